@@ -0,0 +1,197 @@
+// ABOUTME: Board commands for managing named board profiles
+// ABOUTME: Lists, creates and switches between boards with their own databases
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/bbs/internal/config"
+)
+
+var boardCmd = &cobra.Command{
+	Use:   "board",
+	Short: "Manage named boards",
+	Long: `Keep separate boards for separate projects, or a sandbox for agents.
+
+Each board is a profile in the config with its own database, and
+optionally its own Charm host, default identity and auto-sync setting.
+The "default" board always exists and uses the original database.
+
+Pick a board for one command with --board or BBS_BOARD, or switch the
+current board with 'bbs board use'.
+
+Commands:
+  list   - List boards
+  create - Add a board profile
+  use    - Switch the current board`,
+}
+
+var boardListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List boards",
+	Args:    cobra.NoArgs,
+	RunE:    runBoardList,
+}
+
+var boardCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Add a board profile",
+	Long: `Add a board profile. Its database is bbs-<name> unless --db is given;
+pointing --db at an existing database shares it under a new profile.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBoardCreate,
+}
+
+var boardUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Switch the current board",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBoardUse,
+}
+
+var (
+	boardDB       string
+	boardHost     string
+	boardIdentity string
+	boardAutoSync bool
+	boardUse      bool
+)
+
+func init() {
+	rootCmd.AddCommand(boardCmd)
+	boardCmd.AddCommand(boardListCmd, boardCreateCmd, boardUseCmd)
+	boardCreateCmd.Flags().StringVar(&boardDB, "db", "", "database name (default bbs-<name>)")
+	boardCreateCmd.Flags().StringVar(&boardHost, "host", "", "Charm host for this board (default: the configured host)")
+	boardCreateCmd.Flags().StringVar(&boardIdentity, "identity", "", "default username on this board")
+	boardCreateCmd.Flags().BoolVar(&boardAutoSync, "auto-sync", true, "sync writes to the Charm server")
+	boardCreateCmd.Flags().BoolVar(&boardUse, "use", false, "switch to the new board")
+}
+
+// boardInfo is the structured output for one board.
+type boardInfo struct {
+	Name      string
+	Current   bool
+	DB        string
+	CharmHost string
+	Identity  string
+	AutoSync  bool
+}
+
+// listBoards describes every board in the config, marking the current one.
+func listBoards(cfg *config.Config) ([]boardInfo, error) {
+	current, _, err := cfg.Board("")
+	if err != nil {
+		return nil, err
+	}
+	var boards []boardInfo
+	for _, name := range cfg.BoardNames() {
+		_, b, err := cfg.Board(name)
+		if err != nil {
+			return nil, err
+		}
+		host := b.CharmHost
+		if host == "" {
+			host = config.DefaultCharmHost
+		}
+		boards = append(boards, boardInfo{
+			Name:      name,
+			Current:   name == current,
+			DB:        b.DB,
+			CharmHost: host,
+			Identity:  b.Identity,
+			AutoSync:  b.AutoSync == nil || *b.AutoSync,
+		})
+	}
+	return boards, nil
+}
+
+func runBoardList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	boards, err := listBoards(cfg)
+	if err != nil {
+		return err
+	}
+	if structuredOutput() {
+		return printOutput(boards)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tBOARD\tDATABASE\tHOST\tIDENTITY\tAUTO-SYNC")
+	for _, b := range boards {
+		marker := ""
+		if b.Current {
+			marker = "*"
+		}
+		identity := b.Identity
+		if identity == "" {
+			identity = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\n", marker, b.Name, b.DB, b.CharmHost, identity, b.AutoSync)
+	}
+	return w.Flush()
+}
+
+func runBoardCreate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	b := config.Board{DB: boardDB, CharmHost: boardHost, Identity: boardIdentity}
+	if cmd.Flags().Changed("auto-sync") {
+		b.AutoSync = &boardAutoSync
+	}
+	name := args[0]
+	if err := cfg.AddBoard(name, b); err != nil {
+		return usageError{err}
+	}
+	if boardUse {
+		if err := cfg.UseBoard(name); err != nil {
+			return err
+		}
+	}
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	_, created, _ := cfg.Board(name)
+	if structuredOutput() {
+		return printOutput(struct {
+			Name    string
+			DB      string
+			Current bool
+		}{name, created.DB, boardUse})
+	}
+	color.Green("✓ Created board %s (database %s)", name, created.DB)
+	if !boardUse {
+		fmt.Printf("Run 'bbs board use %s' to switch to it, or pass --board %s.\n", name, name)
+	}
+	return nil
+}
+
+func runBoardUse(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := cfg.UseBoard(args[0]); err != nil {
+		return usageError{err}
+	}
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+	if structuredOutput() {
+		return printOutput(struct{ Current string }{args[0]})
+	}
+	color.Green("✓ Now using board %s", args[0])
+	return nil
+}
@@ -0,0 +1,129 @@
+// ABOUTME: Message body input for CLI commands
+// ABOUTME: Reads bodies from arguments, files, stdin or $EDITOR
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// scissorsLine marks the end of the message in an editor template;
+// it and everything below it is discarded.
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+// errEmptyBody is returned when the editor is closed without a message.
+var errEmptyBody = errors.New("aborted: empty message")
+
+// bodySource describes where a command should read a message body from.
+type bodySource struct {
+	Arg    string // positional body; "-" reads stdin
+	HasArg bool
+	File   string // --file path; "-" reads stdin
+}
+
+// readBody returns the message body from the first source given: --file,
+// the positional argument, or, when edit is set, $EDITOR pre-filled with
+// initial. It returns "" with no error when no source applies.
+func readBody(src bodySource, edit bool, initial, hint string) (string, error) {
+	switch {
+	case src.File != "" && src.HasArg:
+		return "", usageError{errors.New("give the message as an argument or with --file, not both")}
+	case src.File == "-":
+		return readAll(os.Stdin)
+	case src.File != "":
+		data, err := os.ReadFile(src.File)
+		if err != nil {
+			return "", fmt.Errorf("read message file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case src.HasArg && src.Arg == "-":
+		return readAll(os.Stdin)
+	case src.HasArg:
+		return src.Arg, nil
+	case edit:
+		return editBody(initial, hint)
+	}
+	return "", nil
+}
+
+func readAll(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("read message from stdin: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// editBody opens $VISUAL or $EDITOR (falling back to vi) on a template and
+// returns what the user wrote above the scissors line.
+func editBody(initial, hint string) (string, error) {
+	f, err := os.CreateTemp("", "bbs-message-*.md")
+	if err != nil {
+		return "", fmt.Errorf("create message file: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+
+	if _, err := f.WriteString(editorTemplate(initial, hint)); err != nil {
+		f.Close()
+		return "", fmt.Errorf("write message file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("write message file: %w", err)
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("run editor %q: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read message file: %w", err)
+	}
+	body := stripTemplate(string(data))
+	if body == "" {
+		return "", errEmptyBody
+	}
+	return body, nil
+}
+
+// editorTemplate builds the file shown in the editor.
+func editorTemplate(initial, hint string) string {
+	var sb strings.Builder
+	sb.WriteString(initial)
+	if initial != "" && !strings.HasSuffix(initial, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n" + scissorsLine + "\n")
+	sb.WriteString("# Do not modify or remove the line above.\n")
+	sb.WriteString("# Everything below it will be ignored. Markdown is fine.\n")
+	sb.WriteString("# Save an empty message to abort.\n")
+	if hint != "" {
+		sb.WriteString("#\n# " + hint + "\n")
+	}
+	return sb.String()
+}
+
+// stripTemplate removes the scissors line and everything after it, plus
+// surrounding blank lines.
+func stripTemplate(s string) string {
+	if i := strings.Index(s, scissorsLine); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
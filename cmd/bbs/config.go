@@ -0,0 +1,180 @@
+// ABOUTME: Config commands for reading and changing settings
+// ABOUTME: Shows each setting's effective value and the layer it comes from
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/bbs/internal/config"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change settings",
+	Long: `Show and change bbs settings.
+
+Each setting is layered: built-in defaults, then the config file, then
+the current board's profile, then environment variables, then flags.
+'bbs config list' shows where every value comes from.
+
+Commands:
+  list - List settings with their values and sources
+  get  - Print one setting
+  set  - Change a setting in the config file
+  path - Print the config file path`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List settings with their values and sources",
+	Args:    cobra.NoArgs,
+	RunE:    runConfigList,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print one setting",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting in the config file",
+	Long: `Change a setting in the config file. An empty value removes it, so
+the default applies again:
+
+  bbs config set sync_interval 30s
+  bbs config set moderators harper,dylan
+  bbs config set charm_host ""`,
+	Args: cobra.ExactArgs(2),
+	RunE: runConfigSet,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the config file path",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigPath,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configListCmd, configGetCmd, configSetCmd, configPathCmd)
+}
+
+// configEntry is the structured output for one setting.
+type configEntry struct {
+	Key    string
+	Value  string
+	Source string
+	Env    string
+}
+
+// describeSetting reports a setting's effective value and where it comes
+// from. --board counts as a flag for the board key.
+func describeSetting(k config.Key, file *config.Config) configEntry {
+	e := configEntry{
+		Key:    k.Name,
+		Value:  k.Get(&settings.Config),
+		Source: settings.Source(k, file, os.Getenv),
+		Env:    k.Env,
+	}
+	if k.Name == "board" && boardFlag != "" {
+		e.Source = "flag"
+	}
+	return e
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	file, err := config.Load()
+	if err != nil {
+		return err
+	}
+	var entries []configEntry
+	for _, k := range config.Keys {
+		entries = append(entries, describeSetting(k, file))
+	}
+	if structuredOutput() {
+		return printOutput(entries)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, e := range entries {
+		value := e.Value
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", e.Key, value, e.Source)
+	}
+	return w.Flush()
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	k, err := config.LookupKey(args[0])
+	if err != nil {
+		return usageError{err}
+	}
+	file, err := config.Load()
+	if err != nil {
+		return err
+	}
+	e := describeSetting(k, file)
+	if structuredOutput() {
+		return printOutput(e)
+	}
+	fmt.Println(e.Value)
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	k, err := config.LookupKey(args[0])
+	if err != nil {
+		return usageError{err}
+	}
+	file, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if k.Name == "moderators" {
+		if err := requireModerator(file.Moderators); err != nil {
+			return err
+		}
+	}
+	if err := k.Set(file, args[1]); err != nil {
+		return usageError{err}
+	}
+	if err := file.Save(); err != nil {
+		return err
+	}
+
+	value := k.Get(file)
+	if structuredOutput() {
+		return printOutput(configEntry{Key: k.Name, Value: value, Source: "file", Env: k.Env})
+	}
+	if value == "" {
+		color.Green("✓ Unset %s", k.Name)
+	} else {
+		color.Green("✓ Set %s = %s", k.Name, value)
+	}
+	if k.Env != "" && os.Getenv(k.Env) != "" {
+		color.Yellow("%s is set in the environment and overrides the config file", k.Env)
+	}
+	return nil
+}
+
+func runConfigPath(cmd *cobra.Command, args []string) error {
+	if structuredOutput() {
+		return printOutput(struct{ Path string }{config.GetConfigPath()})
+	}
+	fmt.Println(config.GetConfigPath())
+	return nil
+}
@@ -0,0 +1,188 @@
+// ABOUTME: Sync conflict commands for reviewing and resolving concurrent edits
+// ABOUTME: Lists conflicts held back by sync and keeps the local or remote version
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/bbs/internal/charm"
+)
+
+var syncConflictsCmd = &cobra.Command{
+	Use:   "conflicts [key]",
+	Short: "List edits that conflicted with changes from another device",
+	Long: `List records changed both here and on another device since the last sync.
+
+Edits to different fields, new posts and reactions are merged automatically.
+When both sides changed the same field, the other device's version is kept
+and this device's edit is held here for review.
+
+Pass a key or record ID prefix to show both versions.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSyncConflicts,
+}
+
+var syncResolveCmd = &cobra.Command{
+	Use:   "resolve <key>",
+	Short: "Resolve a sync conflict",
+	Long: `Resolve a sync conflict by keeping one version.
+
+--keep remote keeps the other device's version, which is already in place.
+--keep local writes this device's version over it; it syncs like any other write.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSyncResolve,
+}
+
+var resolveKeep string
+
+func init() {
+	syncCmd.AddCommand(syncConflictsCmd, syncResolveCmd)
+	syncResolveCmd.Flags().StringVar(&resolveKeep, "keep", "", "version to keep: local or remote (required)")
+	_ = syncResolveCmd.MarkFlagRequired("keep")
+}
+
+// conflictView is the structured output for one conflict.
+type conflictView struct {
+	Key           string
+	Reason        string
+	DetectedAt    time.Time
+	LocalVersion  uint64
+	RemoteVersion uint64
+	Local         json.RawMessage
+	Remote        json.RawMessage
+}
+
+func newConflictView(c charm.Conflict) conflictView {
+	local, remote := c.Versions()
+	return conflictView{
+		Key:           c.Key,
+		Reason:        c.Reason,
+		DetectedAt:    c.DetectedAt,
+		LocalVersion:  local,
+		RemoteVersion: remote,
+		Local:         rawJSON(c.Local),
+		Remote:        rawJSON(c.Remote),
+	}
+}
+
+// rawJSON passes a stored value through as JSON, or null when it is
+// missing or not JSON.
+func rawJSON(data []byte) json.RawMessage {
+	if data == nil || !json.Valid(data) {
+		return nil
+	}
+	return data
+}
+
+func runSyncConflicts(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return fmt.Errorf("charm not initialized: %w", err)
+	}
+	conflicts, err := client.Conflicts()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		c, err := charm.FindConflict(conflicts, args[0])
+		if err != nil {
+			return err
+		}
+		view := newConflictView(c)
+		if structuredOutput() {
+			return printOutput(view)
+		}
+		printConflict(view)
+		return nil
+	}
+
+	views := make([]conflictView, 0, len(conflicts))
+	for _, c := range conflicts {
+		views = append(views, newConflictView(c))
+	}
+	if structuredOutput() {
+		return printOutput(views)
+	}
+
+	if len(views) == 0 {
+		color.Green("No sync conflicts")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tREASON\tLOCAL\tREMOTE\tDETECTED")
+	for _, v := range views {
+		fmt.Fprintf(w, "%s\t%s\tv%d\tv%d\t%s\n", v.Key, v.Reason, v.LocalVersion, v.RemoteVersion, v.DetectedAt.Local().Format("2006-01-02 15:04"))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Println("\nRun 'bbs sync conflicts <key>' to compare, then 'bbs sync resolve <key> --keep local|remote'.")
+	return nil
+}
+
+// printConflict shows both versions of a conflicting record.
+func printConflict(v conflictView) {
+	fmt.Printf("Key:      %s\n", v.Key)
+	fmt.Printf("Reason:   %s\n", v.Reason)
+	fmt.Printf("Detected: %s\n", v.DetectedAt.Local().Format("2006-01-02 15:04:05"))
+	for _, side := range []struct {
+		label   string
+		version uint64
+		data    json.RawMessage
+	}{
+		{"Local", v.LocalVersion, v.Local},
+		{"Remote", v.RemoteVersion, v.Remote},
+	} {
+		fmt.Println()
+		color.New(color.Bold).Printf("%s (v%d):\n", side.label, side.version)
+		if side.data == nil {
+			color.New(color.Faint).Println("  (deleted)")
+			continue
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, side.data, "  ", "  "); err != nil {
+			out.Reset()
+			out.Write(side.data)
+		}
+		fmt.Printf("  %s\n", out.String())
+	}
+}
+
+func runSyncResolve(cmd *cobra.Command, args []string) error {
+	var keepLocal bool
+	switch resolveKeep {
+	case "local":
+		keepLocal = true
+	case "remote":
+	default:
+		return usageError{fmt.Errorf("invalid --keep %q: use local or remote", resolveKeep)}
+	}
+
+	client, err := openClient()
+	if err != nil {
+		return fmt.Errorf("charm not initialized: %w", err)
+	}
+	conflict, err := client.ResolveConflict(args[0], keepLocal)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printOutput(struct {
+			Key  string
+			Kept string
+		}{conflict.Key, resolveKeep})
+	}
+	color.Green("✓ Kept %s version of %s", resolveKeep, conflict.Key)
+	return nil
+}
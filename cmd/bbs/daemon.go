@@ -0,0 +1,82 @@
+// ABOUTME: Sync daemon command that keeps the local replica warm
+// ABOUTME: Syncs on an interval with jittered backoff and logs each attempt
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/bbs/internal/charm"
+)
+
+var syncDaemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Keep the local replica in sync in the background",
+	Long: `Sync with the Charm server on an interval until stopped.
+
+While the daemon runs, reads from the CLI, TUI and MCP server find fresh
+local data instead of syncing on their own. After a failed sync it retries
+with jittered exponential backoff, then returns to the interval.
+
+The interval defaults to the sync_interval config key (1 minute).
+Health is written to a status file that 'bbs sync status' and the TUI read.
+With -o json or -o yaml each attempt is logged as that health record.`,
+	Args: cobra.NoArgs,
+	RunE: runSyncDaemon,
+}
+
+var daemonInterval time.Duration
+
+func init() {
+	syncCmd.AddCommand(syncDaemonCmd)
+	syncDaemonCmd.Flags().DurationVar(&daemonInterval, "interval", 0, "time between syncs (default from config)")
+}
+
+func runSyncDaemon(cmd *cobra.Command, args []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := openClient()
+	if err != nil {
+		return fmt.Errorf("charm not initialized: %w", err)
+	}
+	interval := daemonInterval
+	if interval == 0 {
+		interval = time.Duration(settings.SyncInterval)
+	}
+	if interval <= 0 {
+		interval = charm.DefaultSyncInterval
+	}
+
+	fmt.Fprintf(os.Stderr, "Syncing every %s (Ctrl-C to stop)\n", interval)
+	return client.RunDaemon(ctx, interval, logDaemonSync)
+}
+
+// logDaemonSync prints one line per sync attempt, or with -o json|yaml the
+// daemon's health record, so monitoring can parse the log.
+func logDaemonSync(h charm.DaemonHealth) {
+	if structuredOutput() {
+		if outputFormat == outputYAML {
+			fmt.Println("---") // one YAML document per attempt
+		}
+		if err := printOutput(h); err != nil {
+			fmt.Fprintf(os.Stderr, "daemon log: %v\n", err)
+		}
+		return
+	}
+	at := h.LastAttempt.Local().Format("15:04:05")
+	if h.LastError == "" {
+		fmt.Printf("%s %s\n", at, color.GreenString("synced"))
+		return
+	}
+	fmt.Printf("%s %s %s (retry in %s)\n", at, color.YellowString("failed:"), h.LastError,
+		time.Until(h.NextSync).Round(time.Second))
+}
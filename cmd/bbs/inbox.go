@@ -0,0 +1,105 @@
+// ABOUTME: Inbox CLI command
+// ABOUTME: Lists messages that @mention the current identity
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/models"
+)
+
+var inboxCmd = &cobra.Command{
+	Use:   "inbox",
+	Short: "Show messages that mention you",
+	Long: `Show messages that mention you with @username.
+
+Mentions are matched against the username part of your identity, so
+@harper reaches harper@cli, harper@tui and harper@mcp alike.`,
+	Args: cobra.NoArgs,
+	RunE: runInbox,
+}
+
+var (
+	inboxAll      bool
+	inboxMarkRead bool
+)
+
+func init() {
+	rootCmd.AddCommand(inboxCmd)
+	inboxCmd.Flags().BoolVar(&inboxAll, "all", false, "include mentions already marked read")
+	inboxCmd.Flags().BoolVar(&inboxMarkRead, "mark-read", false, "mark the listed mentions as read")
+}
+
+func runInbox(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	id := resolveIdentity("cli")
+	mentions, err := client.ListMentions(id, inboxAll)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		if inboxMarkRead {
+			if err := markInboxRead(client, id, mentions); err != nil {
+				return err
+			}
+		}
+		return printOutput(mentions)
+	}
+
+	if len(mentions) == 0 {
+		fmt.Println("No mentions.")
+		return nil
+	}
+
+	faint := color.New(color.Faint)
+	subjects := make(map[models.UUID]string)
+	for _, m := range mentions {
+		subject, ok := subjects[m.ThreadID]
+		if !ok {
+			if thread, err := client.GetThread(m.ThreadID); err == nil {
+				subject = thread.Subject
+			} else {
+				subject = "(deleted thread)"
+			}
+			subjects[m.ThreadID] = subject
+		}
+
+		marker := "●"
+		if m.ReadAt != nil {
+			marker = " "
+		}
+		fmt.Printf("%s %s in %s\n", marker, m.MentionedBy, subject)
+		faint.Printf("  %s · message %s\n", m.CreatedAt.Format("Jan 02 15:04"), m.MessageID.String()[:8])
+		fmt.Printf("  %s\n\n", m.Excerpt)
+	}
+
+	if inboxMarkRead {
+		if err := markInboxRead(client, id, mentions); err != nil {
+			return err
+		}
+		color.Green("Marked %d mention(s) as read", len(mentions))
+	}
+	return nil
+}
+
+// markInboxRead marks exactly the listed mentions as read.
+func markInboxRead(client *charm.Client, id string, mentions []*models.Mention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+	ids := make([]models.UUID, 0, len(mentions))
+	for _, m := range mentions {
+		ids = append(ids, m.MessageID)
+	}
+	return client.MarkMentionsRead(id, ids...)
+}
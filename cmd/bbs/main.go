@@ -4,7 +4,6 @@
 package main
 
 import (
-	"fmt"
 	"os"
 )
 
@@ -15,8 +14,9 @@ var (
 )
 
 func main() {
+	withUsageErrors(rootCmd)
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		reportError(os.Stderr, err)
 		os.Exit(1)
 	}
 }
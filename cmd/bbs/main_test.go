@@ -1,11 +1,292 @@
-// ABOUTME: Placeholder test for cmd/bbs package
-// ABOUTME: Ensures coverage tool doesn't fail on empty package
+// ABOUTME: Tests for cmd/bbs helpers
+// ABOUTME: Covers output formatting and structured error codes
 
 package main
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/config"
+	"github.com/harper/bbs/internal/models"
+)
 
 func TestMain(t *testing.T) {
 	// Placeholder test to ensure package has test coverage
 	// Real integration tests are in .scratch/ scenario tests
 }
+
+func TestWriteOutputYAML(t *testing.T) {
+	v := struct {
+		Name    string
+		Count   int
+		Flag    string
+		Content string
+	}{"general", 3, "true", "line one\nline two"}
+
+	var buf bytes.Buffer
+	if err := writeOutput(&buf, outputYAML, v); err != nil {
+		t.Fatalf("writeOutput: %v", err)
+	}
+
+	var back map[string]any
+	if err := yaml.Unmarshal(buf.Bytes(), &back); err != nil {
+		t.Fatalf("output is not valid YAML: %v\n%s", err, buf.String())
+	}
+	if back["Name"] != "general" || back["Count"] != 3 || back["Flag"] != "true" || back["Content"] != v.Content {
+		t.Errorf("round trip mismatch: %#v\n%s", back, buf.String())
+	}
+	if strings.Contains(buf.String(), "{") {
+		t.Errorf("expected block style YAML, got:\n%s", buf.String())
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{usageError{errors.New("accepts 1 arg(s), received 0")}, codeUsage},
+		{fmt.Errorf("failed to create topic: %w", charm.ErrTopicExists), codeConflict},
+		{charm.ErrThreadLocked, codeForbidden},
+		{fmt.Errorf("react: %w", models.ErrInvalidReaction), codeInvalid},
+		{&charm.NotFoundError{Kind: "thread", Key: "abc"}, codeNotFound},
+		{&charm.AmbiguousError{Kind: "thread", Prefix: "a"}, codeAmbiguous},
+		{fmt.Errorf("post: %w", charm.ErrSyncFailed), codeSync},
+		{fmt.Errorf("resolve: %w", &charm.AmbiguousError{Kind: "topic", Prefix: "g"}), codeAmbiguous},
+		// Codes come from error kinds only, never from message text
+		{errors.New("thread not found: abc"), codeInternal},
+		{errors.New("ambiguous prefix"), codeInternal},
+		{errors.New("sync failed: timeout"), codeInternal},
+		{errors.New("database is locked"), codeInternal},
+	}
+
+	for _, tt := range tests {
+		if got := errorCode(tt.err); got != tt.want {
+			t.Errorf("errorCode(%q) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestReportErrorJSON(t *testing.T) {
+	old := outputFormat
+	outputFormat = outputJSON
+	defer func() { outputFormat = old }()
+
+	var buf bytes.Buffer
+	reportError(&buf, &charm.NotFoundError{Kind: "topic", Key: "nope"})
+
+	var got cliError
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("stderr is not JSON: %v\n%s", err, buf.String())
+	}
+	if got.Error.Code != codeNotFound || got.Error.Message != "topic not found: nope" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestEditorTemplateRoundTrip(t *testing.T) {
+	tmpl := editorTemplate("current text", "Editing message abc12345")
+	if !strings.HasPrefix(tmpl, "current text\n") {
+		t.Errorf("template should start with the current content:\n%s", tmpl)
+	}
+	if got := stripTemplate(tmpl); got != "current text" {
+		t.Errorf("stripTemplate(template) = %q, want %q", got, "current text")
+	}
+
+	edited := "# Heading\n\n- item\n\n" + tmpl[len("current text\n"):]
+	if got := stripTemplate(edited); got != "# Heading\n\n- item" {
+		t.Errorf("stripTemplate kept the wrong text: %q", got)
+	}
+	if got := stripTemplate(editorTemplate("", "")); got != "" {
+		t.Errorf("untouched empty template should strip to empty, got %q", got)
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "body.md")
+	if err := os.WriteFile(path, []byte("line one\nline two\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readBody(bodySource{File: path}, false, "", "")
+	if err != nil || got != "line one\nline two" {
+		t.Errorf("readBody(file) = %q, %v", got, err)
+	}
+
+	got, err = readBody(bodySource{Arg: "inline", HasArg: true}, true, "", "")
+	if err != nil || got != "inline" {
+		t.Errorf("readBody(arg) = %q, %v", got, err)
+	}
+
+	got, err = readBody(bodySource{}, false, "", "")
+	if err != nil || got != "" {
+		t.Errorf("readBody(nothing) = %q, %v", got, err)
+	}
+
+	_, err = readBody(bodySource{Arg: "inline", HasArg: true, File: path}, false, "", "")
+	if errorCode(err) != codeUsage {
+		t.Errorf("argument plus --file should be a usage error, got %v", err)
+	}
+}
+
+func TestSyncStatusAddSyncState(t *testing.T) {
+	queued := time.Now().Add(-time.Hour)
+	st := &charm.SyncState{
+		Pending:     []charm.OutboxEntry{{Key: "topic:a", QueuedAt: queued}},
+		Conflicts:   []charm.Conflict{{Key: "thread:b"}},
+		LastError:   "sync failed: connection refused",
+		NextAttempt: time.Now().Add(time.Minute),
+	}
+
+	var status syncStatus
+	status.addSyncState(st)
+
+	if status.Pending != 1 || status.OldestPending == nil || !status.OldestPending.Equal(queued) {
+		t.Errorf("pending = %d, oldest %v", status.Pending, status.OldestPending)
+	}
+	if status.LastSync != nil {
+		t.Error("a state that never synced should have no last sync")
+	}
+	if status.NextRetry == nil || len(status.Conflicts) != 1 || status.Conflicts[0].Key != "thread:b" {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func TestSyncStatusAddStats(t *testing.T) {
+	dbSync := time.Now().Add(-time.Hour)
+	stats := &charm.Stats{
+		Records:        map[string]int{"topic": 2},
+		LastSync:       dbSync,
+		StaleThreshold: 5 * time.Minute,
+		SizeBytes:      2048,
+	}
+
+	var status syncStatus
+	status.addStats(stats, time.Now())
+	if status.LastSync == nil || !status.LastSync.Equal(dbSync) || !status.Stale {
+		t.Errorf("an hour-old sync should be stale: %+v", status)
+	}
+
+	recent := time.Now().Add(-time.Minute)
+	status = syncStatus{LastSync: &recent}
+	status.addStats(stats, time.Now())
+	if !status.LastSync.Equal(recent) || status.Stale {
+		t.Errorf("a later sync from the sync state should win: %+v", status)
+	}
+}
+
+func TestSyncStatusJSONFlag(t *testing.T) {
+	if syncStatusCmd.Flags().Lookup("json") == nil {
+		t.Fatal("sync status should accept --json")
+	}
+}
+
+func TestFormatRecords(t *testing.T) {
+	got := formatRecords(map[string]int{"topic": 2, "message": 10, "subscription": 1, "reaction": 3})
+	want := "topic: 2, thread: 0, message: 10, attachment: 0, reaction: 3, subscription: 1"
+	if got != want {
+		t.Errorf("formatRecords = %q, want %q", got, want)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{
+		512:        "512 B",
+		2048:       "2.0 KiB",
+		5 << 20:    "5.0 MiB",
+		3 << 30:    "3.0 GiB",
+		1536 << 10: "1.5 MiB",
+	} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestListBoards(t *testing.T) {
+	off := false
+	cfg := &config.Config{
+		Boards:       map[string]config.Board{"sandbox": {DB: "bbs-sandbox", AutoSync: &off}},
+		CurrentBoard: "sandbox",
+	}
+	boards, err := listBoards(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boards) != 2 {
+		t.Fatalf("boards = %+v, want default and sandbox", boards)
+	}
+	if boards[0].Name != "default" || boards[0].Current || !boards[0].AutoSync || boards[0].CharmHost != config.DefaultCharmHost {
+		t.Errorf("default board = %+v", boards[0])
+	}
+	if !boards[1].Current || boards[1].AutoSync {
+		t.Errorf("sandbox board = %+v, want current with auto-sync off", boards[1])
+	}
+}
+
+func TestResolveIdentity(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { identityFlag, settings = "", saved })
+	t.Setenv("USER", "harper")
+	file := &config.Config{
+		Identity: "hpr",
+		Boards:   map[string]config.Board{"sandbox": {DB: "bbs-sandbox", Identity: "sandbox-bot"}},
+	}
+	env := map[string]string{}
+	resolve := func(board string) {
+		t.Helper()
+		var err error
+		if settings, err = config.Resolve(file, board, func(name string) string { return env[name] }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resolve("")
+	if got := resolveIdentity("cli"); got != "hpr@cli" {
+		t.Errorf("config identity = %q", got)
+	}
+	resolve("sandbox")
+	if got := resolveIdentity("cli"); got != "sandbox-bot@cli" {
+		t.Errorf("board identity = %q", got)
+	}
+	env["BBS_USER"] = "dylan"
+	resolve("sandbox")
+	if got := resolveIdentity("cli"); got != "dylan@cli" {
+		t.Errorf("BBS_USER should win over the board, got %q", got)
+	}
+	identityFlag = "scout"
+	if got := resolveIdentity("cli"); got != "scout@cli" {
+		t.Errorf("--as should win, got %q", got)
+	}
+}
+
+func TestRequireModerator(t *testing.T) {
+	saved := settings
+	t.Cleanup(func() { identityFlag, settings = "", saved })
+	settings = &config.Resolved{Config: config.Config{Identity: "dylan"}}
+
+	if err := requireModerator(nil); err != nil {
+		t.Errorf("anyone may add the first moderator, got %v", err)
+	}
+	if err := requireModerator([]string{"harper"}); !errors.Is(err, charm.ErrNotModerator) {
+		t.Errorf("non-moderator changing moderators = %v, want ErrNotModerator", err)
+	}
+	if err := requireModerator([]string{"harper", "Dylan"}); err != nil {
+		t.Errorf("moderator changing moderators: %v", err)
+	}
+	identityFlag = "harper"
+	if err := requireModerator([]string{"harper"}); !errors.Is(err, charm.ErrNotModerator) {
+		t.Errorf("--as a moderator = %v, want ErrNotModerator", err)
+	}
+}
@@ -1,5 +1,5 @@
 // ABOUTME: MCP server command implementation
-// ABOUTME: Starts BBS MCP server in stdio mode
+// ABOUTME: Starts BBS MCP server over stdio or HTTP
 
 package main
 
@@ -8,41 +8,151 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
-	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/config"
 	"github.com/harper/bbs/internal/mcp"
 )
 
 var mcpCmd = &cobra.Command{
 	Use:   "mcp",
-	Short: "Start MCP server (stdio mode)",
+	Short: "Start MCP server (stdio or HTTP)",
 	Long: `Start the Model Context Protocol server for AI agent integration.
 
-The MCP server communicates via stdio, allowing AI agents like Claude
-to interact with BBS through a standardized protocol.`,
+By default the MCP server communicates via stdio, allowing AI agents like
+Claude to interact with BBS through a standardized protocol.
+
+With --transport http, one process serves many agent sessions over the
+streamable HTTP transport at /mcp (and the older SSE transport at /sse):
+
+  bbs mcp --transport http --token-file ~/.config/bbs/mcp-tokens
+
+Each token is name=secret (or just secret, which authenticates as
+"agent"). Give every agent its own named token: a session using a named
+token posts as <name>@mcp regardless of agent_name, while each session
+with an unnamed token or no token gets its own identity (agent-<session>@mcp,
+with its own read marks and feed) unless it passes agent_name. Tokens come from
+--token-file (one per line), BBS_MCP_TOKEN (comma separated) or --token;
+--token is visible to other users in the process list, so prefer the
+first two.
+
+The HTTP server listens on 127.0.0.1:8080 by default. Without any token
+it accepts unauthenticated requests, so it refuses to listen on anything
+but a loopback address unless tokens are given.
+
+The server is bound to one board for its lifetime: the current board, or
+the one given with --board. Over stdio, tool calls without agent_name
+post as the board's default identity when it has one.
+
+Untrusted agents can be given narrower access. --read-only leaves out
+every tool that changes board content and the prompts that would use
+them (subscribe, get_mentions and get_feed are kept and still update the
+agent's own watch list, read marks and feed cursor); --allow-topics limits tools,
+resources and prompts to the listed topics (create_topic and batch are
+left out, and other topics look like they do not exist); --tools
+registers only the named tools:
+
+  bbs mcp --read-only --allow-topics standup,releases
+  bbs mcp --tools list_threads,list_messages,post_message
+
+Clients may subscribe to bbs:// resources. Subscribed resources are
+re-read every --poll-interval (after syncing if the local copy is stale)
+and subscribers are notified when their content changes. Template
+prompts are rebuilt every minute, whatever the poll interval.
+
+Defaults for --transport, --listen and --poll-interval come from the
+mcp.* config keys (see 'bbs config list').`,
+	Args: cobra.NoArgs,
 	RunE: runMCP,
 }
 
+var (
+	mcpPollInterval time.Duration
+	mcpTransport    string
+	mcpListen       string
+	mcpTokens       []string
+	mcpTokenFile    string
+	mcpReadOnly     bool
+	mcpAllowTopics  []string
+	mcpTools        []string
+)
+
 func init() {
 	rootCmd.AddCommand(mcpCmd)
+	mcpCmd.Flags().DurationVar(&mcpPollInterval, "poll-interval", mcp.DefaultPollInterval, "how often to check subscribed resources for changes (0 disables)")
+	mcpCmd.Flags().StringVar(&mcpTransport, "transport", "stdio", "transport to serve: stdio or http")
+	mcpCmd.Flags().StringVar(&mcpListen, "listen", config.DefaultMCPListen, "listen address for the http transport")
+	mcpCmd.Flags().StringArrayVar(&mcpTokens, "token", nil, "bearer token for the http transport as name=secret (repeatable)")
+	mcpCmd.Flags().StringVar(&mcpTokenFile, "token-file", "", "file of bearer tokens for the http transport, one name=secret per line")
+	mcpCmd.Flags().BoolVar(&mcpReadOnly, "read-only", false, "leave out tools that change board content (subscribe still writes the watch list)")
+	mcpCmd.Flags().StringSliceVar(&mcpAllowTopics, "allow-topics", nil, "only expose these topics (names, slugs or IDs)")
+	mcpCmd.Flags().StringSliceVar(&mcpTools, "tools", nil, "only register these tools")
 }
 
 func runMCP(cmd *cobra.Command, args []string) error {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	client, err := charm.Global()
+	// Flags win over the mcp settings in the config
+	if !cmd.Flags().Changed("poll-interval") {
+		mcpPollInterval = time.Duration(settings.MCP.PollInterval)
+	}
+	if !cmd.Flags().Changed("transport") {
+		mcpTransport = settings.MCP.Transport
+	}
+	if !cmd.Flags().Changed("listen") {
+		mcpListen = settings.MCP.Listen
+	}
+
+	client, err := openClient()
 	if err != nil {
 		return fmt.Errorf("charm client not initialized: %w", err)
 	}
 
-	server, err := mcp.NewServer(client)
+	opts := []mcp.Option{
+		mcp.WithPollInterval(mcpPollInterval),
+		mcp.WithBoard(settings.BoardName),
+		mcp.WithDefaultAgent(settings.Identity),
+		mcp.WithAllowTopics(mcpAllowTopics),
+		mcp.WithTools(mcpTools),
+	}
+	if mcpReadOnly {
+		opts = append(opts, mcp.WithReadOnly())
+	}
+	server, err := mcp.NewServer(client, opts...)
 	if err != nil {
 		return err
 	}
+	client.StartSyncer(ctx)
 
-	return server.Serve(ctx)
+	switch mcpTransport {
+	case "stdio":
+		return server.Serve(ctx)
+	case "http":
+		specs := mcpTokens
+		if env := os.Getenv("BBS_MCP_TOKEN"); env != "" {
+			specs = append(specs, strings.Split(env, ",")...)
+		}
+		if mcpTokenFile != "" {
+			fromFile, err := mcp.ReadTokenFile(mcpTokenFile)
+			if err != nil {
+				return err
+			}
+			specs = append(specs, fromFile...)
+		}
+		tokens, err := mcp.ParseTokens(specs)
+		if err != nil {
+			return err
+		}
+		if len(tokens) == 0 {
+			fmt.Fprintln(os.Stderr, "warning: no tokens given; the MCP HTTP server accepts unauthenticated requests from this machine")
+		}
+		return server.ListenAndServe(ctx, mcp.HTTPOptions{Addr: mcpListen, Tokens: tokens})
+	default:
+		return usageError{fmt.Errorf("unknown transport %q: use stdio or http", mcpTransport)}
+	}
 }
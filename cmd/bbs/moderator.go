@@ -0,0 +1,151 @@
+// ABOUTME: Moderator CLI commands
+// ABOUTME: Manages which usernames may lock threads and make topics read-only
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/config"
+)
+
+var moderatorCmd = &cobra.Command{
+	Use:   "moderator",
+	Short: "Manage moderators",
+	Long: `Manage the usernames allowed to lock threads and make topics read-only.
+
+Moderators are matched against the username part of an identity, so
+"harper" covers both harper@cli and harper@mcp. The list is stored in
+the moderators key of this device's config and is not synced.
+
+Once the list has any moderators, only a moderator may add or remove
+them; the first moderator can be added by anyone. Moderator rights are
+checked against the configured identity (BBS_USER, the board's, then the
+config file's) or $USER; --as is ignored for them.`,
+}
+
+var moderatorListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List moderators",
+	Args:  cobra.NoArgs,
+	RunE:  runModeratorList,
+}
+
+var moderatorAddCmd = &cobra.Command{
+	Use:   "add <username>",
+	Short: "Add a moderator",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runModeratorAdd,
+}
+
+var moderatorRemoveCmd = &cobra.Command{
+	Use:   "remove <username>",
+	Short: "Remove a moderator",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runModeratorRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(moderatorCmd)
+	moderatorCmd.AddCommand(moderatorListCmd, moderatorAddCmd, moderatorRemoveCmd)
+}
+
+func runModeratorList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printOutput(cfg.Moderators)
+	}
+
+	if len(cfg.Moderators) == 0 {
+		fmt.Println("No moderators configured.")
+		return nil
+	}
+	for _, m := range cfg.Moderators {
+		fmt.Println(m)
+	}
+	return nil
+}
+
+// requireModerator fails unless the caller is already a moderator. While
+// the list is empty anyone may add the first one. --as does not count; see
+// moderatorIdentity.
+func requireModerator(moderators []string) error {
+	if len(moderators) == 0 {
+		return nil
+	}
+	if id := moderatorIdentity(); !charm.IsModeratorIn(moderators, id) {
+		return fmt.Errorf("%w: %s cannot change moderators", charm.ErrNotModerator, id)
+	}
+	return nil
+}
+
+func runModeratorAdd(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := requireModerator(cfg.Moderators); err != nil {
+		return err
+	}
+
+	username := strings.TrimSpace(args[0])
+	if slices.ContainsFunc(cfg.Moderators, func(m string) bool { return strings.EqualFold(m, username) }) {
+		if structuredOutput() {
+			return printOutput(cfg.Moderators)
+		}
+		fmt.Printf("%s is already a moderator\n", username)
+		return nil
+	}
+
+	cfg.Moderators = append(cfg.Moderators, username)
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printOutput(cfg.Moderators)
+	}
+	color.Green("Added moderator: %s", username)
+	return nil
+}
+
+func runModeratorRemove(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := requireModerator(cfg.Moderators); err != nil {
+		return err
+	}
+
+	var kept []string
+	for _, m := range cfg.Moderators {
+		if !strings.EqualFold(m, args[0]) {
+			kept = append(kept, m)
+		}
+	}
+	if len(kept) == len(cfg.Moderators) {
+		return &charm.NotFoundError{Kind: "moderator", Key: args[0]}
+	}
+
+	cfg.Moderators = kept
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printOutput(cfg.Moderators)
+	}
+	color.Yellow("Removed moderator: %s", args[0])
+	return nil
+}
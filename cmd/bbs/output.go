@@ -0,0 +1,193 @@
+// ABOUTME: Output formats and structured errors for CLI commands
+// ABOUTME: Renders results as table, JSON or YAML and maps errors to stable codes
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/models"
+)
+
+// Output formats accepted by --output.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// Stable error codes reported in structured error output.
+const (
+	codeUsage     = "usage"
+	codeInvalid   = "invalid_argument"
+	codeNotFound  = "not_found"
+	codeAmbiguous = "ambiguous"
+	codeConflict  = "conflict"
+	codeForbidden = "forbidden"
+	codeSync      = "sync_failed"
+	codeInternal  = "internal"
+)
+
+var outputFormat string
+
+// validateOutputFormat checks the --output flag value.
+func validateOutputFormat() error {
+	switch outputFormat {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	}
+	return usageError{fmt.Errorf("invalid --output %q: use table, json or yaml", outputFormat)}
+}
+
+// structuredOutput reports whether results should be printed as JSON or YAML.
+func structuredOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// printOutput writes v to stdout in the selected structured format.
+func printOutput(v any) error {
+	return writeOutput(os.Stdout, outputFormat, v)
+}
+
+// promptWriter is where interactive prompts go: stdout normally, stderr
+// when stdout is reserved for structured output.
+func promptWriter() io.Writer {
+	if structuredOutput() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func writeOutput(w io.Writer, format string, v any) error {
+	// Empty lists print as [] rather than null.
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.IsNil() {
+		v = []any{}
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal output: %w", err)
+	}
+
+	if format != outputYAML {
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	// Go through JSON so YAML keys and field order match the JSON output.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("convert output to yaml: %w", err)
+	}
+	blockStyle(&node)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle clears JSON's flow and quoting styles so YAML renders in block form.
+// The encoder still quotes strings that would otherwise read back as another type.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// usageError marks errors caused by bad flags or arguments.
+type usageError struct{ err error }
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+// withUsageErrors wraps argument validation on cmd and its children so
+// bad arguments are reported with the usage error code.
+func withUsageErrors(cmd *cobra.Command) {
+	if args := cmd.Args; args != nil {
+		cmd.Args = func(c *cobra.Command, a []string) error {
+			if err := args(c, a); err != nil {
+				return usageError{err}
+			}
+			return nil
+		}
+	}
+	for _, child := range cmd.Commands() {
+		withUsageErrors(child)
+	}
+}
+
+// errorCode maps an error to a stable code for scripts. Only error kinds
+// matched with errors.Is count; the message text never does.
+func errorCode(err error) string {
+	var ue usageError
+	switch {
+	case errors.As(err, &ue):
+		return codeUsage
+	case errors.Is(err, charm.ErrNotFound):
+		return codeNotFound
+	case errors.Is(err, charm.ErrAmbiguous):
+		return codeAmbiguous
+	case errors.Is(err, charm.ErrConflict):
+		return codeConflict
+	case errors.Is(err, charm.ErrForbidden):
+		return codeForbidden
+	case errors.Is(err, charm.ErrSyncFailed):
+		return codeSync
+	case errors.Is(err, charm.ErrInvalidArgument),
+		errors.Is(err, models.ErrInvalidReaction),
+		errors.Is(err, models.ErrInvalidTemplate),
+		errors.Is(err, models.ErrInvalidFields):
+		return codeInvalid
+	}
+	return codeInternal
+}
+
+// cliError is the structured form of an error written to stderr.
+type cliError struct {
+	Error struct {
+		Code       string            `json:"code"`
+		Message    string            `json:"message"`
+		Candidates []charm.Candidate `json:"candidates,omitempty"`
+	} `json:"error"`
+}
+
+// reportError writes err to w, as a structured error when JSON or YAML
+// output is selected and as plain text otherwise.
+func reportError(w io.Writer, err error) {
+	if !structuredOutput() {
+		fmt.Fprintln(w, "Error:", err)
+		var ue usageError
+		if errors.As(err, &ue) {
+			fmt.Fprintln(w, "Run 'bbs --help' for usage.")
+		}
+		return
+	}
+
+	var out cliError
+	out.Error.Code = errorCode(err)
+	out.Error.Message = err.Error()
+	var amb *charm.AmbiguousError
+	if errors.As(err, &amb) {
+		out.Error.Candidates = amb.Candidates
+	}
+
+	var buf bytes.Buffer
+	if werr := writeOutput(&buf, outputFormat, out); werr != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	_, _ = w.Write(buf.Bytes())
+}
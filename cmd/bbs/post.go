@@ -4,71 +4,158 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/harper/bbs/internal/charm"
-	"github.com/harper/bbs/internal/identity"
 	"github.com/harper/bbs/internal/models"
 )
 
 var postCmd = &cobra.Command{
-	Use:   "post <thread> <message>",
+	Use:   "post <thread> [message]",
 	Short: "Post a message to a thread",
-	Args:  cobra.ExactArgs(2),
-	RunE:  runPost,
+	Long: `Post a message to a thread.
+
+The message can be given as an argument, read from a file with --file,
+or read from stdin with "-" (e.g. 'make test 2>&1 | bbs post abc123 -').
+With none of these, $EDITOR opens so you can write multi-line Markdown.
+
+With --template, the message is built from a template's typed fields
+instead ('bbs template show <name>' lists them):
+
+  bbs post abc123 --template standup --field "today=ship templates" --field status=green`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runPost,
 }
 
 var editCmd = &cobra.Command{
-	Use:   "edit <message-id> <new-content>",
+	Use:   "edit <message-id> [new-content]",
 	Short: "Edit a message",
-	Args:  cobra.ExactArgs(2),
-	RunE:  runEdit,
+	Long: `Edit a message.
+
+The new content can be given as an argument, read from a file with
+--file, or read from stdin with "-". With none of these, $EDITOR opens
+pre-filled with the current content.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runEdit,
 }
 
+var (
+	postFile     string
+	editFile     string
+	postTemplate string
+	postFields   []string
+)
+
 func init() {
 	rootCmd.AddCommand(postCmd)
 	rootCmd.AddCommand(editCmd)
+	postCmd.Flags().StringVarP(&postFile, "file", "f", "", `read the message from a file ("-" for stdin)`)
+	postCmd.Flags().StringVarP(&postTemplate, "template", "t", "", "build the message from a template")
+	postCmd.Flags().StringArrayVar(&postFields, "field", nil, "template field value as key=value (repeatable)")
+	editCmd.Flags().StringVarP(&editFile, "file", "f", "", `read the new content from a file ("-" for stdin)`)
+}
+
+// bodyArg returns the optional body argument at index i.
+func bodyArg(args []string, i int, file string) bodySource {
+	src := bodySource{File: file}
+	if len(args) > i {
+		src.Arg, src.HasArg = args[i], true
+	}
+	return src
 }
 
 func runPost(cmd *cobra.Command, args []string) error {
-	client, err := charm.Global()
+	client, err := openClient()
 	if err != nil {
 		return err
 	}
 
 	thread, err := client.ResolveThread(args[0])
 	if err != nil {
-		return fmt.Errorf("thread not found: %s", args[0])
+		return err
 	}
 
-	id := identity.GetIdentity(identityFlag, "cli")
-	msg := models.NewMessage(thread.ID, args[1], id)
+	id := resolveIdentity("cli")
+	var msg *models.Message
+	if postTemplate != "" {
+		msg, err = templateMessage(client, thread, args, id)
+	} else {
+		msg, err = plainMessage(thread, args, id)
+	}
+	if err != nil {
+		return err
+	}
 
 	if err := client.CreateMessage(msg); err != nil {
 		return fmt.Errorf("failed to post message: %w", err)
 	}
 
+	if structuredOutput() {
+		return printOutput(msg)
+	}
+
 	color.Green("Posted to: %s", thread.Subject)
 	fmt.Printf("Message ID: %s\n", msg.ID.String()[:8])
 	return nil
 }
 
+// plainMessage builds a message from the body argument, --file, stdin or $EDITOR.
+func plainMessage(thread *models.Thread, args []string, id string) (*models.Message, error) {
+	if len(postFields) > 0 {
+		return nil, usageError{errors.New("--field requires --template")}
+	}
+	content, err := readBody(bodyArg(args, 1, postFile), true, "", "Replying to: "+thread.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil, usageError{errors.New("message is empty")}
+	}
+	return models.NewMessage(thread.ID, content, id), nil
+}
+
+// templateMessage builds a message from --template and --field values.
+func templateMessage(client *charm.Client, thread *models.Thread, args []string, id string) (*models.Message, error) {
+	if len(args) > 1 || postFile != "" {
+		return nil, usageError{errors.New("--template builds the message from --field values; do not also give a body")}
+	}
+	values, err := parseFieldValues(postFields)
+	if err != nil {
+		return nil, err
+	}
+	t, err := client.GetTemplate(postTemplate, &thread.TopicID)
+	if err != nil {
+		return nil, err
+	}
+	return t.NewMessage(thread.ID, values, id)
+}
+
 func runEdit(cmd *cobra.Command, args []string) error {
-	client, err := charm.Global()
+	client, err := openClient()
 	if err != nil {
 		return err
 	}
 
 	msg, err := client.ResolveMessage(args[0])
 	if err != nil {
-		return fmt.Errorf("message not found: %s", args[0])
+		return err
 	}
 
-	msg.Content = args[1]
+	content, err := readBody(bodyArg(args, 1, editFile), true, msg.Content, "Editing message "+msg.ID.String()[:8])
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(content) == "" {
+		return usageError{errors.New("message is empty")}
+	}
+
+	msg.Content = content
 	now := time.Now()
 	msg.EditedAt = &now
 
@@ -76,6 +163,10 @@ func runEdit(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if structuredOutput() {
+		return printOutput(msg)
+	}
+
 	color.Green("Message updated")
 	return nil
 }
@@ -0,0 +1,147 @@
+// ABOUTME: Reaction CLI commands
+// ABOUTME: React to messages and list who reacted or acknowledged
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/models"
+)
+
+var reactCmd = &cobra.Command{
+	Use:   "react <message-id> <reaction>",
+	Short: "React to a message",
+	Long: `React to a message with an emoji or keyword instead of posting a reply.
+
+Use "ack" to acknowledge that you have seen a message. Shortcodes such as
++1, :eyes: and :tada: are stored as their emoji.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runReact,
+}
+
+var reactionsCmd = &cobra.Command{
+	Use:   "reactions <message-id> [reaction]",
+	Short: "List who reacted to a message",
+	Long:  "List reactions on a message, or only the identities that used one reaction (e.g. 'ack').",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE:  runReactions,
+}
+
+var removeReaction bool
+
+func init() {
+	rootCmd.AddCommand(reactCmd, reactionsCmd)
+	reactCmd.Flags().BoolVar(&removeReaction, "remove", false, "remove your reaction instead of adding it")
+}
+
+func runReact(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	msg, err := client.ResolveMessage(args[0])
+	if err != nil {
+		return err
+	}
+
+	reaction, err := models.NormalizeReaction(args[1])
+	if err != nil {
+		return err
+	}
+
+	id := resolveIdentity("cli")
+	if removeReaction {
+		if err := client.RemoveReaction(msg.ID, reaction, id); err != nil {
+			return err
+		}
+		if structuredOutput() {
+			return printReactionCounts(client, msg.ID)
+		}
+		color.Yellow("Removed %s", reaction)
+		return nil
+	}
+
+	if err := client.AddReaction(models.NewReaction(msg.ID, reaction, id)); err != nil {
+		return err
+	}
+	if structuredOutput() {
+		return printReactionCounts(client, msg.ID)
+	}
+	color.Green("Reacted %s", reaction)
+	return nil
+}
+
+func runReactions(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	msg, err := client.ResolveMessage(args[0])
+	if err != nil {
+		return err
+	}
+
+	reactions, err := client.ListReactions(msg.ID)
+	if err != nil {
+		return err
+	}
+
+	counts := models.SummarizeReactions(reactions)
+	if len(args) > 1 {
+		want, err := models.NormalizeReaction(args[1])
+		if err != nil {
+			return err
+		}
+		var filtered []models.ReactionCount
+		for _, rc := range counts {
+			if rc.Reaction == want {
+				filtered = append(filtered, rc)
+			}
+		}
+		counts = filtered
+	}
+
+	if structuredOutput() {
+		return printOutput(counts)
+	}
+
+	if len(counts) == 0 {
+		fmt.Println("No reactions.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REACTION\tCOUNT\tBY")
+	for _, rc := range counts {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", rc.Reaction, rc.Count, strings.Join(rc.By, ", "))
+	}
+	return w.Flush()
+}
+
+// formatReactionCounts renders counts as "ack 2  👍 1" for compact display.
+func formatReactionCounts(counts []models.ReactionCount) string {
+	parts := make([]string, 0, len(counts))
+	for _, rc := range counts {
+		parts = append(parts, fmt.Sprintf("%s %d", rc.Reaction, rc.Count))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// printReactionCounts prints a message's reaction counts in the structured format.
+func printReactionCounts(client *charm.Client, messageID models.UUID) error {
+	reactions, err := client.ListReactions(messageID)
+	if err != nil {
+		return err
+	}
+	return printOutput(models.SummarizeReactions(reactions))
+}
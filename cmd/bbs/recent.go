@@ -0,0 +1,93 @@
+// ABOUTME: Recent activity CLI command
+// ABOUTME: Shows new threads, posts and edits across all topics
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/models"
+)
+
+var recentCmd = &cobra.Command{
+	Use:   "recent",
+	Short: "Show recent activity across all topics",
+	Long: `Show new threads, posts and edits across all non-archived topics,
+newest first.
+
+--since takes a duration like 2h, a date, or an RFC 3339 time.`,
+	Args: cobra.NoArgs,
+	RunE: runRecent,
+}
+
+var (
+	recentSince string
+	recentLimit int
+)
+
+func init() {
+	rootCmd.AddCommand(recentCmd)
+	recentCmd.Flags().StringVar(&recentSince, "since", "7d", "only show activity after this time")
+	recentCmd.Flags().IntVarP(&recentLimit, "limit", "n", 20, "maximum number of entries (0 for all)")
+}
+
+func runRecent(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	since, err := charm.ParseSince(recentSince, time.Now())
+	if err != nil {
+		return err
+	}
+
+	items, err := client.RecentActivity(since, recentLimit)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printOutput(items)
+	}
+
+	if len(items) == 0 {
+		fmt.Println("No recent activity.")
+		return nil
+	}
+	printActivity(items)
+	return nil
+}
+
+// printActivity prints activity entries in the order given.
+func printActivity(items []*models.Activity) {
+	faint := color.New(color.Faint)
+	for _, item := range items {
+		subject := fmt.Sprintf("%q", item.Subject)
+		if item.Locked {
+			subject = "🔒 " + subject
+		}
+		topic := item.Topic
+		if item.ReadOnly {
+			topic += " (read-only)"
+		}
+		switch item.Kind {
+		case models.ActivityThreadCreated:
+			fmt.Printf("%s started %s in %s\n", item.Actor, subject, topic)
+		case models.ActivityMessagePosted:
+			fmt.Printf("%s posted in %s\n", item.Actor, subject)
+		case models.ActivityMessageEdited:
+			fmt.Printf("%s edited a post in %s\n", item.Actor, subject)
+		}
+		faint.Printf("  %s · thread %s\n", item.At.Format("Jan 02 15:04"), item.ThreadID.String()[:8])
+		if item.Excerpt != "" {
+			fmt.Printf("  %s\n", item.Excerpt)
+		}
+		fmt.Println()
+	}
+}
@@ -4,8 +4,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/harper/bbs/internal/charm"
@@ -14,7 +18,19 @@ import (
 	"github.com/harper/bbs/internal/tui"
 )
 
-var identityFlag string
+var (
+	identityFlag string
+	boardFlag    string
+)
+
+// settings is the configuration this command runs with: defaults, the
+// config file, the board picked by --board or BBS_BOARD and the
+// environment, resolved before it runs. Flags apply on top.
+var settings = &config.Resolved{
+	Config:    *config.Defaults(),
+	BoardName: config.DefaultBoard,
+	DB:        config.DefaultBoardDB,
+}
 
 var rootCmd = &cobra.Command{
 	Use:   "bbs",
@@ -35,38 +51,117 @@ Topics → Threads → Messages
 Data syncs automatically to the cloud via Charm.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Launch TUI if no subcommand
-		client, err := charm.Global()
+		client, err := openClient()
 		if err != nil {
 			return fmt.Errorf("failed to get charm client: %w", err)
 		}
-		return tui.Run(client, identity.GetIdentity(identityFlag, "tui"))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		client.StartSyncer(ctx)
+		return tui.Run(client, resolveIdentity("tui"), tui.WithSyncRefresh(time.Duration(settings.TUI.SyncRefresh)))
 	},
+	SilenceErrors: true,
+	SilenceUsage:  true,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(); err != nil {
+			return err
+		}
+
 		// Skip init for help commands
 		if cmd.Name() == "help" || cmd.Name() == "version" {
 			return nil
 		}
 
-		// Load config and apply environment
 		cfg, err := config.Load()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
-		cfg.ApplyEnvironment()
-
-		// Initialize Charm
-		if err := charm.InitGlobal(); err != nil {
-			return fmt.Errorf("failed to initialize charm: %w", err)
+		resolved, err := config.Resolve(cfg, boardFlag, os.Getenv)
+		if err != nil {
+			return usageError{err}
 		}
+		settings = resolved
 
+		// The charm library fetches encryption keys through a client of its
+		// own built from CHARM_HOST, whatever host our client was given
+		// (kv.Open -> fs.NewFSWithClient -> crypt.NewCrypt ->
+		// client.NewClientWithDefaults), so export the resolved host once
+		// here. This stays until the fork lets the caller's client fetch the
+		// keys; see docs/plans/2026-10-18-charm-fork-client-keys.md. Nothing
+		// else writes the environment; an explicit CHARM_HOST already won in
+		// Resolve.
+		if os.Getenv("CHARM_HOST") != settings.CharmHost {
+			if err := os.Setenv("CHARM_HOST", settings.CharmHost); err != nil {
+				return err
+			}
+		}
 		return nil
 	},
 	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
-		// Charm client is global, no need to close per-command
+		flushOutbox(cmd)
 		return nil
 	},
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&identityFlag, "as", "", "identity override (username)")
+	rootCmd.PersistentFlags().StringVar(&boardFlag, "board", "", "board profile to use (default: current board, or BBS_BOARD)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "output format: table, json or yaml")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError{err}
+	})
+}
+
+// openClient returns a client for the active board.
+func openClient() (*charm.Client, error) {
+	return charm.NewClient(
+		charm.WithDBName(settings.DB),
+		charm.WithCharmHost(settings.CharmHost),
+		charm.WithAutoSync(settings.AutoSyncEnabled()),
+		charm.WithStaleThreshold(time.Duration(settings.StaleThreshold)),
+		charm.WithModerators(settings.Moderators),
+	)
+}
+
+// resolveIdentity returns the identity to act as: --as, then the
+// configured identity (BBS_USER, the board's, then the config file's),
+// then $USER.
+func resolveIdentity(source string) string {
+	name := identityFlag
+	if name == "" {
+		name = settings.Identity
+	}
+	return identity.GetIdentity(name, source)
+}
+
+// moderatorIdentity returns the identity moderator rights are checked
+// against: the configured identity, then $USER. --as is ignored, since
+// anyone can pass it; the local config and environment are trusted.
+func moderatorIdentity() string {
+	return identity.GetIdentity(settings.Identity, "cli")
+}
+
+// cliSyncTimeout bounds how long a command waits on exit to push its writes.
+const cliSyncTimeout = 10 * time.Second
+
+// flushOutbox pushes queued writes when a command finishes, unless a retry
+// after an earlier failure is not due yet. A failure only warns: the
+// writes are committed locally and stay queued for the next attempt.
+func flushOutbox(cmd *cobra.Command) {
+	if cmd.Name() == "help" || cmd.Name() == "version" || cmd == serverCmd || cmd.Parent() == configCmd || cmd.Parent() == syncCmd && cmd != syncResolveCmd {
+		return
+	}
+	client, err := openClient()
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cliSyncTimeout)
+	defer cancel()
+	if _, err := client.FlushIfDue(ctx); err != nil {
+		pending := 0
+		if st, err := client.SyncState(); err == nil {
+			pending = len(st.Pending)
+		}
+		color.New(color.FgYellow).Fprintf(os.Stderr, "warning: %v; %d writes still queued (see 'bbs sync status')\n", err, pending)
+	}
 }
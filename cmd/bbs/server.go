@@ -0,0 +1,122 @@
+// ABOUTME: Server command that runs a self-hosted Charm server
+// ABOUTME: Lets teams and CI sync bbs data without the public Charm service
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/bbs/internal/charm"
+)
+
+var serverCmd = &cobra.Command{
+	Use:   "server",
+	Short: "Run a self-hosted Charm server",
+	Long: `Run an embedded Charm server that bbs clients can sync through.
+
+Data, including the server's host key, is kept in --data-dir; use --temp
+for a throwaway server in tests and CI. Point clients at the server by
+setting charm_host in the config, or with the environment variables
+printed at startup.`,
+	Args: cobra.NoArgs,
+	RunE: runServer,
+}
+
+var (
+	serverDataDir    string
+	serverTemp       bool
+	serverHost       string
+	serverBind       string
+	serverSSHPort    int
+	serverHTTPPort   int
+	serverHealthPort int
+)
+
+// serverShutdownTimeout bounds how long the server waits for open connections on exit.
+const serverShutdownTimeout = 30 * time.Second
+
+func init() {
+	rootCmd.AddCommand(serverCmd)
+	serverCmd.Flags().StringVar(&serverDataDir, "data-dir", charm.ServerDataDir(), "directory for the server database, keys and files")
+	serverCmd.Flags().BoolVar(&serverTemp, "temp", false, "use a temporary data dir, removed on exit")
+	serverCmd.Flags().StringVar(&serverHost, "host", "localhost", "host name clients connect to")
+	serverCmd.Flags().StringVar(&serverBind, "bind", "", "address to listen on (default all interfaces)")
+	serverCmd.Flags().IntVar(&serverSSHPort, "ssh-port", charm.DefaultServerSSHPort, "SSH port (0 picks a free port)")
+	serverCmd.Flags().IntVar(&serverHTTPPort, "http-port", charm.DefaultServerHTTPPort, "HTTP port (0 picks a free port)")
+	serverCmd.Flags().IntVar(&serverHealthPort, "health-port", charm.DefaultServerHealthPort, "health check port (0 picks a free port)")
+}
+
+func runServer(cmd *cobra.Command, args []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	dataDir := serverDataDir
+	if serverTemp {
+		dir, err := os.MkdirTemp("", "bbs-server-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir) //nolint:errcheck
+		dataDir = dir
+	}
+
+	srv, err := charm.StartServer(charm.ServerConfig{
+		DataDir:    dataDir,
+		Host:       serverHost,
+		BindAddr:   serverBind,
+		SSHPort:    serverSSHPort,
+		HTTPPort:   serverHTTPPort,
+		HealthPort: serverHealthPort,
+	})
+	if err != nil {
+		return err
+	}
+
+	cfg := srv.Config()
+	if structuredOutput() {
+		if err := printOutput(struct {
+			DataDir    string
+			SSHPort    int
+			HTTPPort   int
+			HealthPort int
+			Env        map[string]string
+		}{cfg.DataDir, cfg.SSHPort, cfg.HTTPPort, cfg.HealthPort, srv.ClientEnv()}); err != nil {
+			return err
+		}
+	} else {
+		color.Green("✓ Charm server running (ssh :%d, http :%d)", cfg.SSHPort, cfg.HTTPPort)
+		fmt.Printf("Data: %s\n\n", cfg.DataDir)
+		fmt.Println("Point bbs at it with:")
+		printServerEnv(srv.ClientEnv())
+	}
+
+	select {
+	case <-ctx.Done():
+	case err := <-srv.Done():
+		return fmt.Errorf("server stopped: %w", err)
+	}
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancelShutdown()
+	return srv.Shutdown(shutdownCtx)
+}
+
+// printServerEnv prints shell exports for the client environment.
+func printServerEnv(env map[string]string) {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  export %s=%s\n", name, env[name])
+	}
+}
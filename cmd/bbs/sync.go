@@ -7,11 +7,12 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
-	charmclient "github.com/charmbracelet/charm/client"
-	"github.com/charmbracelet/charm/kv"
 	"github.com/charmbracelet/charm/ui/link"
 	"github.com/charmbracelet/charm/ui/linkgen"
 	"github.com/fatih/color"
@@ -29,18 +30,33 @@ var syncCmd = &cobra.Command{
 Authentication is automatic via SSH keys - no passwords needed.
 Your data is encrypted end-to-end before leaving your device.
 
+Writes need the server: the local database fetches its encryption keys
+from it when opened, and backs up each change when it is done. Writes are
+also queued, and any the backup missed are pushed in the background and
+retried with backoff.
+
 Commands:
-  status  - Show sync status and user info
-  link    - Link this device to your Charm account
-  repair  - Repair database integrity issues
-  reset   - Clear local data and re-sync from cloud
-  wipe    - Delete all local and cloud data`,
+  status    - Show sync status, queued writes and conflicts
+  daemon    - Keep the local replica in sync in the background
+  conflicts - Review edits that conflicted with another device
+  resolve   - Keep the local or remote version of a conflict
+  link      - Link this device to your Charm account
+  repair    - Repair database integrity issues
+  reset     - Clear local data and re-sync from cloud
+  wipe      - Delete all local and cloud data`,
 }
 
 var syncStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show sync status",
-	RunE:  runSyncStatus,
+	Long: `Show whether local data is up to date with the Charm server.
+
+Reports the last sync and whether it is older than the stale threshold,
+local record counts, the database size on disk, writes still waiting to
+be pushed, the sync daemon's health and unresolved conflicts.
+
+Use --json (short for -o json) for monitoring.`,
+	RunE: runSyncStatus,
 }
 
 var syncLinkCmd = &cobra.Command{
@@ -84,93 +100,321 @@ This cannot be undone.`,
 	RunE: runSyncWipe,
 }
 
-var forceRepair bool
+var (
+	forceRepair    bool
+	syncStatusJSON bool
+)
 
 func init() {
 	rootCmd.AddCommand(syncCmd)
 	syncCmd.AddCommand(syncStatusCmd, syncLinkCmd, syncRepairCmd, syncResetCmd, syncWipeCmd)
 	syncRepairCmd.Flags().BoolVar(&forceRepair, "force", false, "Force repair even if initial checks fail")
+	syncStatusCmd.Flags().BoolVar(&syncStatusJSON, "json", false, "print status as JSON (same as -o json)")
 }
 
 func runSyncStatus(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load()
-	if err != nil {
-		return err
+	if syncStatusJSON {
+		outputFormat = outputJSON
+	}
+	status := syncStatus{
+		Config:    config.GetConfigPath(),
+		Board:     settings.BoardName,
+		CharmHost: settings.CharmHost,
+		Status:    "not initialized",
+	}
+
+	// Check if charm is initialized and linked
+	client, err := openClient()
+	if err == nil {
+		st, err := client.SyncState()
+		if err != nil {
+			return err
+		}
+		status.addSyncState(st)
+		health, err := client.DaemonHealth()
+		if err != nil {
+			return err
+		}
+		status.addDaemonHealth(health, time.Now())
+		// Opening the database needs the charm keys; status still reports
+		// the outbox without it
+		if stats, err := client.Stats(); err == nil {
+			status.addStats(stats, time.Now())
+		}
+		if userID, err := client.ID(); err != nil {
+			status.Status = "not linked"
+		} else {
+			status.Status = "connected"
+			status.UserID = strings.TrimSpace(userID)
+
+			// Get authorized keys
+			cc, err := client.CharmClient()
+			if err != nil {
+				return err
+			}
+			keys, err := cc.AuthorizedKeys()
+			if err == nil && keys != "" {
+				status.Devices = len(strings.Split(strings.TrimSpace(keys), "\n"))
+			}
+		}
+	}
+
+	if structuredOutput() {
+		return printOutput(status)
 	}
 
 	fmt.Println("Sync Status")
 	fmt.Println("───────────")
 
 	// Show config
-	fmt.Printf("Config:     %s\n", config.GetConfigPath())
-	fmt.Printf("Charm Host: %s\n", cfg.GetCharmHost())
+	fmt.Printf("Config:     %s\n", status.Config)
+	fmt.Printf("Board:      %s (%s)\n", status.Board, settings.DB)
+	fmt.Printf("Charm Host: %s\n", status.CharmHost)
 
-	// Check if charm is initialized
-	client, err := charm.Global()
-	if err != nil {
+	switch status.Status {
+	case "not initialized":
 		fmt.Print("\nStatus:     ")
 		color.Yellow("Not initialized")
 		fmt.Println()
 		fmt.Println("\nRun any BBS command to initialize, or 'bbs sync link' to link devices.")
-		return nil
-	}
-
-	// Get user info
-	userID, err := client.ID()
-	if err != nil {
+	case "not linked":
 		fmt.Print("\nStatus:     ")
 		color.Yellow("Not linked")
 		fmt.Println()
 		fmt.Println("\nRun 'bbs sync link' to link this device.")
-		return nil
+	default:
+		fmt.Printf("User ID:    %s\n", status.UserID)
+		fmt.Print("\nStatus:     ")
+		color.Green("Connected")
+		fmt.Println()
+		if status.Devices > 0 {
+			fmt.Printf("Devices:    %d linked\n", status.Devices)
+		}
 	}
+	printLocalData(status)
+	printSyncQueue(status)
+	return nil
+}
 
-	// Show user info
-	fmt.Printf("User ID:    %s\n", strings.TrimSpace(userID))
-	fmt.Print("\nStatus:     ")
-	color.Green("Connected")
+// printLocalData shows record counts and the database size.
+func printLocalData(status syncStatus) {
+	if status.Records == nil {
+		return
+	}
 	fmt.Println()
+	fmt.Printf("Records:    %s\n", formatRecords(status.Records))
+	if status.DBPath != "" {
+		fmt.Printf("Database:   %s (%s)\n", status.DBPath, formatBytes(status.DBSize))
+	}
+}
 
-	// Get authorized keys
-	cc, err := client.CharmClient()
-	if err != nil {
-		return err
+// recordOrder lists the main record kinds first in status output.
+var recordOrder = []string{"topic", "thread", "message", "attachment"}
+
+// formatRecords lists counts for the main record kinds, then the rest by name.
+func formatRecords(records map[string]int) string {
+	parts := make([]string, 0, len(records))
+	for _, kind := range recordOrder {
+		parts = append(parts, fmt.Sprintf("%s: %d", kind, records[kind]))
 	}
-	keys, err := cc.AuthorizedKeys()
-	if err == nil && keys != "" {
-		lines := strings.Split(strings.TrimSpace(keys), "\n")
-		fmt.Printf("Devices:    %d linked\n", len(lines))
+	rest := make([]string, 0, len(records))
+	for kind := range records {
+		if !slices.Contains(recordOrder, kind) {
+			rest = append(rest, kind)
+		}
 	}
+	sort.Strings(rest)
+	for _, kind := range rest {
+		parts = append(parts, fmt.Sprintf("%s: %d", kind, records[kind]))
+	}
+	return strings.Join(parts, ", ")
+}
 
-	return nil
+// formatBytes renders a size in the largest whole binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
 }
 
-func runSyncLink(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load()
-	if err != nil {
-		return err
+// printSyncQueue shows queued writes, the last sync attempt and conflicts.
+func printSyncQueue(status syncStatus) {
+	fmt.Println()
+	fmt.Print("Last sync:  ")
+	last := "never"
+	if status.LastSync != nil {
+		last = fmt.Sprintf("%s (%s ago)", status.LastSync.Local().Format("2006-01-02 15:04:05"), time.Since(*status.LastSync).Round(time.Second))
 	}
+	if status.Stale {
+		color.Yellow("%s, stale (threshold %s)", last, status.StaleThreshold)
+	} else {
+		fmt.Println(last)
+	}
+	fmt.Print("Pending:    ")
+	if status.Pending == 0 {
+		color.Green("none")
+	} else {
+		color.Yellow("%d queued (oldest %s ago)", status.Pending, time.Since(*status.OldestPending).Round(time.Second))
+	}
+	if status.LastError != "" {
+		fmt.Print("Last error: ")
+		color.Red(status.LastError)
+		if status.NextRetry != nil {
+			fmt.Printf("Next retry: %s\n", status.NextRetry.Local().Format("2006-01-02 15:04:05"))
+		}
+	}
+	if status.UnsentOps > 0 {
+		fmt.Print("Unsent ops: ")
+		color.Yellow("%d not yet backed up by the charm library", status.UnsentOps)
+	}
+	printDaemon(status.Daemon)
+	if len(status.Conflicts) > 0 {
+		fmt.Print("Conflicts:  ")
+		color.Yellow("%d (the server's version was kept)", len(status.Conflicts))
+		for _, c := range status.Conflicts {
+			fmt.Printf("  %s  %s\n", c.DetectedAt.Local().Format("2006-01-02 15:04"), c.Key)
+		}
+		fmt.Println("\nRun 'bbs sync conflicts' to review them.")
+	}
+}
+
+// syncStatus is the structured output of 'bbs sync status'.
+type syncStatus struct {
+	Config         string
+	Board          string
+	CharmHost      string
+	Status         string
+	UserID         string
+	Devices        int
+	LastSync       *time.Time
+	Stale          bool
+	StaleThreshold string
+	Records        map[string]int
+	DBPath         string
+	DBSize         int64
+	UnsentOps      int64
+	Pending        int
+	OldestPending  *time.Time
+	LastError      string
+	NextRetry      *time.Time
+	Conflicts      []syncConflict
+	Daemon         *daemonStatus
+}
 
-	// Apply environment (set CHARM_HOST)
-	cfg.ApplyEnvironment()
+// daemonStatus is the health reported by 'bbs sync daemon'.
+type daemonStatus struct {
+	Running     bool
+	PID         int
+	Interval    string
+	LastSuccess *time.Time
+	Lag         string
+	LastError   string
+	NextSync    *time.Time
+}
 
-	// Get charm config
-	charmCfg, err := getCharmConfig()
+// syncConflict is a key whose local write lost to a remote change.
+type syncConflict struct {
+	Key        string
+	DetectedAt time.Time
+}
+
+// addSyncState fills in the outbox and the outcome of the last sync.
+func (s *syncStatus) addSyncState(st *charm.SyncState) {
+	if !st.LastSuccess.IsZero() {
+		s.LastSync = &st.LastSuccess
+	}
+	s.Pending = len(st.Pending)
+	if oldest := st.OldestPending(); !oldest.IsZero() {
+		s.OldestPending = &oldest
+	}
+	s.LastError = st.LastError
+	if st.LastError != "" && len(st.Pending) > 0 {
+		s.NextRetry = &st.NextAttempt
+	}
+	for _, c := range st.Conflicts {
+		s.Conflicts = append(s.Conflicts, syncConflict{Key: c.Key, DetectedAt: c.DetectedAt})
+	}
+}
+
+// addStats fills in local record counts and the database's own sync time,
+// which also reflects syncs by processes that keep no sync state.
+func (s *syncStatus) addStats(stats *charm.Stats, now time.Time) {
+	latest := *stats
+	if s.LastSync != nil && s.LastSync.After(latest.LastSync) {
+		latest.LastSync = *s.LastSync
+	}
+	if !latest.LastSync.IsZero() {
+		s.LastSync = &latest.LastSync
+	}
+	s.Stale = latest.Stale(now)
+	s.StaleThreshold = stats.StaleThreshold.String()
+	s.Records = stats.Records
+	s.DBPath = stats.Path
+	s.DBSize = stats.SizeBytes
+	s.UnsentOps = stats.UnsentOps
+}
+
+// addDaemonHealth fills in the sync daemon's health, if one has run.
+func (s *syncStatus) addDaemonHealth(h *charm.DaemonHealth, now time.Time) {
+	if h == nil {
+		return
+	}
+	d := &daemonStatus{Running: h.Running(now)}
+	if d.Running {
+		d.PID = h.PID
+		d.Interval = h.Interval.String()
+		d.Lag = h.Lag(now).Round(time.Second).String()
+		d.LastError = h.LastError
+		d.NextSync = &h.NextSync
+	}
+	if !h.LastSuccess.IsZero() {
+		d.LastSuccess = &h.LastSuccess
+	}
+	s.Daemon = d
+}
+
+// printDaemon shows whether the sync daemon is keeping the replica warm.
+func printDaemon(d *daemonStatus) {
+	fmt.Print("Daemon:     ")
+	switch {
+	case d == nil || !d.Running:
+		fmt.Println("not running")
+	case d.LastError != "":
+		color.Yellow("failing (pid %d), %s behind, next try %s", d.PID, d.Lag, d.NextSync.Local().Format("15:04:05"))
+	default:
+		color.Green("running (pid %d, every %s), %s behind", d.PID, d.Interval, d.Lag)
+	}
+}
+
+func runSyncLink(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return fmt.Errorf("charm not initialized: %w", err)
+	}
+	charmCfg, err := client.CharmConfig()
 	if err != nil {
 		return fmt.Errorf("get charm config: %w", err)
 	}
 
+	out := promptWriter()
 	var p *tea.Program
 	if len(args) == 0 {
 		// Generate a link code
-		fmt.Println("Generating link code...")
-		fmt.Println("Share this code with another device to link it to your account.")
-		fmt.Println()
+		fmt.Fprintln(out, "Generating link code...")
+		fmt.Fprintln(out, "Share this code with another device to link it to your account.")
+		fmt.Fprintln(out)
 		p = linkgen.NewProgram(charmCfg, "bbs")
 	} else {
 		// Join a link session
-		fmt.Println("Linking to existing account...")
-		fmt.Println()
+		fmt.Fprintln(out, "Linking to existing account...")
+		fmt.Fprintln(out)
 		p = link.NewProgram(charmCfg, args[0])
 	}
 
@@ -178,20 +422,32 @@ func runSyncLink(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if structuredOutput() {
+		return printOutput(struct{ Linked bool }{true})
+	}
+
 	color.Green("\n✓ Device linked successfully")
 	return nil
 }
 
 func runSyncRepair(cmd *cobra.Command, args []string) error {
-	_, err := charm.Global()
+	client, err := openClient()
 	if err != nil {
 		return fmt.Errorf("charm not initialized: %w", err)
 	}
 
+	if structuredOutput() {
+		result, err := client.Repair(forceRepair)
+		if err != nil {
+			return err
+		}
+		return printOutput(result)
+	}
+
 	fmt.Println("Running database repair...")
 	fmt.Println()
 
-	result, err := kv.Repair(charm.DBName, forceRepair)
+	result, err := client.Repair(forceRepair)
 	if err != nil {
 		color.Red("✗ Repair failed: %v", err)
 		return err
@@ -237,66 +493,82 @@ func runSyncRepair(cmd *cobra.Command, args []string) error {
 }
 
 func runSyncReset(cmd *cobra.Command, args []string) error {
-	_, err := charm.Global()
+	client, err := openClient()
 	if err != nil {
 		return fmt.Errorf("charm not initialized: %w", err)
 	}
 
 	// Confirm with user
-	fmt.Println("This will DELETE your local BBS data and re-sync from the cloud.")
-	fmt.Println("Your cloud data will NOT be affected.")
-	fmt.Print("\nContinue? [y/N]: ")
+	out := promptWriter()
+	fmt.Fprintf(out, "This will DELETE your local data for board %q and re-sync from the cloud.\n", settings.BoardName)
+	fmt.Fprintln(out, "Your cloud data will NOT be affected.")
+	fmt.Fprint(out, "\nContinue? [y/N]: ")
 
 	reader := bufio.NewReader(os.Stdin)
 	confirmation, _ := reader.ReadString('\n')
 	confirmation = strings.ToLower(strings.TrimSpace(confirmation))
 
 	if confirmation != "y" && confirmation != "yes" {
-		fmt.Println("Aborted.")
+		fmt.Fprintln(out, "Aborted.")
+		if structuredOutput() {
+			return printOutput(struct{ Reset bool }{false})
+		}
 		return nil
 	}
 
-	fmt.Println("\nResetting local data...")
-	if err := kv.Reset(charm.DBName); err != nil {
+	fmt.Fprintln(out, "\nResetting local data...")
+	if err := client.Reset(); err != nil {
 		return fmt.Errorf("reset failed: %w", err)
 	}
 
+	if structuredOutput() {
+		return printOutput(struct{ Reset bool }{true})
+	}
+
 	color.Green("✓ Local data reset and re-synced from cloud")
 	return nil
 }
 
 func runSyncWipe(cmd *cobra.Command, args []string) error {
-	_, err := charm.Global()
+	client, err := openClient()
 	if err != nil {
 		return fmt.Errorf("charm not initialized: %w", err)
 	}
 
 	// Confirm with user
-	fmt.Println("WARNING: This will PERMANENTLY DELETE all BBS data!")
-	fmt.Println()
-	fmt.Println("This includes:")
-	fmt.Println("  • All local database files")
-	fmt.Println("  • All cloud backups on Charm servers")
-	fmt.Println()
-	color.Red("THIS CANNOT BE UNDONE!")
-	fmt.Println()
-	fmt.Print("Type 'wipe' to confirm: ")
+	out := promptWriter()
+	fmt.Fprintf(out, "WARNING: This will PERMANENTLY DELETE all data for board %q!\n", settings.BoardName)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "This includes:")
+	fmt.Fprintln(out, "  • All local database files")
+	fmt.Fprintln(out, "  • All cloud backups on Charm servers")
+	fmt.Fprintln(out)
+	color.New(color.FgRed).Fprintln(out, "THIS CANNOT BE UNDONE!")
+	fmt.Fprintln(out)
+	fmt.Fprint(out, "Type 'wipe' to confirm: ")
 
 	reader := bufio.NewReader(os.Stdin)
 	confirmation, _ := reader.ReadString('\n')
 	confirmation = strings.TrimSpace(confirmation)
 
 	if confirmation != "wipe" {
-		fmt.Println("Aborted.")
+		fmt.Fprintln(out, "Aborted.")
+		if structuredOutput() {
+			return printOutput(struct{ Wiped bool }{false})
+		}
 		return nil
 	}
 
-	fmt.Println("\nWiping all data...")
-	result, err := kv.Wipe(charm.DBName)
+	fmt.Fprintln(out, "\nWiping all data...")
+	result, err := client.Wipe()
 	if err != nil {
 		return fmt.Errorf("wipe failed: %w", err)
 	}
 
+	if structuredOutput() {
+		return printOutput(result)
+	}
+
 	// Show wipe results
 	fmt.Println()
 	fmt.Println("Wipe Results:")
@@ -318,27 +590,3 @@ func runSyncWipe(cmd *cobra.Command, args []string) error {
 	color.Green("✓ All BBS data permanently deleted")
 	return nil
 }
-
-// getCharmConfig returns the charm client configuration.
-func getCharmConfig() (*charmclient.Config, error) {
-	cfg, err := config.Load()
-	if err != nil {
-		return nil, err
-	}
-
-	// Apply environment first
-	cfg.ApplyEnvironment()
-
-	// Load charm config from environment
-	charmCfg, err := charmclient.ConfigFromEnv()
-	if err != nil {
-		return nil, err
-	}
-
-	// Override host if set in our config
-	if cfg.CharmHost != "" {
-		charmCfg.Host = cfg.CharmHost
-	}
-
-	return charmCfg, nil
-}
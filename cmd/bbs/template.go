@@ -0,0 +1,376 @@
+// ABOUTME: Template CLI commands
+// ABOUTME: Implements template list, show, create, delete and posts subcommands
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/models"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage message templates",
+	Long: `Templates are named message shapes with typed fields, such as a
+standup or an incident note. Post from one with
+'bbs post <thread> --template <name> --field key=value ...'.
+
+Templates are global unless created with --topic, in which case they
+only apply to threads in that topic and take precedence over a global
+template of the same name.`,
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List templates",
+	Args:  cobra.NoArgs,
+	RunE:  runTemplateList,
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a template's fields and body",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTemplateShow,
+}
+
+var templateCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a template",
+	Long: `Create a template from --field specs or a YAML/JSON definition file.
+
+Field specs are name[:type][!], where ! marks a required field:
+
+  bbs template create standup -d "Daily standup" \
+    --field "yesterday:text!" --field "today:text!" --field blockers:text \
+    --field "status:enum=green|yellow|red!"
+
+Types are string, text, number, bool, date and enum=a|b|c. --body may
+reference fields as {{name}}; without it every field is listed in order.
+
+A definition file looks like:
+
+  description: Incident note
+  fields:
+    - name: severity
+      type: enum
+      options: [sev1, sev2, sev3]
+      required: true
+    - name: summary
+      type: text
+      required: true
+  body: "**{{severity}}** {{summary}}"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTemplateCreate,
+}
+
+var templateDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a template (creator or moderators only)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTemplateDelete,
+}
+
+var templatePostsCmd = &cobra.Command{
+	Use:   "posts <name>",
+	Short: "List messages posted from a template",
+	Long: `List messages posted from a template, newest first.
+
+Filter on field values with --field, e.g. --field status=red.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTemplatePosts,
+}
+
+var (
+	templateTopic       string
+	templateDescription string
+	templateFieldSpecs  []string
+	templateBody        string
+	templateFile        string
+	templateFilters     []string
+)
+
+func init() {
+	rootCmd.AddCommand(templateCmd)
+	templateCmd.AddCommand(templateListCmd, templateShowCmd, templateCreateCmd, templateDeleteCmd, templatePostsCmd)
+
+	templateCmd.PersistentFlags().StringVar(&templateTopic, "topic", "", "topic the template is scoped to")
+	templateCreateCmd.Flags().StringVarP(&templateDescription, "description", "d", "", "what the template is for")
+	templateCreateCmd.Flags().StringArrayVar(&templateFieldSpecs, "field", nil, "field spec name[:type][!] (repeatable)")
+	templateCreateCmd.Flags().StringVar(&templateBody, "body", "", "message body with {{field}} placeholders")
+	templateCreateCmd.Flags().StringVarP(&templateFile, "file", "f", "", "read the template definition from a YAML or JSON file")
+	templatePostsCmd.Flags().StringArrayVar(&templateFilters, "field", nil, "only posts with this field value, as key=value (repeatable)")
+}
+
+// templateScope resolves --topic to a topic ID, or nil for global templates.
+func templateScope(client *charm.Client) (*models.UUID, error) {
+	if templateTopic == "" {
+		return nil, nil
+	}
+	topic, err := client.ResolveTopic(templateTopic)
+	if err != nil {
+		return nil, err
+	}
+	return &topic.ID, nil
+}
+
+// parseFieldValues parses repeated key=value flags.
+func parseFieldValues(pairs []string) (map[string]string, error) {
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, usageError{fmt.Errorf("invalid field %q: use key=value", pair)}
+		}
+		values[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+	return values, nil
+}
+
+func runTemplateList(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	scope, err := templateScope(client)
+	if err != nil {
+		return err
+	}
+
+	templates, err := client.ListTemplates(scope)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printOutput(templates)
+	}
+
+	if len(templates) == 0 {
+		fmt.Println("No templates found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSCOPE\tFIELDS\tDESCRIPTION")
+	for _, t := range templates {
+		scope := "global"
+		if t.TopicID != nil {
+			scope = "topic"
+			if topic, err := client.GetTopic(*t.TopicID); err == nil {
+				scope = topic.Name
+			}
+		}
+		names := make([]string, 0, len(t.Fields))
+		for _, f := range t.Fields {
+			names = append(names, f.Name)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.Name, scope, strings.Join(names, ", "), t.Description)
+	}
+	return w.Flush()
+}
+
+func runTemplateShow(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	scope, err := templateScope(client)
+	if err != nil {
+		return err
+	}
+
+	t, err := client.GetTemplate(args[0], scope)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printOutput(t)
+	}
+
+	fmt.Printf("Template: %s\n", t.Name)
+	if t.Description != "" {
+		fmt.Printf("Description: %s\n", t.Description)
+	}
+	fmt.Printf("Created by: %s\n", t.CreatedBy)
+	fmt.Println("\nFields:")
+	for _, f := range t.Fields {
+		typ := f.Type
+		if f.Type == models.FieldEnum {
+			typ += " (" + strings.Join(f.Options, "|") + ")"
+		}
+		req := ""
+		if f.Required {
+			req = " required"
+		}
+		fmt.Printf("  %s: %s%s", f.Name, typ, req)
+		if f.Default != "" {
+			fmt.Printf(" [default %s]", f.Default)
+		}
+		if f.Description != "" {
+			fmt.Printf(" — %s", f.Description)
+		}
+		fmt.Println()
+	}
+	if t.Body != "" {
+		fmt.Printf("\nBody:\n%s\n", t.Body)
+	}
+	return nil
+}
+
+// templateDefinition is the YAML/JSON file format for 'bbs template create --file'.
+type templateDefinition struct {
+	Description string                 `yaml:"description"`
+	Fields      []models.TemplateField `yaml:"fields"`
+	Body        string                 `yaml:"body"`
+}
+
+func runTemplateCreate(cmd *cobra.Command, args []string) error {
+	var def templateDefinition
+	if templateFile != "" {
+		if len(templateFieldSpecs) > 0 {
+			return usageError{errors.New("give fields with --field or --file, not both")}
+		}
+		data, err := os.ReadFile(templateFile)
+		if err != nil {
+			return fmt.Errorf("read template file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &def); err != nil {
+			return fmt.Errorf("parse template file: %w", err)
+		}
+	}
+	for _, spec := range templateFieldSpecs {
+		f, err := models.ParseFieldSpec(spec)
+		if err != nil {
+			return err
+		}
+		def.Fields = append(def.Fields, f)
+	}
+	if templateDescription != "" {
+		def.Description = templateDescription
+	}
+	if templateBody != "" {
+		def.Body = templateBody
+	}
+
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	scope, err := templateScope(client)
+	if err != nil {
+		return err
+	}
+
+	id := resolveIdentity("cli")
+	t := models.NewTemplate(args[0], def.Description, def.Fields, def.Body, id)
+	t.TopicID = scope
+
+	if err := client.CreateTemplate(t); err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printOutput(t)
+	}
+
+	color.Green("Created template: %s", t.Name)
+	return nil
+}
+
+func runTemplateDelete(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	scope, err := templateScope(client)
+	if err != nil {
+		return err
+	}
+
+	t, err := client.GetTemplate(args[0], scope)
+	if err != nil {
+		return err
+	}
+	if scope != nil && t.TopicID == nil {
+		return &charm.NotFoundError{Kind: "topic template", Key: args[0]}
+	}
+
+	id := resolveIdentity("cli")
+	if err := client.DeleteTemplate(t.ID, id); err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printOutput(t)
+	}
+
+	color.Yellow("Deleted template: %s", t.Name)
+	return nil
+}
+
+func runTemplatePosts(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	filters, err := parseFieldValues(templateFilters)
+	if err != nil {
+		return err
+	}
+
+	scope, err := templateScope(client)
+	if err != nil {
+		return err
+	}
+
+	messages, err := client.ListTemplatePosts(args[0], filters, scope)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printOutput(messages)
+	}
+
+	if len(messages) == 0 {
+		fmt.Println("No posts found.")
+		return nil
+	}
+
+	faint := color.New(color.Faint)
+	for _, msg := range messages {
+		faint.Printf("%s · %s · message %s · thread %s\n", msg.CreatedBy, msg.CreatedAt.Format("Jan 02 15:04"),
+			msg.ID.String()[:8], msg.ThreadID.String()[:8])
+		pairs := make([]string, 0, len(msg.Fields))
+		for k, v := range msg.Fields {
+			if !strings.Contains(v, "\n") {
+				pairs = append(pairs, k+"="+v)
+			}
+		}
+		if len(pairs) > 0 {
+			slices.Sort(pairs)
+			fmt.Println(strings.Join(pairs, "  "))
+		}
+		fmt.Println(models.Excerpt(msg.Content))
+		fmt.Println()
+	}
+	return nil
+}
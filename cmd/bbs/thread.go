@@ -1,18 +1,18 @@
 // ABOUTME: Thread CLI commands
-// ABOUTME: Implements thread list, new, show, sticky subcommands
+// ABOUTME: Implements thread list, new, show, sticky, lock, retitle, move, reindex subcommands
 
 package main
 
 import (
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/harper/bbs/internal/charm"
-	"github.com/harper/bbs/internal/identity"
 	"github.com/harper/bbs/internal/models"
 )
 
@@ -30,10 +30,14 @@ var threadListCmd = &cobra.Command{
 }
 
 var threadNewCmd = &cobra.Command{
-	Use:   "new <topic> <subject>",
+	Use:   "new <topic> <subject> [message]",
 	Short: "Create a new thread",
-	Args:  cobra.ExactArgs(2),
-	RunE:  runThreadNew,
+	Long: `Create a new thread, optionally with an initial message.
+
+The message can be given as an argument, read from a file with --file,
+or read from stdin with "-". Use --edit to write it in $EDITOR.`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: runThreadNew,
 }
 
 var threadShowCmd = &cobra.Command{
@@ -50,17 +54,62 @@ var threadStickyCmd = &cobra.Command{
 	RunE:  runThreadSticky,
 }
 
-var unsticky bool
+var threadLockCmd = &cobra.Command{
+	Use:   "lock <thread>",
+	Short: "Lock/unlock a thread (moderators only)",
+	Long: `Lock a thread so no new messages can be posted to it.
+
+Only moderators can lock or unlock threads, and moderators can still
+post to locked threads. Moderators are configured with 'bbs moderator add'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runThreadLock,
+}
+
+var threadRetitleCmd = &cobra.Command{
+	Use:   "retitle <thread> <subject>",
+	Short: "Change a thread's subject",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runThreadRetitle,
+}
+
+var threadMoveCmd = &cobra.Command{
+	Use:   "move <thread> <topic>",
+	Short: "Move a thread to another topic",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runThreadMove,
+}
+
+var threadReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Recompute thread activity, reply counts and participants",
+	Long: `Recompute every thread's last activity, message count, last poster and
+participants from its messages.
+
+Run this once after upgrading so threads created before these fields
+existed sort and display correctly.`,
+	Args: cobra.NoArgs,
+	RunE: runThreadReindex,
+}
+
+var (
+	unsticky      bool
+	unlock        bool
+	threadNewFile string
+	threadNewEdit bool
+)
 
 func init() {
 	rootCmd.AddCommand(threadCmd)
-	threadCmd.AddCommand(threadListCmd, threadNewCmd, threadShowCmd, threadStickyCmd)
+	threadCmd.AddCommand(threadListCmd, threadNewCmd, threadShowCmd, threadStickyCmd, threadLockCmd, threadRetitleCmd, threadMoveCmd, threadReindexCmd)
 
 	threadStickyCmd.Flags().BoolVar(&unsticky, "unpin", false, "unpin instead of pin")
+	threadLockCmd.Flags().BoolVar(&unlock, "unlock", false, "unlock instead of lock")
+	threadNewCmd.Flags().StringVarP(&threadNewFile, "file", "f", "", `read the initial message from a file ("-" for stdin)`)
+	threadNewCmd.Flags().BoolVarP(&threadNewEdit, "edit", "e", false, "write the initial message in $EDITOR")
 }
 
 func runThreadList(cmd *cobra.Command, args []string) error {
-	client, err := charm.Global()
+	client, err := openClient()
 	if err != nil {
 		return err
 	}
@@ -75,25 +124,37 @@ func runThreadList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if structuredOutput() {
+		return printOutput(threads)
+	}
+
 	if len(threads) == 0 {
 		fmt.Println("No threads found.")
 		return nil
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "SUBJECT\tCREATED BY\tDATE")
+	fmt.Fprintln(w, "SUBJECT\tPOSTS\tPEOPLE\tLAST POSTER\tLAST ACTIVITY\tCREATED BY")
 	for _, t := range threads {
 		prefix := ""
 		if t.Sticky {
 			prefix = "📌 "
 		}
-		fmt.Fprintf(w, "%s%s\t%s\t%s\n", prefix, t.Subject, t.CreatedBy, t.CreatedAt.Format("Jan 02"))
+		if t.Locked {
+			prefix += "🔒 "
+		}
+		lastPoster := t.LastPoster
+		if lastPoster == "" {
+			lastPoster = "-"
+		}
+		fmt.Fprintf(w, "%s%s\t%d\t%d\t%s\t%s\t%s\n", prefix, t.Subject, t.MessageCount, len(t.Participants),
+			lastPoster, t.LastActive().Format("Jan 02 15:04"), t.CreatedBy)
 	}
 	return w.Flush()
 }
 
 func runThreadNew(cmd *cobra.Command, args []string) error {
-	client, err := charm.Global()
+	client, err := openClient()
 	if err != nil {
 		return err
 	}
@@ -103,49 +164,92 @@ func runThreadNew(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	id := identity.GetIdentity(identityFlag, "cli")
+	// Read the body before creating anything so an aborted editor leaves no empty thread.
+	content, err := readBody(bodyArg(args, 2, threadNewFile), threadNewEdit, "", "New thread: "+args[1])
+	if err != nil {
+		return err
+	}
+
+	id := resolveIdentity("cli")
 	thread := models.NewThread(topic.ID, args[1], id)
+	var post *models.Message
+	if strings.TrimSpace(content) != "" {
+		post = models.NewMessage(thread.ID, content, id)
+	}
 
-	if err := client.CreateThread(thread); err != nil {
+	if err := client.CreateThreadWithPost(thread, post); err != nil {
 		return fmt.Errorf("failed to create thread: %w", err)
 	}
 
+	if structuredOutput() {
+		return printThread(client, thread.ID)
+	}
+
 	color.Green("Created thread: %s", args[1])
 	fmt.Printf("ID: %s\n", thread.ID.String()[:8])
 	return nil
 }
 
 func runThreadShow(cmd *cobra.Command, args []string) error {
-	client, err := charm.Global()
+	client, err := openClient()
 	if err != nil {
 		return err
 	}
 
 	thread, err := client.ResolveThread(args[0])
 	if err != nil {
-		return fmt.Errorf("thread not found: %s", args[0])
+		return err
+	}
+
+	if structuredOutput() {
+		return printThreadDetail(client, thread)
 	}
 
 	if thread.Sticky {
 		fmt.Print("📌 ")
 	}
+	if thread.Locked {
+		fmt.Print("🔒 ")
+	}
 	fmt.Printf("%s\n", thread.Subject)
 	faint := color.New(color.Faint)
-	faint.Printf("by %s on %s\n\n", thread.CreatedBy, thread.CreatedAt.Format("2006-01-02 15:04"))
+	faint.Printf("by %s on %s\n", thread.CreatedBy, thread.CreatedAt.Format("2006-01-02 15:04"))
+	if thread.UpdatedAt != nil {
+		faint.Printf("updated by %s on %s\n", thread.UpdatedBy, thread.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+	if len(thread.Participants) > 0 {
+		faint.Printf("%d posts from %s\n", thread.MessageCount, strings.Join(thread.Participants, ", "))
+	}
+	fmt.Println()
 
 	messages, err := client.ListMessages(thread.ID)
 	if err != nil {
 		return err
 	}
 
+	ids := make([]models.UUID, 0, len(messages))
+	for _, msg := range messages {
+		ids = append(ids, msg.ID)
+	}
+	reactions, err := client.ListReactionsForMessages(ids)
+	if err != nil {
+		return err
+	}
+
 	for _, msg := range messages {
 		fmt.Printf("─────────────────────────────────\n")
-		faint.Printf("%s · %s", msg.CreatedBy, msg.CreatedAt.Format("Jan 02 15:04"))
+		faint.Printf("%s · %s · %s", msg.CreatedBy, msg.CreatedAt.Format("Jan 02 15:04"), msg.ID.String()[:8])
+		if msg.Template != "" {
+			faint.Printf(" · %s", msg.Template)
+		}
 		if msg.EditedAt != nil {
 			faint.Printf(" (edited)")
 		}
 		fmt.Println()
 		fmt.Println(msg.Content)
+		if counts := models.SummarizeReactions(reactions[msg.ID]); len(counts) > 0 {
+			faint.Println(formatReactionCounts(counts))
+		}
 		fmt.Println()
 	}
 
@@ -157,7 +261,7 @@ func runThreadShow(cmd *cobra.Command, args []string) error {
 }
 
 func runThreadSticky(cmd *cobra.Command, args []string) error {
-	client, err := charm.Global()
+	client, err := openClient()
 	if err != nil {
 		return err
 	}
@@ -172,6 +276,10 @@ func runThreadSticky(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if structuredOutput() {
+		return printThread(client, thread.ID)
+	}
+
 	if sticky {
 		color.Green("📌 Pinned thread")
 	} else {
@@ -179,3 +287,147 @@ func runThreadSticky(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+func runThreadLock(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	thread, err := client.ResolveThread(args[0])
+	if err != nil {
+		return err
+	}
+
+	locked := !unlock
+	id := moderatorIdentity() // --as cannot claim moderator rights
+	if err := client.SetThreadLocked(thread.ID, locked, id); err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printThread(client, thread.ID)
+	}
+
+	if locked {
+		color.Yellow("🔒 Locked thread")
+	} else {
+		color.Green("Unlocked thread")
+	}
+	return nil
+}
+
+func runThreadRetitle(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	thread, err := client.ResolveThread(args[0])
+	if err != nil {
+		return err
+	}
+
+	id := resolveIdentity("cli")
+	if err := client.RetitleThread(thread.ID, args[1], id); err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printThread(client, thread.ID)
+	}
+
+	color.Green("Retitled thread: %s → %s", thread.Subject, args[1])
+	return nil
+}
+
+func runThreadMove(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	thread, err := client.ResolveThread(args[0])
+	if err != nil {
+		return err
+	}
+
+	topic, err := client.ResolveTopic(args[1])
+	if err != nil {
+		return err
+	}
+
+	id := resolveIdentity("cli")
+	if err := client.MoveThread(thread.ID, topic.ID, id); err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printThread(client, thread.ID)
+	}
+
+	color.Green("Moved thread %q to topic: %s", thread.Subject, topic.Name)
+	return nil
+}
+
+func runThreadReindex(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	changed, err := client.ReindexThreads()
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printOutput(map[string]int{"updated": changed})
+	}
+
+	color.Green("Reindexed threads: %d updated", changed)
+	return nil
+}
+
+// threadDetail is the structured output of 'bbs thread show'.
+type threadDetail struct {
+	*models.Thread
+	Messages []messageDetail
+}
+
+// messageDetail is a message with its reaction counts.
+type messageDetail struct {
+	*models.Message
+	Reactions []models.ReactionCount
+}
+
+func printThreadDetail(client *charm.Client, thread *models.Thread) error {
+	messages, err := client.ListMessages(thread.ID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]models.UUID, 0, len(messages))
+	for _, msg := range messages {
+		ids = append(ids, msg.ID)
+	}
+	reactions, err := client.ListReactionsForMessages(ids)
+	if err != nil {
+		return err
+	}
+
+	detail := threadDetail{Thread: thread, Messages: make([]messageDetail, 0, len(messages))}
+	for _, msg := range messages {
+		detail.Messages = append(detail.Messages, messageDetail{Message: msg, Reactions: models.SummarizeReactions(reactions[msg.ID])})
+	}
+	return printOutput(detail)
+}
+
+// printThread re-reads a thread after a change and prints it in the structured format.
+func printThread(client *charm.Client, id models.UUID) error {
+	thread, err := client.GetThread(id)
+	if err != nil {
+		return err
+	}
+	return printOutput(thread)
+}
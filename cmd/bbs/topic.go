@@ -1,5 +1,5 @@
 // ABOUTME: Topic CLI commands
-// ABOUTME: Implements topic list, new, archive, show subcommands
+// ABOUTME: Implements topic list, new, archive, readonly, show, rename, describe, migrate subcommands
 
 package main
 
@@ -12,7 +12,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/harper/bbs/internal/charm"
-	"github.com/harper/bbs/internal/identity"
 	"github.com/harper/bbs/internal/models"
 )
 
@@ -42,6 +41,17 @@ var topicArchiveCmd = &cobra.Command{
 	RunE:  runTopicArchive,
 }
 
+var topicReadOnlyCmd = &cobra.Command{
+	Use:   "readonly <topic>",
+	Short: "Make a topic read-only (moderators only)",
+	Long: `Make a topic read-only so no new threads or messages can be posted.
+
+Only moderators can change this, and moderators can still post to
+read-only topics. Moderators are configured with 'bbs moderator add'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTopicReadOnly,
+}
+
 var topicShowCmd = &cobra.Command{
 	Use:   "show <topic>",
 	Short: "Show topic details",
@@ -49,21 +59,53 @@ var topicShowCmd = &cobra.Command{
 	RunE:  runTopicShow,
 }
 
+var topicRenameCmd = &cobra.Command{
+	Use:   "rename <topic> <new-name>",
+	Short: "Rename a topic",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runTopicRename,
+}
+
+var topicDescribeCmd = &cobra.Command{
+	Use:   "describe <topic> <description>",
+	Short: "Change a topic's description",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runTopicDescribe,
+}
+
+var topicMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Backfill topic slugs and rename duplicate topics",
+	Long: `Check topics created by older versions of bbs.
+
+Topics without a slug get one, and topics whose names collide
+(case-insensitively) are reported. The oldest topic keeps its name;
+the others are renamed "<name> 2", "<name> 3", and so on.
+
+Nothing is written unless --apply is given.`,
+	Args: cobra.NoArgs,
+	RunE: runTopicMigrate,
+}
+
 var (
 	showArchived bool
 	unarchive    bool
+	writable     bool
+	applyMigrate bool
 )
 
 func init() {
 	rootCmd.AddCommand(topicCmd)
-	topicCmd.AddCommand(topicListCmd, topicNewCmd, topicArchiveCmd, topicShowCmd)
+	topicCmd.AddCommand(topicListCmd, topicNewCmd, topicArchiveCmd, topicReadOnlyCmd, topicShowCmd, topicRenameCmd, topicDescribeCmd, topicMigrateCmd)
 
 	topicListCmd.Flags().BoolVar(&showArchived, "archived", false, "show archived topics")
 	topicArchiveCmd.Flags().BoolVar(&unarchive, "unarchive", false, "unarchive instead of archive")
+	topicReadOnlyCmd.Flags().BoolVar(&writable, "off", false, "make the topic writable again")
+	topicMigrateCmd.Flags().BoolVar(&applyMigrate, "apply", false, "write slugs and renames instead of only reporting them")
 }
 
 func runTopicList(cmd *cobra.Command, args []string) error {
-	client, err := charm.Global()
+	client, err := openClient()
 	if err != nil {
 		return err
 	}
@@ -73,21 +115,29 @@ func runTopicList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if structuredOutput() {
+		return printOutput(topics)
+	}
+
 	if len(topics) == 0 {
 		fmt.Println("No topics found.")
 		return nil
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "NAME\tDESCRIPTION\tCREATED BY")
+	fmt.Fprintln(w, "NAME\tSLUG\tDESCRIPTION\tCREATED BY")
 	for _, t := range topics {
-		fmt.Fprintf(w, "%s\t%s\t%s\n", t.Name, t.Description, t.CreatedBy)
+		name := t.Name
+		if t.ReadOnly {
+			name = "🔒 " + name
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, t.Slug, t.Description, t.CreatedBy)
 	}
 	return w.Flush()
 }
 
 func runTopicNew(cmd *cobra.Command, args []string) error {
-	client, err := charm.Global()
+	client, err := openClient()
 	if err != nil {
 		return err
 	}
@@ -98,20 +148,25 @@ func runTopicNew(cmd *cobra.Command, args []string) error {
 		description = args[1]
 	}
 
-	id := identity.GetIdentity(identityFlag, "cli")
+	id := resolveIdentity("cli")
 	topic := models.NewTopic(name, description, id)
 
 	if err := client.CreateTopic(topic); err != nil {
 		return fmt.Errorf("failed to create topic: %w", err)
 	}
 
+	if structuredOutput() {
+		return printOutput(topic)
+	}
+
 	color.Green("Created topic: %s", name)
 	fmt.Printf("ID: %s\n", topic.ID.String()[:8])
+	fmt.Printf("Slug: %s\n", topic.Slug)
 	return nil
 }
 
 func runTopicArchive(cmd *cobra.Command, args []string) error {
-	client, err := charm.Global()
+	client, err := openClient()
 	if err != nil {
 		return err
 	}
@@ -126,6 +181,10 @@ func runTopicArchive(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if structuredOutput() {
+		return printTopic(client, topic.ID)
+	}
+
 	if archived {
 		color.Yellow("Archived topic: %s", args[0])
 	} else {
@@ -134,24 +193,68 @@ func runTopicArchive(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runTopicReadOnly(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	topic, err := client.ResolveTopic(args[0])
+	if err != nil {
+		return err
+	}
+
+	readOnly := !writable
+	id := moderatorIdentity() // --as cannot claim moderator rights
+	if err := client.SetTopicReadOnly(topic.ID, readOnly, id); err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printTopic(client, topic.ID)
+	}
+
+	if readOnly {
+		color.Yellow("🔒 Topic is now read-only: %s", topic.Name)
+	} else {
+		color.Green("Topic is writable again: %s", topic.Name)
+	}
+	return nil
+}
+
 func runTopicShow(cmd *cobra.Command, args []string) error {
-	client, err := charm.Global()
+	client, err := openClient()
 	if err != nil {
 		return err
 	}
 
 	topic, err := client.ResolveTopic(args[0])
 	if err != nil {
-		return fmt.Errorf("topic not found: %s", args[0])
+		return err
+	}
+
+	if structuredOutput() {
+		threads, err := client.ListThreads(topic.ID)
+		if err != nil {
+			return err
+		}
+		return printOutput(topicDetail{Topic: topic, Threads: threads})
 	}
 
 	fmt.Printf("Topic: %s\n", topic.Name)
+	fmt.Printf("Slug: %s\n", topic.Slug)
 	fmt.Printf("Description: %s\n", topic.Description)
 	fmt.Printf("Created by: %s\n", topic.CreatedBy)
 	fmt.Printf("Created at: %s\n", topic.CreatedAt.Format("2006-01-02 15:04"))
+	if topic.UpdatedAt != nil {
+		fmt.Printf("Updated by: %s on %s\n", topic.UpdatedBy, topic.UpdatedAt.Format("2006-01-02 15:04"))
+	}
 	if topic.Archived {
 		color.Yellow("Status: Archived")
 	}
+	if topic.ReadOnly {
+		color.Yellow("Status: Read-only")
+	}
 
 	// Show recent threads
 	threads, _ := client.ListThreads(topic.ID)
@@ -166,9 +269,127 @@ func runTopicShow(cmd *cobra.Command, args []string) error {
 			if t.Sticky {
 				prefix = "📌"
 			}
+			if t.Locked {
+				prefix = "🔒"
+			}
 			fmt.Printf("%s %s (%s)\n", prefix, t.Subject, t.CreatedBy)
 		}
 	}
 
 	return nil
 }
+
+func runTopicRename(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	topic, err := client.ResolveTopic(args[0])
+	if err != nil {
+		return err
+	}
+
+	id := resolveIdentity("cli")
+	if err := client.RenameTopic(topic.ID, args[1], id); err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printTopic(client, topic.ID)
+	}
+
+	color.Green("Renamed topic: %s → %s", topic.Name, args[1])
+	return nil
+}
+
+func runTopicDescribe(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	topic, err := client.ResolveTopic(args[0])
+	if err != nil {
+		return err
+	}
+
+	id := resolveIdentity("cli")
+	if err := client.DescribeTopic(topic.ID, args[1], id); err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printTopic(client, topic.ID)
+	}
+
+	color.Green("Updated description of topic: %s", topic.Name)
+	return nil
+}
+
+func runTopicMigrate(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	result, err := client.MigrateTopics(applyMigrate)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printOutput(result)
+	}
+
+	if result.SlugsBackfilled == 0 && len(result.Duplicates) == 0 {
+		color.Green("Topics are up to date")
+		return nil
+	}
+
+	verb := "Would backfill"
+	if applyMigrate {
+		verb = "Backfilled"
+	}
+	if result.SlugsBackfilled > 0 {
+		fmt.Printf("%s slugs for %d topic(s)\n", verb, result.SlugsBackfilled)
+	}
+
+	for _, group := range result.Duplicates {
+		color.Yellow("\nDuplicate topics:")
+		for _, t := range group {
+			fmt.Printf("  %s  %s (%s, %s)\n", t.ID.String()[:8], t.Name, t.CreatedBy, t.CreatedAt.Format("2006-01-02"))
+		}
+	}
+
+	if len(result.Renames) > 0 {
+		fmt.Println()
+		for _, r := range result.Renames {
+			if applyMigrate {
+				color.Green("Renamed %s: %q → %q", r.ID.String()[:8], r.From, r.To)
+			} else {
+				fmt.Printf("Would rename %s: %q → %q\n", r.ID.String()[:8], r.From, r.To)
+			}
+		}
+	}
+
+	if !applyMigrate {
+		fmt.Println("\nRun 'bbs topic migrate --apply' to make these changes.")
+	}
+	return nil
+}
+
+// topicDetail is the structured output of 'bbs topic show'.
+type topicDetail struct {
+	*models.Topic
+	Threads []*models.Thread
+}
+
+// printTopic re-reads a topic after a change and prints it in the structured format.
+func printTopic(client *charm.Client, id models.UUID) error {
+	topic, err := client.GetTopic(id)
+	if err != nil {
+		return err
+	}
+	return printOutput(topic)
+}
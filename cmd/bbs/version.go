@@ -12,10 +12,14 @@ import (
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if structuredOutput() {
+			return printOutput(struct{ Version, Commit, Date string }{version, commit, date})
+		}
 		fmt.Printf("bbs %s\n", version)
 		fmt.Printf("  commit: %s\n", commit)
 		fmt.Printf("  built:  %s\n", date)
+		return nil
 	},
 }
 
@@ -0,0 +1,198 @@
+// ABOUTME: Watch, unwatch and feed CLI commands
+// ABOUTME: Subscribes to topics and threads and shows activity on them
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/models"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [topic|thread]",
+	Short: "Watch a topic or thread, or list what you watch",
+	Long: `Watch a topic or thread so its activity shows up in 'bbs feed'.
+
+The target is tried as a topic first and then as a thread ID prefix;
+use --topic or --thread to force one. With no argument, lists your
+watch list.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runWatch,
+}
+
+var unwatchCmd = &cobra.Command{
+	Use:   "unwatch <topic|thread>",
+	Short: "Stop watching a topic or thread",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUnwatch,
+}
+
+var feedCmd = &cobra.Command{
+	Use:   "feed",
+	Short: "Show activity on watched topics and threads",
+	Long: `Show new threads and posts on the topics and threads you watch.
+
+By default the feed shows everything since you last ran 'bbs feed' and
+then moves your cursor forward. --since shows a fixed window (a duration
+like 2h, a date, or an RFC 3339 time) and --peek leaves the cursor alone.`,
+	Args: cobra.NoArgs,
+	RunE: runFeed,
+}
+
+var (
+	watchTopic  bool
+	watchThread bool
+	feedSince   string
+	feedPeek    bool
+)
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(unwatchCmd)
+	rootCmd.AddCommand(feedCmd)
+
+	watchCmd.Flags().BoolVar(&watchTopic, "topic", false, "treat the target as a topic")
+	watchCmd.Flags().BoolVar(&watchThread, "thread", false, "treat the target as a thread")
+	watchCmd.MarkFlagsMutuallyExclusive("topic", "thread")
+
+	feedCmd.Flags().StringVar(&feedSince, "since", "", "show activity since this time instead of the last check")
+	feedCmd.Flags().BoolVar(&feedPeek, "peek", false, "do not mark the feed as checked")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	id := resolveIdentity("cli")
+	if len(args) == 0 {
+		return listWatches(client, id)
+	}
+
+	kind := ""
+	if watchTopic {
+		kind = models.WatchTopic
+	} else if watchThread {
+		kind = models.WatchThread
+	}
+	kind, targetID, name, err := client.ResolveWatchTarget(args[0], kind)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Subscribe(id, kind, targetID); err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return listWatches(client, id)
+	}
+
+	color.Green("Watching %s: %s", kind, name)
+	return nil
+}
+
+func listWatches(client *charm.Client, id string) error {
+	subs, err := client.ListSubscriptions(id)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printOutput(subs)
+	}
+
+	if len(subs) == 0 {
+		fmt.Println("Not watching anything. Use 'bbs watch <topic|thread>' to start.")
+		return nil
+	}
+
+	for _, sub := range subs {
+		name := "(deleted)"
+		switch sub.Kind {
+		case models.WatchTopic:
+			if topic, err := client.GetTopic(sub.TargetID); err == nil {
+				name = topic.Name
+			}
+		case models.WatchThread:
+			if thread, err := client.GetThread(sub.TargetID); err == nil {
+				name = thread.Subject
+			}
+		}
+		fmt.Printf("%-6s  %s  %s\n", sub.Kind, sub.TargetID.String()[:8], name)
+	}
+	return nil
+}
+
+func runUnwatch(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	kind, targetID, name, err := client.ResolveWatchTarget(args[0], "")
+	if err != nil {
+		return err
+	}
+
+	id := resolveIdentity("cli")
+	if err := client.Unsubscribe(id, targetID); err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return listWatches(client, id)
+	}
+
+	color.Yellow("Stopped watching %s: %s", kind, name)
+	return nil
+}
+
+func runFeed(cmd *cobra.Command, args []string) error {
+	client, err := openClient()
+	if err != nil {
+		return err
+	}
+
+	var since *time.Time
+	if feedSince != "" {
+		t, err := charm.ParseSince(feedSince, time.Now())
+		if err != nil {
+			return err
+		}
+		since = &t
+	}
+
+	id := resolveIdentity("cli")
+	checkedAt := time.Now()
+	feed, err := client.GetFeed(id, since)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case structuredOutput():
+		if err := printOutput(feed); err != nil {
+			return err
+		}
+	case feed.Subscriptions == 0:
+		fmt.Println("Not watching anything. Use 'bbs watch <topic|thread>' to start.")
+		return nil
+	case len(feed.Items) == 0:
+		fmt.Println("Nothing new.")
+	default:
+		printActivity(feed.Items)
+	}
+
+	if !feedPeek && since == nil {
+		return client.MarkFeedChecked(id, checkedAt)
+	}
+	return nil
+}
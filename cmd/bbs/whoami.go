@@ -6,8 +6,6 @@ package main
 import (
 	"fmt"
 
-	"github.com/harper/bbs/internal/charm"
-	"github.com/harper/bbs/internal/identity"
 	"github.com/spf13/cobra"
 )
 
@@ -22,27 +20,42 @@ func init() {
 }
 
 func runWhoami(cmd *cobra.Command, args []string) error {
-	id := identity.GetIdentity(identityFlag, "cli")
-	fmt.Printf("Identity: %s\n", id)
-
-	client, err := charm.Global()
-	if err != nil {
-		fmt.Println("Sync: not initialized")
-		return nil
+	id := resolveIdentity("cli")
+	info := whoamiInfo{Identity: id, Board: settings.BoardName, Sync: "not initialized"}
+
+	if client, err := openClient(); err == nil {
+		if charmID, err := client.ID(); err != nil {
+			info.Sync = "not linked"
+		} else {
+			info.CharmID = charmID
+			info.Sync = "enabled"
+			info.Host = settings.CharmHost
+		}
 	}
 
-	charmID, err := client.ID()
-	if err != nil {
-		fmt.Println("Sync: not linked")
-	} else {
-		fmt.Printf("Charm ID: %s\n", charmID[:8])
-		cfg := client.Config()
-		host := cfg.CharmHost
-		if host == "" {
-			host = "charm.2389.dev"
-		}
-		fmt.Printf("Sync: enabled (host: %s)\n", host)
+	if structuredOutput() {
+		return printOutput(info)
 	}
 
+	fmt.Printf("Identity: %s\n", info.Identity)
+	if info.Board != "" {
+		fmt.Printf("Board: %s\n", info.Board)
+	}
+	switch info.Sync {
+	case "enabled":
+		fmt.Printf("Charm ID: %s\n", info.CharmID[:8])
+		fmt.Printf("Sync: enabled (host: %s)\n", info.Host)
+	default:
+		fmt.Printf("Sync: %s\n", info.Sync)
+	}
 	return nil
 }
+
+// whoamiInfo is the structured output of 'bbs whoami'.
+type whoamiInfo struct {
+	Identity string
+	Board    string
+	CharmID  string
+	Sync     string
+	Host     string
+}
@@ -0,0 +1,225 @@
+// ABOUTME: Activity feed built from threads and messages
+// ABOUTME: Merges thread creations and posts across topics in one scan
+
+package charm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+	"github.com/google/uuid"
+
+	"github.com/harper/bbs/internal/models"
+)
+
+// boardSnapshot holds every topic, thread and message read in one pass over the keys.
+type boardSnapshot struct {
+	topics   map[uuid.UUID]*models.Topic
+	threads  map[uuid.UUID]*models.Thread
+	messages []*models.Message
+}
+
+// readSnapshot loads topics, threads and messages from an open database.
+func readSnapshot(k *Tx) (*boardSnapshot, error) {
+	snap := &boardSnapshot{
+		topics:  make(map[uuid.UUID]*models.Topic),
+		threads: make(map[uuid.UUID]*models.Thread),
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		var target any
+		switch {
+		case bytes.HasPrefix(key, []byte(TopicPrefix)):
+			target = &models.Topic{}
+		case bytes.HasPrefix(key, []byte(ThreadPrefix)):
+			target = &models.Thread{}
+		case bytes.HasPrefix(key, []byte(MessagePrefix)):
+			target = &models.Message{}
+		default:
+			continue
+		}
+
+		data, err := k.Get(key)
+		if err != nil {
+			if errors.Is(err, kv.ErrMissingKey) {
+				continue // Key was deleted between Keys() and Get()
+			}
+			return nil, err
+		}
+		if err := json.Unmarshal(data, target); err != nil {
+			return nil, err
+		}
+
+		switch v := target.(type) {
+		case *models.Topic:
+			v.EnsureSlug()
+			snap.topics[v.ID] = v
+		case *models.Thread:
+			snap.threads[v.ID] = v
+		case *models.Message:
+			snap.messages = append(snap.messages, v)
+		}
+	}
+	return snap, nil
+}
+
+// activity returns thread creations, posts and edits after since, oldest first,
+// for threads accepted by include (nil accepts every thread).
+func (snap *boardSnapshot) activity(since time.Time, include func(*models.Thread) bool) []*models.Activity {
+	var items []*models.Activity
+
+	// newItem fills in the thread's and topic's names and moderation state.
+	newItem := func(kind string, at time.Time, actor string, thread *models.Thread) *models.Activity {
+		item := &models.Activity{
+			Kind:     kind,
+			At:       at,
+			Actor:    actor,
+			TopicID:  thread.TopicID,
+			ThreadID: thread.ID,
+			Subject:  thread.Subject,
+			Locked:   thread.Locked,
+		}
+		if t, ok := snap.topics[thread.TopicID]; ok {
+			item.Topic = t.Name
+			item.ReadOnly = t.ReadOnly
+		}
+		return item
+	}
+
+	for _, thread := range snap.threads {
+		if include != nil && !include(thread) {
+			continue
+		}
+		if thread.CreatedAt.After(since) {
+			items = append(items, newItem(models.ActivityThreadCreated, thread.CreatedAt, thread.CreatedBy, thread))
+		}
+	}
+
+	for _, msg := range snap.messages {
+		thread, ok := snap.threads[msg.ThreadID]
+		if !ok || (include != nil && !include(thread)) {
+			continue
+		}
+		post := func(kind string, at time.Time) *models.Activity {
+			item := newItem(kind, at, msg.CreatedBy, thread)
+			item.MessageID = msg.ID
+			item.Excerpt = models.Excerpt(msg.Content)
+			return item
+		}
+		if msg.CreatedAt.After(since) {
+			items = append(items, post(models.ActivityMessagePosted, msg.CreatedAt))
+		}
+		if msg.EditedAt != nil && msg.EditedAt.After(since) {
+			items = append(items, post(models.ActivityMessageEdited, *msg.EditedAt))
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].At.Before(items[j].At)
+	})
+	return items
+}
+
+// RecentActivity returns thread creations, posts and edits after since
+// across all non-archived topics, newest first. A limit of 0 or less
+// returns everything.
+func (c *Client) RecentActivity(since time.Time, limit int) ([]*models.Activity, error) {
+	var items []*models.Activity
+	err := c.DoReadOnly(func(k *Tx) error {
+		snap, err := readSnapshot(k)
+		if err != nil {
+			return err
+		}
+		items = snap.activity(since, func(t *models.Thread) bool {
+			topic, ok := snap.topics[t.TopicID]
+			return ok && !topic.Archived
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newestFirst(items, limit), nil
+}
+
+// PostsBy returns messages written by author after since, newest first.
+// A limit of 0 or less returns everything.
+func (c *Client) PostsBy(author string, since time.Time, limit int) ([]*models.Message, error) {
+	var posts []*models.Message
+	err := c.DoReadOnly(func(k *Tx) error {
+		snap, err := readSnapshot(k)
+		if err != nil {
+			return err
+		}
+		posts = snap.postsBy(author, since)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(posts) > limit {
+		posts = posts[:limit]
+	}
+	return posts, nil
+}
+
+// postsBy returns the author's messages after since in threads that still
+// exist, newest first.
+func (snap *boardSnapshot) postsBy(author string, since time.Time) []*models.Message {
+	var posts []*models.Message
+	for _, msg := range snap.messages {
+		if _, ok := snap.threads[msg.ThreadID]; !ok {
+			continue
+		}
+		if msg.CreatedBy == author && msg.CreatedAt.After(since) {
+			posts = append(posts, msg)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].CreatedAt.After(posts[j].CreatedAt)
+	})
+	return posts
+}
+
+// newestFirst reverses an oldest-first activity list and trims it to limit.
+func newestFirst(items []*models.Activity, limit int) []*models.Activity {
+	slices.Reverse(items)
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// ParseSince accepts an RFC 3339 timestamp, a date (2006-01-02), or a
+// duration such as "2h", "30m" or "7d" meaning that long before now.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	return time.Time{}, newError(ErrInvalidArgument, fmt.Sprintf("invalid time %q: use a duration like 2h, a date, or RFC 3339", s))
+}
@@ -0,0 +1,313 @@
+// ABOUTME: Batches of thread and message operations run in one database session
+// ABOUTME: Later operations can refer to records created earlier with $N references
+
+package charm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/harper/bbs/internal/models"
+)
+
+// Batch operation names.
+const (
+	BatchCreateThread = "create_thread"
+	BatchPostMessage  = "post_message"
+	BatchSticky       = "sticky"
+	BatchArchive      = "archive"
+	BatchEdit         = "edit"
+)
+
+// BatchOp is one operation in a batch. Only the fields the operation uses
+// are read.
+//
+// Topic, Thread and Message take the same IDs, prefixes and names as the
+// single-record commands, or a reference "$N" to the record produced by
+// operation N (counting from 0) earlier in the batch.
+type BatchOp struct {
+	Op       string
+	Topic    string // create_thread, archive
+	Thread   string // post_message, sticky
+	Message  string // edit
+	Subject  string // create_thread
+	Content  string // create_thread (optional opening post), post_message, edit
+	Sticky   bool   // sticky
+	Archived bool   // archive
+}
+
+// BatchResult is the record one operation created or changed. Thread is
+// set for create_thread, post_message and sticky; Message for post_message,
+// edit and a create_thread with an opening post; Topic for archive.
+type BatchResult struct {
+	Op      string
+	Topic   *models.Topic
+	Thread  *models.Thread
+	Message *models.Message
+}
+
+// Batch runs ops in order in a single Do call, so the database is opened
+// and synced once. Every target is resolved and every permission checked
+// before anything is written; a batch that fails those checks changes
+// nothing.
+func (c *Client) Batch(ops []BatchOp, by string) ([]BatchResult, error) {
+	targets := make([]uuid.UUID, len(ops))
+	for i, op := range ops {
+		id, err := c.resolveBatchTarget(ops, i)
+		if err != nil {
+			return nil, batchError(i, op, err)
+		}
+		targets[i] = id
+	}
+
+	var results []BatchResult
+	err := c.Do(func(k *Tx) error {
+		for i, op := range ops {
+			if err := c.checkBatchOp(k, op, targets[i], by); err != nil {
+				return batchError(i, op, err)
+			}
+		}
+		results = make([]BatchResult, 0, len(ops))
+		for i, op := range ops {
+			id := targets[i]
+			if id == uuid.Nil {
+				n, _ := batchRef(batchTarget(op))
+				id = batchRefID(results[n], batchTargetKind(op.Op))
+			}
+			res, err := c.applyBatchOp(k, op, id, by)
+			if err != nil {
+				return batchError(i, op, err)
+			}
+			results = append(results, res)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// batchError prefixes err with the operation that failed.
+func batchError(i int, op BatchOp, err error) error {
+	return fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+}
+
+// batchRef parses a "$N" reference to an earlier operation.
+func batchRef(s string) (int, bool) {
+	rest, ok := strings.CutPrefix(s, "$")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// batchTargetKind names the kind of record an operation acts on.
+func batchTargetKind(op string) string {
+	switch op {
+	case BatchCreateThread, BatchArchive:
+		return "topic"
+	case BatchPostMessage, BatchSticky:
+		return "thread"
+	case BatchEdit:
+		return "message"
+	}
+	return ""
+}
+
+// batchTarget returns the field holding an operation's target.
+func batchTarget(op BatchOp) string {
+	switch batchTargetKind(op.Op) {
+	case "topic":
+		return op.Topic
+	case "thread":
+		return op.Thread
+	case "message":
+		return op.Message
+	}
+	return ""
+}
+
+// batchYields reports whether op produces a record of the given kind that
+// later operations can refer to.
+func batchYields(op BatchOp, kind string) bool {
+	switch kind {
+	case "topic":
+		return op.Op == BatchArchive
+	case "thread":
+		return op.Op == BatchCreateThread || op.Op == BatchPostMessage || op.Op == BatchSticky
+	case "message":
+		return op.Op == BatchPostMessage || op.Op == BatchEdit || (op.Op == BatchCreateThread && op.Content != "")
+	}
+	return false
+}
+
+// batchRefID returns the ID of the record of the given kind in res.
+func batchRefID(res BatchResult, kind string) uuid.UUID {
+	switch kind {
+	case "topic":
+		return res.Topic.ID
+	case "thread":
+		return res.Thread.ID
+	default:
+		return res.Message.ID
+	}
+}
+
+// validateBatchOp checks that operation i names a known operation, has the
+// fields it needs and refers only to earlier operations that produce the
+// right kind of record.
+func validateBatchOp(ops []BatchOp, i int) error {
+	op := ops[i]
+	kind := batchTargetKind(op.Op)
+	if kind == "" {
+		return newError(ErrInvalidArgument, "unknown operation")
+	}
+	target := batchTarget(op)
+	if target == "" {
+		return newError(ErrInvalidArgument, kind+" is required")
+	}
+	switch op.Op {
+	case BatchCreateThread:
+		if op.Subject == "" {
+			return newError(ErrInvalidArgument, "subject is required")
+		}
+	case BatchPostMessage, BatchEdit:
+		if op.Content == "" {
+			return newError(ErrInvalidArgument, "content is required")
+		}
+	}
+	if strings.HasPrefix(target, "$") {
+		n, ok := batchRef(target)
+		if !ok || n >= i {
+			return newError(ErrInvalidArgument, fmt.Sprintf("%s must refer to an earlier operation", target))
+		}
+		if !batchYields(ops[n], kind) {
+			return newError(ErrInvalidArgument, fmt.Sprintf("operation %d (%s) has no %s for %s", n, ops[n].Op, kind, target))
+		}
+	}
+	return nil
+}
+
+// resolveBatchTarget resolves the target of operation i to an ID. It
+// returns uuid.Nil for references, which are filled in as the batch runs.
+func (c *Client) resolveBatchTarget(ops []BatchOp, i int) (uuid.UUID, error) {
+	if err := validateBatchOp(ops, i); err != nil {
+		return uuid.Nil, err
+	}
+	op := ops[i]
+	target := batchTarget(op)
+	if strings.HasPrefix(target, "$") {
+		return uuid.Nil, nil
+	}
+	switch batchTargetKind(op.Op) {
+	case "topic":
+		topic, err := c.ResolveTopic(target)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return topic.ID, nil
+	case "thread":
+		thread, err := c.ResolveThread(target)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return thread.ID, nil
+	default:
+		msg, err := c.ResolveMessage(target)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return msg.ID, nil
+	}
+}
+
+// checkBatchOp verifies that by may apply op to an existing record.
+// Records created earlier in the batch are new threads in topics that
+// already passed these checks, so references are not checked again.
+func (c *Client) checkBatchOp(k *Tx, op BatchOp, id uuid.UUID, by string) error {
+	if id == uuid.Nil {
+		return nil
+	}
+	switch op.Op {
+	case BatchCreateThread:
+		topic, err := getTopicTx(k, id)
+		if err != nil {
+			return err
+		}
+		if topic.ReadOnly && !c.IsModerator(by) {
+			return fmt.Errorf("%w: %s", ErrTopicReadOnly, topic.Name)
+		}
+	case BatchPostMessage:
+		return c.checkCanPost(k, id, by)
+	}
+	return nil
+}
+
+// applyBatchOp writes one operation and returns the records it touched.
+func (c *Client) applyBatchOp(k *Tx, op BatchOp, id uuid.UUID, by string) (BatchResult, error) {
+	res := BatchResult{Op: op.Op}
+	switch op.Op {
+	case BatchCreateThread:
+		thread := models.NewThread(id, op.Subject, by)
+		res.Thread = thread
+		if op.Content != "" {
+			res.Message = models.NewMessage(thread.ID, op.Content, by)
+			thread.RecordPost(res.Message)
+			if err := putMessage(k, res.Message); err != nil {
+				return res, err
+			}
+		}
+		return res, putThread(k, thread)
+
+	case BatchPostMessage:
+		thread, err := getThreadTx(k, id)
+		if err != nil {
+			return res, err
+		}
+		res.Message = models.NewMessage(id, op.Content, by)
+		if err := putMessage(k, res.Message); err != nil {
+			return res, err
+		}
+		thread.RecordPost(res.Message)
+		res.Thread = thread
+		return res, putThread(k, thread)
+
+	case BatchSticky:
+		thread, err := getThreadTx(k, id)
+		if err != nil {
+			return res, err
+		}
+		thread.Sticky = op.Sticky
+		res.Thread = thread
+		return res, putThread(k, thread)
+
+	case BatchArchive:
+		topic, err := getTopicTx(k, id)
+		if err != nil {
+			return res, err
+		}
+		topic.Archived = op.Archived
+		res.Topic = topic
+		return res, putTopic(k, topic)
+
+	default: // BatchEdit
+		msg, err := getMessageTx(k, id)
+		if err != nil {
+			return res, err
+		}
+		msg.Content = op.Content
+		now := time.Now()
+		msg.EditedAt = &now
+		res.Message = msg
+		return res, putMessage(k, msg)
+	}
+}
@@ -8,10 +8,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/charm/client"
+	"github.com/charmbracelet/charm/fs"
 	"github.com/charmbracelet/charm/kv"
 	"github.com/google/uuid"
 
@@ -29,13 +31,20 @@ const (
 // DBName is the name of the BBS key-value store
 const DBName = "bbs"
 
+// ErrTopicExists is returned when a topic name or slug is already taken.
+var ErrTopicExists = newError(ErrConflict, "topic already exists")
+
 // Client holds configuration for KV operations.
 // Unlike the previous implementation, it does NOT hold a persistent connection.
 // Each operation opens the database, performs the operation, and closes it.
 type Client struct {
 	dbName         string
+	charmHost      string
 	autoSync       bool
 	staleThreshold time.Duration
+	moderators     []string
+	dataDir        string        // overrides the charm data path, see WithDataDir
+	kick           chan struct{} // wakes the background syncer
 }
 
 // Option configures a Client.
@@ -48,6 +57,25 @@ func WithDBName(name string) Option {
 	}
 }
 
+// WithCharmHost sets the Charm server to sync with. Without it the charm
+// library's own setting applies: CHARM_HOST, or its default. The library
+// still fetches encryption keys from the CHARM_HOST server, so the two
+// must agree.
+func WithCharmHost(host string) Option {
+	return func(c *Client) {
+		c.charmHost = host
+	}
+}
+
+// WithDataDir keeps the database and its sync state under dir instead of
+// the charm data path and the XDG state dir, so one machine can act as
+// several devices of the same account.
+func WithDataDir(dir string) Option {
+	return func(c *Client) {
+		c.dataDir = dir
+	}
+}
+
 // WithAutoSync enables or disables auto-sync after writes.
 func WithAutoSync(enabled bool) Option {
 	return func(c *Client) {
@@ -55,24 +83,27 @@ func WithAutoSync(enabled bool) Option {
 	}
 }
 
-// NewClient creates a new client with the given options.
-func NewClient(opts ...Option) (*Client, error) {
-	cfg, err := LoadConfig()
-	if err != nil {
-		return nil, err
+// WithStaleThreshold sets how old local data may get before a read syncs
+// first.
+func WithStaleThreshold(d time.Duration) Option {
+	return func(c *Client) {
+		c.staleThreshold = d
 	}
+}
 
-	// Set charm host if configured
-	if cfg.CharmHost != "" {
-		if err := os.Setenv("CHARM_HOST", cfg.CharmHost); err != nil {
-			return nil, err
-		}
+// WithModerators sets the usernames allowed to moderate.
+func WithModerators(usernames []string) Option {
+	return func(c *Client) {
+		c.moderators = usernames
 	}
+}
 
+// NewClient creates a new client with the given options.
+func NewClient(opts ...Option) (*Client, error) {
 	c := &Client{
 		dbName:         DBName,
-		autoSync:       cfg.AutoSync,
-		staleThreshold: cfg.StaleThreshold,
+		autoSync:       true,
+		staleThreshold: kv.DefaultStaleThreshold,
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -80,49 +111,126 @@ func NewClient(opts ...Option) (*Client, error) {
 	return c, nil
 }
 
+// DBName returns the name of the client's board database.
+func (c *Client) DBName() string {
+	return c.dbName
+}
+
+// kvOptions returns the options for opening the client's database.
+func (c *Client) kvOptions() []kv.Option {
+	if c.dataDir == "" {
+		return nil
+	}
+	return []kv.Option{kv.WithPath(c.dataDir)}
+}
+
+// CharmConfig returns the charm library settings for the client's host,
+// taking the rest from the charm environment variables.
+func (c *Client) CharmConfig() (*client.Config, error) {
+	cfg, err := client.ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if c.charmHost != "" {
+		cfg.Host = c.charmHost
+	}
+	return cfg, nil
+}
+
+// CharmClient returns a new charm client for auth operations.
+func (c *Client) CharmClient() (*client.Client, error) {
+	cfg, err := c.CharmConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.NewClient(cfg)
+}
+
+// DataPath returns the directory holding the client's kv databases.
+func (c *Client) DataPath() (string, error) {
+	if c.dataDir != "" {
+		return c.dataDir, nil
+	}
+	cc, err := c.CharmClient()
+	if err != nil {
+		return "", err
+	}
+	return cc.DataPath()
+}
+
+// withKV opens the client's database, runs fn and closes it, like kv.Do
+// but against the client's host.
+func (c *Client) withKV(readOnly bool, fn func(k *kv.KV) error) (err error) {
+	cc, err := c.CharmClient()
+	if err != nil {
+		return err
+	}
+	open := kv.Open
+	if readOnly {
+		open = kv.OpenReadOnly
+	}
+	k, err := open(cc, c.dbName, c.kvOptions()...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := k.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+	return fn(k)
+}
+
+// stateDir returns the directory for the client's sync state files.
+func (c *Client) stateDir() string {
+	if c.dataDir == "" {
+		return StateDir()
+	}
+	return filepath.Join(c.dataDir, "state")
+}
+
 // DoReadOnly executes a function with read-only database access.
 // Use this for batch read operations that need multiple Gets.
-// Automatically syncs if data is stale before executing the read operation.
-func (c *Client) DoReadOnly(fn func(k *kv.KV) error) error {
-	// Sync if stale before reading
-	if err := c.SyncIfStale(); err != nil {
-		return fmt.Errorf("stale sync: %w", err)
-	}
-	return kv.DoReadOnly(c.dbName, fn)
+// Syncs first if data is stale; a failed sync is recorded in the sync
+// state and the read goes ahead with local data.
+func (c *Client) DoReadOnly(fn func(k *Tx) error) error {
+	_ = c.SyncIfStale()
+	return c.withKV(true, func(k *kv.KV) error {
+		return fn(&Tx{KV: k})
+	})
 }
 
 // Do executes a function with write access to the database.
-// Use this for batch write operations.
-func (c *Client) Do(fn func(k *kv.KV) error) error {
-	return kv.Do(c.dbName, func(k *kv.KV) error {
-		if err := fn(k); err != nil {
+// Use this for batch write operations. Opening the database needs the
+// Charm server, since the charm library fetches the encryption keys then,
+// and closing it backs up what was written. Writes are also queued in the
+// outbox; with auto-sync on, the background syncer pushes any the backup
+// missed.
+func (c *Client) Do(fn func(k *Tx) error) error {
+	var writes []OutboxEntry
+	err := c.withKV(false, func(k *kv.KV) error {
+		tx := &Tx{KV: k}
+		if err := fn(tx); err != nil {
 			return err
 		}
-		if c.autoSync {
-			return k.Sync()
-		}
+		writes = tx.writes
 		return nil
 	})
-}
-
-// --- Legacy compatibility layer ---
-// These functions maintain backwards compatibility with existing code.
-
-// InitGlobal is a no-op for backwards compatibility.
-// With Do API, connections are automatically managed.
-func InitGlobal() error {
-	// Set CHARM_HOST if not already set
-	if os.Getenv("CHARM_HOST") == "" {
-		os.Setenv("CHARM_HOST", "charm.2389.dev")
+	if err != nil || len(writes) == 0 {
+		return err
+	}
+	if err := c.updateSyncState(func(st *SyncState) error {
+		st.queue(writes)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("queue writes: %w", err)
+	}
+	if c.autoSync {
+		c.kickSyncer()
 	}
 	return nil
 }
 
-// Global returns a new client instance.
-func Global() (*Client, error) {
-	return NewClient()
-}
-
 // Close is a no-op for backwards compatibility.
 // With Do API, connections are automatically closed after each operation.
 func (c *Client) Close() error {
@@ -137,29 +245,17 @@ func (c *Client) IsLinked() bool {
 
 // ID returns the current user's Charm ID.
 func (c *Client) ID() (string, error) {
-	cc, err := client.NewClientWithDefaults()
+	cc, err := c.CharmClient()
 	if err != nil {
 		return "", err
 	}
 	return cc.ID()
 }
 
-// CharmClient returns a new charm client for auth operations.
-func (c *Client) CharmClient() (*client.Client, error) {
-	return client.NewClientWithDefaults()
-}
-
-// Sync triggers a manual sync with the charm server.
-func (c *Client) Sync() error {
-	return kv.Do(c.dbName, func(k *kv.KV) error {
-		return k.Sync()
-	})
-}
-
 // LastSyncTime returns the time of the last successful sync.
 func (c *Client) LastSyncTime() (time.Time, error) {
 	var lastSync time.Time
-	err := kv.DoReadOnly(c.dbName, func(k *kv.KV) error {
+	err := c.withKV(true, func(k *kv.KV) error {
 		lastSync = k.LastSyncTime()
 		return nil
 	})
@@ -169,7 +265,7 @@ func (c *Client) LastSyncTime() (time.Time, error) {
 // IsStale returns true if the database hasn't been synced within the stale threshold.
 func (c *Client) IsStale() (bool, error) {
 	var isStale bool
-	err := kv.DoReadOnly(c.dbName, func(k *kv.KV) error {
+	err := c.withKV(true, func(k *kv.KV) error {
 		isStale = k.IsStale(c.staleThreshold)
 		return nil
 	})
@@ -177,8 +273,17 @@ func (c *Client) IsStale() (bool, error) {
 }
 
 // SyncIfStale syncs the database if it hasn't been synced within the stale threshold.
-// Returns nil if not stale or if sync succeeds.
+// After a failed sync it waits out the retry backoff rather than blocking
+// every read on an unreachable server.
+// Returns nil if not stale, while backing off, or if sync succeeds.
 func (c *Client) SyncIfStale() error {
+	st, err := c.SyncState()
+	if err != nil {
+		return err
+	}
+	if time.Now().Before(st.NextAttempt) {
+		return nil
+	}
 	stale, err := c.IsStale()
 	if err != nil {
 		return err
@@ -189,17 +294,57 @@ func (c *Client) SyncIfStale() error {
 	return c.Sync()
 }
 
-// Reset clears all data (nuclear option).
+// Reset deletes the local database and pulls a fresh copy from the
+// server, discarding writes that were never synced.
 func (c *Client) Reset() error {
-	return kv.Do(c.dbName, func(k *kv.KV) error {
+	return c.withKV(false, func(k *kv.KV) error {
 		return k.Reset()
 	})
 }
 
-// Config returns the current configuration.
-func (c *Client) Config() *Config {
-	cfg, _ := LoadConfig()
-	return cfg
+// Repair checkpoints, checks and vacuums the local database; with force it
+// also attempts recovery when the integrity check fails.
+func (c *Client) Repair(force bool) (*kv.RepairResult, error) {
+	dataDir, err := c.DataPath()
+	if err != nil {
+		return nil, err
+	}
+	return kv.Repair(c.dbName, force, kv.WithPath(dataDir))
+}
+
+// Wipe permanently deletes the database's backups on the server and its
+// local files.
+func (c *Client) Wipe() (*kv.WipeResult, error) {
+	result := &kv.WipeResult{}
+	cc, err := c.CharmClient()
+	if err != nil {
+		return result, err
+	}
+	cfs, err := fs.NewFSWithClient(cc)
+	if err != nil {
+		return result, err
+	}
+	if entries, err := cfs.ReadDir(c.dbName); err == nil {
+		for _, e := range entries {
+			key := c.dbName + "/" + e.Name()
+			if err := cfs.Remove(key); err != nil {
+				result.Error = fmt.Errorf("delete cloud backup %s: %w", key, err)
+			} else {
+				result.CloudBackupsDeleted++
+			}
+		}
+	}
+
+	dataDir, err := c.DataPath()
+	if err != nil {
+		return result, err
+	}
+	local, err := kv.Wipe(c.dbName, kv.WithPath(dataDir))
+	if err != nil {
+		return result, err
+	}
+	result.LocalFilesDeleted = local.LocalFilesDeleted
+	return result, nil
 }
 
 // key helpers
@@ -220,41 +365,180 @@ func attachmentKey(id uuid.UUID) []byte {
 	return []byte(AttachmentPrefix + id.String())
 }
 
-// Topic CRUD
+// transaction helpers operate on an already-open database so that a
+// read-modify-write happens inside a single Do call.
 
-// CreateTopic stores a new topic.
-func (c *Client) CreateTopic(t *models.Topic) error {
+func getTopicTx(k *Tx, id uuid.UUID) (*models.Topic, error) {
+	data, err := k.Get(topicKey(id))
+	if err != nil {
+		if errors.Is(err, kv.ErrMissingKey) {
+			return nil, notFound("topic", id)
+		}
+		return nil, err
+	}
+	var topic models.Topic
+	if err := json.Unmarshal(data, &topic); err != nil {
+		return nil, err
+	}
+	topic.EnsureSlug()
+	return &topic, nil
+}
+
+// nextVersion returns the version for a write of key: one past both the
+// caller's copy and the stored record, so a version never goes backwards.
+func nextVersion(k *Tx, key []byte, v uint64) uint64 {
+	if data, err := k.Get(key); err == nil {
+		v = max(v, recordVersion(data))
+	}
+	return v + 1
+}
+
+// recordVersion reads the Version of a stored topic, thread or message.
+func recordVersion(data []byte) uint64 {
+	var r struct{ Version uint64 }
+	if data != nil {
+		_ = json.Unmarshal(data, &r)
+	}
+	return r.Version
+}
+
+func putTopic(k *Tx, t *models.Topic) error {
+	t.Version = nextVersion(k, topicKey(t.ID), t.Version)
 	data, err := json.Marshal(t)
 	if err != nil {
 		return fmt.Errorf("marshal topic: %w", err)
 	}
-	return c.Do(func(k *kv.KV) error {
-		return k.Set(topicKey(t.ID), data)
-	})
+	return k.Set(topicKey(t.ID), data)
 }
 
-// GetTopic retrieves a topic by ID.
-func (c *Client) GetTopic(id uuid.UUID) (*models.Topic, error) {
-	var topic models.Topic
-	err := c.DoReadOnly(func(k *kv.KV) error {
-		data, err := k.Get(topicKey(id))
+func getThreadTx(k *Tx, id uuid.UUID) (*models.Thread, error) {
+	data, err := k.Get(threadKey(id))
+	if err != nil {
+		if errors.Is(err, kv.ErrMissingKey) {
+			return nil, notFound("thread", id)
+		}
+		return nil, err
+	}
+	var thread models.Thread
+	if err := json.Unmarshal(data, &thread); err != nil {
+		return nil, err
+	}
+	return &thread, nil
+}
+
+func getMessageTx(k *Tx, id uuid.UUID) (*models.Message, error) {
+	data, err := k.Get(messageKey(id))
+	if err != nil {
+		if errors.Is(err, kv.ErrMissingKey) {
+			return nil, notFound("message", id)
+		}
+		return nil, err
+	}
+	var msg models.Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func putThread(k *Tx, t *models.Thread) error {
+	t.Version = nextVersion(k, threadKey(t.ID), t.Version)
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("marshal thread: %w", err)
+	}
+	return k.Set(threadKey(t.ID), data)
+}
+
+// putMessage stores a message and delivers its @mentions.
+func putMessage(k *Tx, m *models.Message) error {
+	m.Version = nextVersion(k, messageKey(m.ID), m.Version)
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+	if err := k.Set(messageKey(m.ID), data); err != nil {
+		return err
+	}
+	return recordMentions(k, m)
+}
+
+// Topic CRUD
+
+// CreateTopic stores a new topic.
+// Names must be unique (case-insensitive) and must not collide with another topic's slug.
+func (c *Client) CreateTopic(t *models.Topic) error {
+	t.EnsureSlug()
+	return c.Do(func(k *Tx) error {
+		topics, err := readTopics(k)
 		if err != nil {
-			if errors.Is(err, kv.ErrMissingKey) {
-				return fmt.Errorf("topic not found: %s", id)
-			}
 			return err
 		}
-		return json.Unmarshal(data, &topic)
+		for _, other := range topics {
+			other.EnsureSlug()
+		}
+		if err := checkTopicUnique(topics, t); err != nil {
+			return err
+		}
+		return putTopic(k, t)
+	})
+}
+
+// checkTopicUnique returns ErrTopicExists if another topic uses the same name or slug.
+func checkTopicUnique(topics []*models.Topic, t *models.Topic) error {
+	for _, other := range topics {
+		if other.ID == t.ID {
+			continue
+		}
+		if strings.EqualFold(other.Name, t.Name) {
+			return fmt.Errorf("%w: name %q is used by topic %s", ErrTopicExists, other.Name, other.ID.String()[:8])
+		}
+		if other.Slug == t.Slug {
+			return fmt.Errorf("%w: slug %q is used by topic %q", ErrTopicExists, t.Slug, other.Name)
+		}
+	}
+	return nil
+}
+
+// GetTopic retrieves a topic by ID.
+func (c *Client) GetTopic(id uuid.UUID) (*models.Topic, error) {
+	var topic *models.Topic
+	err := c.DoReadOnly(func(k *Tx) error {
+		var err error
+		topic, err = getTopicTx(k, id)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &topic, nil
+	return topic, nil
 }
 
 // UpdateTopic updates an existing topic.
+// A changed name or slug must stay unique, as in CreateTopic; topics that
+// already clash can still be updated otherwise, so they can be archived.
 func (c *Client) UpdateTopic(t *models.Topic) error {
-	return c.CreateTopic(t) // Same operation - overwrite
+	t.EnsureSlug()
+	return c.Do(func(k *Tx) error {
+		stored, err := getTopicTx(k, t.ID)
+		if err != nil {
+			return err
+		}
+		stored.EnsureSlug()
+		if stored.Name != t.Name || stored.Slug != t.Slug {
+			topics, err := readTopics(k)
+			if err != nil {
+				return err
+			}
+			for _, other := range topics {
+				other.EnsureSlug()
+			}
+			if err := checkTopicUnique(topics, t); err != nil {
+				return err
+			}
+		}
+		return putTopic(k, t)
+	})
 }
 
 // DeleteTopic deletes a topic and all its threads (cascade).
@@ -269,7 +553,7 @@ func (c *Client) DeleteTopic(id uuid.UUID) error {
 			return fmt.Errorf("cascade delete thread %s: %w", thread.ID, err)
 		}
 	}
-	return c.Do(func(k *kv.KV) error {
+	return c.Do(func(k *Tx) error {
 		return k.Delete(topicKey(id))
 	})
 }
@@ -277,36 +561,16 @@ func (c *Client) DeleteTopic(id uuid.UUID) error {
 // ListTopics returns all topics, optionally including archived ones.
 func (c *Client) ListTopics(includeArchived bool) ([]*models.Topic, error) {
 	var topics []*models.Topic
-	prefix := []byte(TopicPrefix)
 
-	err := c.DoReadOnly(func(k *kv.KV) error {
-		// Get all keys from the database
-		keys, err := k.Keys()
+	err := c.DoReadOnly(func(k *Tx) error {
+		all, err := readTopics(k)
 		if err != nil {
 			return err
 		}
-
-		// Filter keys by prefix and unmarshal values
-		for _, key := range keys {
-			if !bytes.HasPrefix(key, prefix) {
-				continue
-			}
-
-			data, err := k.Get(key)
-			if err != nil {
-				if errors.Is(err, kv.ErrMissingKey) {
-					continue // Key was deleted between Keys() and Get()
-				}
-				return err
-			}
-
-			var topic models.Topic
-			if err := json.Unmarshal(data, &topic); err != nil {
-				return err
-			}
-
+		for _, topic := range all {
+			topic.EnsureSlug()
 			if includeArchived || !topic.Archived {
-				topics = append(topics, &topic)
+				topics = append(topics, topic)
 			}
 		}
 		return nil
@@ -315,7 +579,43 @@ func (c *Client) ListTopics(includeArchived bool) ([]*models.Topic, error) {
 	return topics, err
 }
 
+// readTopics loads every topic from an open database.
+// Slugs are left as stored so migrations can tell which topics still need one.
+func readTopics(k *Tx) ([]*models.Topic, error) {
+	var topics []*models.Topic
+	prefix := []byte(TopicPrefix)
+
+	// Get all keys from the database
+	keys, err := k.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter keys by prefix and unmarshal values
+	for _, key := range keys {
+		if !bytes.HasPrefix(key, prefix) {
+			continue
+		}
+
+		data, err := k.Get(key)
+		if err != nil {
+			if errors.Is(err, kv.ErrMissingKey) {
+				continue // Key was deleted between Keys() and Get()
+			}
+			return nil, err
+		}
+
+		var topic models.Topic
+		if err := json.Unmarshal(data, &topic); err != nil {
+			return nil, err
+		}
+		topics = append(topics, &topic)
+	}
+	return topics, nil
+}
+
 // GetTopicByName finds a topic by its name.
+// An exact match wins; otherwise names are compared case-insensitively.
 func (c *Client) GetTopicByName(name string) (*models.Topic, error) {
 	topics, err := c.ListTopics(true) // Include archived
 	if err != nil {
@@ -326,44 +626,78 @@ func (c *Client) GetTopicByName(name string) (*models.Topic, error) {
 			return t, nil
 		}
 	}
-	return nil, fmt.Errorf("topic not found: %s", name)
+	for _, t := range topics {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+	}
+	return nil, notFound("topic", name)
+}
+
+// GetTopicBySlug finds a topic by its URL-safe slug.
+func (c *Client) GetTopicBySlug(slug string) (*models.Topic, error) {
+	topics, err := c.ListTopics(true) // Include archived
+	if err != nil {
+		return nil, err
+	}
+	slug = strings.ToLower(slug)
+	for _, t := range topics {
+		if t.Slug == slug {
+			return t, nil
+		}
+	}
+	return nil, notFound("topic", slug)
 }
 
 // Thread CRUD
 
 // CreateThread stores a new thread.
+// Fails with ErrTopicReadOnly unless the topic accepts new threads from the creator.
 func (c *Client) CreateThread(t *models.Thread) error {
-	data, err := json.Marshal(t)
-	if err != nil {
-		return fmt.Errorf("marshal thread: %w", err)
-	}
-	return c.Do(func(k *kv.KV) error {
-		return k.Set(threadKey(t.ID), data)
-	})
+	return c.CreateThreadWithPost(t, nil)
 }
 
-// GetThread retrieves a thread by ID.
-func (c *Client) GetThread(id uuid.UUID) (*models.Thread, error) {
-	var thread models.Thread
-	err := c.DoReadOnly(func(k *kv.KV) error {
-		data, err := k.Get(threadKey(id))
+// CreateThreadWithPost stores a new thread and its opening post in one
+// transaction, so a post that fails leaves no empty thread behind. A nil
+// post creates the thread alone.
+func (c *Client) CreateThreadWithPost(t *models.Thread, post *models.Message) error {
+	return c.Do(func(k *Tx) error {
+		topic, err := getTopicTx(k, t.TopicID)
 		if err != nil {
-			if errors.Is(err, kv.ErrMissingKey) {
-				return fmt.Errorf("thread not found: %s", id)
-			}
 			return err
 		}
-		return json.Unmarshal(data, &thread)
+		if topic.ReadOnly && !c.IsModerator(t.CreatedBy) {
+			return fmt.Errorf("%w: %s", ErrTopicReadOnly, topic.Name)
+		}
+		if post != nil {
+			if err := putMessage(k, post); err != nil {
+				return err
+			}
+			t.RecordPost(post)
+		}
+		return putThread(k, t)
+	})
+}
+
+// GetThread retrieves a thread by ID.
+func (c *Client) GetThread(id uuid.UUID) (*models.Thread, error) {
+	var thread *models.Thread
+	err := c.DoReadOnly(func(k *Tx) error {
+		var err error
+		thread, err = getThreadTx(k, id)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &thread, nil
+	return thread, nil
 }
 
 // UpdateThread updates an existing thread.
 func (c *Client) UpdateThread(t *models.Thread) error {
-	return c.CreateThread(t)
+	return c.Do(func(k *Tx) error {
+		return putThread(k, t)
+	})
 }
 
 // DeleteThread deletes a thread and all its messages (cascade).
@@ -378,17 +712,17 @@ func (c *Client) DeleteThread(id uuid.UUID) error {
 			return fmt.Errorf("cascade delete message %s: %w", msg.ID, err)
 		}
 	}
-	return c.Do(func(k *kv.KV) error {
+	return c.Do(func(k *Tx) error {
 		return k.Delete(threadKey(id))
 	})
 }
 
-// ListThreads returns all threads for a topic.
+// ListThreads returns all threads for a topic, sticky first and then by last activity.
 func (c *Client) ListThreads(topicID uuid.UUID) ([]*models.Thread, error) {
 	var threads []*models.Thread
 	prefix := []byte(ThreadPrefix)
 
-	err := c.DoReadOnly(func(k *kv.KV) error {
+	err := c.DoReadOnly(func(k *Tx) error {
 		// Get all keys from the database
 		keys, err := k.Keys()
 		if err != nil {
@@ -421,30 +755,40 @@ func (c *Client) ListThreads(topicID uuid.UUID) ([]*models.Thread, error) {
 		return nil
 	})
 
+	models.SortThreadsByActivity(threads)
 	return threads, err
 }
 
 // Message CRUD
 
-// CreateMessage stores a new message.
+// CreateMessage stores a new message, updates the thread's activity fields
+// and delivers its @mentions.
+// Fails with ErrThreadLocked or ErrTopicReadOnly unless the author is a moderator.
 func (c *Client) CreateMessage(m *models.Message) error {
-	data, err := json.Marshal(m)
-	if err != nil {
-		return fmt.Errorf("marshal message: %w", err)
-	}
-	return c.Do(func(k *kv.KV) error {
-		return k.Set(messageKey(m.ID), data)
+	return c.Do(func(k *Tx) error {
+		if err := c.checkCanPost(k, m.ThreadID, m.CreatedBy); err != nil {
+			return err
+		}
+		if err := putMessage(k, m); err != nil {
+			return err
+		}
+		thread, err := getThreadTx(k, m.ThreadID)
+		if err != nil {
+			return err
+		}
+		thread.RecordPost(m)
+		return putThread(k, thread)
 	})
 }
 
 // GetMessage retrieves a message by ID.
 func (c *Client) GetMessage(id uuid.UUID) (*models.Message, error) {
 	var msg models.Message
-	err := c.DoReadOnly(func(k *kv.KV) error {
+	err := c.DoReadOnly(func(k *Tx) error {
 		data, err := k.Get(messageKey(id))
 		if err != nil {
 			if errors.Is(err, kv.ErrMissingKey) {
-				return fmt.Errorf("message not found: %s", id)
+				return notFound("message", id)
 			}
 			return err
 		}
@@ -457,11 +801,14 @@ func (c *Client) GetMessage(id uuid.UUID) (*models.Message, error) {
 }
 
 // UpdateMessage updates an existing message.
+// Newly added @mentions are delivered; existing inbox entries are kept.
 func (c *Client) UpdateMessage(m *models.Message) error {
-	return c.CreateMessage(m)
+	return c.Do(func(k *Tx) error {
+		return putMessage(k, m)
+	})
 }
 
-// DeleteMessage deletes a message and all its attachments (cascade).
+// DeleteMessage deletes a message and all its attachments, reactions and mentions (cascade).
 func (c *Client) DeleteMessage(id uuid.UUID) error {
 	// Cascade delete attachments first
 	attachments, err := c.ListAttachments(id)
@@ -473,8 +820,21 @@ func (c *Client) DeleteMessage(id uuid.UUID) error {
 			return fmt.Errorf("cascade delete attachment %s: %w", att.ID, err)
 		}
 	}
-	return c.Do(func(k *kv.KV) error {
-		return k.Delete(messageKey(id))
+	return c.Do(func(k *Tx) error {
+		msg, err := getMessageTx(k, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteReactions(k, id); err != nil {
+			return fmt.Errorf("delete reactions: %w", err)
+		}
+		if err := deleteMentions(k, id); err != nil {
+			return fmt.Errorf("delete mentions: %w", err)
+		}
+		if err := k.Delete(messageKey(id)); err != nil {
+			return err
+		}
+		return refreshThreadStats(k, msg.ThreadID)
 	})
 }
 
@@ -483,7 +843,7 @@ func (c *Client) ListMessages(threadID uuid.UUID) ([]*models.Message, error) {
 	var messages []*models.Message
 	prefix := []byte(MessagePrefix)
 
-	err := c.DoReadOnly(func(k *kv.KV) error {
+	err := c.DoReadOnly(func(k *Tx) error {
 		// Get all keys from the database
 		keys, err := k.Keys()
 		if err != nil {
@@ -527,7 +887,7 @@ func (c *Client) CreateAttachment(a *models.Attachment) error {
 	if err != nil {
 		return fmt.Errorf("marshal attachment: %w", err)
 	}
-	return c.Do(func(k *kv.KV) error {
+	return c.Do(func(k *Tx) error {
 		return k.Set(attachmentKey(a.ID), data)
 	})
 }
@@ -535,11 +895,11 @@ func (c *Client) CreateAttachment(a *models.Attachment) error {
 // GetAttachment retrieves an attachment by ID.
 func (c *Client) GetAttachment(id uuid.UUID) (*models.Attachment, error) {
 	var att models.Attachment
-	err := c.DoReadOnly(func(k *kv.KV) error {
+	err := c.DoReadOnly(func(k *Tx) error {
 		data, err := k.Get(attachmentKey(id))
 		if err != nil {
 			if errors.Is(err, kv.ErrMissingKey) {
-				return fmt.Errorf("attachment not found: %s", id)
+				return notFound("attachment", id)
 			}
 			return err
 		}
@@ -553,7 +913,7 @@ func (c *Client) GetAttachment(id uuid.UUID) (*models.Attachment, error) {
 
 // DeleteAttachment deletes an attachment.
 func (c *Client) DeleteAttachment(id uuid.UUID) error {
-	return c.Do(func(k *kv.KV) error {
+	return c.Do(func(k *Tx) error {
 		return k.Delete(attachmentKey(id))
 	})
 }
@@ -563,7 +923,7 @@ func (c *Client) ListAttachments(messageID uuid.UUID) ([]*models.Attachment, err
 	var attachments []*models.Attachment
 	prefix := []byte(AttachmentPrefix)
 
-	err := c.DoReadOnly(func(k *kv.KV) error {
+	err := c.DoReadOnly(func(k *Tx) error {
 		// Get all keys from the database
 		keys, err := k.Keys()
 		if err != nil {
@@ -4,10 +4,19 @@
 package charm
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/charm/kv"
+	"github.com/google/uuid"
+
+	"github.com/harper/bbs/internal/models"
 )
 
 func TestNewClient(t *testing.T) {
@@ -23,7 +32,7 @@ func TestNewClient(t *testing.T) {
 
 func TestKeyPrefixes(t *testing.T) {
 	// Verify key prefixes are defined correctly
-	prefixes := []string{TopicPrefix, ThreadPrefix, MessagePrefix, AttachmentPrefix}
+	prefixes := []string{TopicPrefix, ThreadPrefix, MessagePrefix, AttachmentPrefix, ReactionPrefix, MentionPrefix, SubscriptionPrefix, FeedCursorPrefix, TemplatePrefix}
 	for _, p := range prefixes {
 		if p == "" {
 			t.Error("Key prefix should not be empty")
@@ -32,13 +41,9 @@ func TestKeyPrefixes(t *testing.T) {
 }
 
 func TestWALConcurrentConnections(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping integration test in short mode")
-	}
 	// Test that multiple KV connections can open the same database concurrently.
 	// This verifies the WAL mode fix prevents SQLITE_BUSY errors.
-	tmpDir := t.TempDir()
-	t.Setenv("CHARM_DATA_DIR", tmpDir)
+	testServer(t)
 
 	// First, initialize the database with a single connection.
 	initKV, err := kv.OpenWithDefaults("bbs-wal-test")
@@ -90,3 +95,833 @@ func TestWALConcurrentConnections(t *testing.T) {
 		t.Errorf("concurrent connections produced %d errors, first: %v", len(errs), errs[0])
 	}
 }
+
+func TestCheckTopicUnique(t *testing.T) {
+	existing := models.NewTopic("General", "", "harper@cli")
+	topics := []*models.Topic{existing}
+
+	tests := []struct {
+		name    string
+		topic   string
+		wantErr bool
+	}{
+		{"same name", "General", true},
+		{"different case", "general", true},
+		{"same slug", "general!", true},
+		{"distinct", "random", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTopicUnique(topics, models.NewTopic(tt.topic, "", "harper@cli"))
+			if tt.wantErr && !errors.Is(err, ErrTopicExists) {
+				t.Errorf("expected ErrTopicExists, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+
+	// A topic never conflicts with itself
+	if err := checkTopicUnique(topics, existing); err != nil {
+		t.Errorf("topic should not conflict with itself: %v", err)
+	}
+}
+
+func TestDuplicateTopicGroups(t *testing.T) {
+	older := models.NewTopic("general", "", "harper@cli")
+	older.CreatedAt = time.Now().Add(-time.Hour)
+	newer := models.NewTopic("General", "", "claude@mcp")
+	other := models.NewTopic("random", "", "harper@cli")
+	taken := models.NewTopic("general 2", "", "harper@cli")
+
+	topics := []*models.Topic{newer, other, older, taken}
+	groups := duplicateTopicGroups(topics)
+	if len(groups) != 1 {
+		t.Fatalf("expected 1 duplicate group, got %d", len(groups))
+	}
+	if groups[0][0].ID != older.ID {
+		t.Error("expected oldest topic first in group")
+	}
+
+	renames := planTopicRenames(topics, groups)
+	if len(renames) != 1 {
+		t.Fatalf("expected 1 rename, got %d", len(renames))
+	}
+	if renames[0].ID != newer.ID || renames[0].To != "general 3" {
+		t.Errorf("expected newer topic renamed to 'general 3', got %+v", renames[0])
+	}
+}
+
+func TestRenameTopic(t *testing.T) {
+	testServer(t)
+	c := testDevice(t)
+
+	general := models.NewTopic("General", "", "harper@cli")
+	notes := models.NewTopic("Release Notes", "", "harper@cli")
+	for _, topic := range []*models.Topic{general, notes} {
+		if err := c.CreateTopic(topic); err != nil {
+			t.Fatalf("CreateTopic: %v", err)
+		}
+	}
+
+	if err := c.RenameTopic(general.ID, "release notes", "harper@cli"); !errors.Is(err, ErrTopicExists) {
+		t.Errorf("renaming onto a taken name = %v, want ErrTopicExists", err)
+	}
+	if err := c.RenameTopic(general.ID, "Release-Notes", "harper@cli"); !errors.Is(err, ErrTopicExists) {
+		t.Errorf("renaming onto a taken slug = %v, want ErrTopicExists", err)
+	}
+	if err := c.RenameTopic(general.ID, "  ", "harper@cli"); !errors.Is(err, ErrEmptyValue) {
+		t.Errorf("renaming to blank = %v, want ErrEmptyValue", err)
+	}
+
+	if err := c.RenameTopic(general.ID, "Lobby", "claude@mcp"); err != nil {
+		t.Fatalf("RenameTopic: %v", err)
+	}
+	got, err := c.GetTopic(general.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Lobby" || got.Slug != "lobby" {
+		t.Errorf("renamed topic = %q (%s), want Lobby (lobby)", got.Name, got.Slug)
+	}
+	if len(got.History) != 1 || got.History[0].From != "General" || got.History[0].ChangedBy != "claude@mcp" {
+		t.Errorf("history = %+v, want one Name change by claude@mcp", got.History)
+	}
+	if _, err := c.GetTopicBySlug("general"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("old slug should no longer resolve, got %v", err)
+	}
+}
+
+func TestUpdateTopicKeepsNamesUnique(t *testing.T) {
+	testServer(t)
+	c := testDevice(t)
+
+	general := models.NewTopic("General", "", "harper@cli")
+	random := models.NewTopic("Random", "", "harper@cli")
+	for _, topic := range []*models.Topic{general, random} {
+		if err := c.CreateTopic(topic); err != nil {
+			t.Fatalf("CreateTopic: %v", err)
+		}
+	}
+
+	clash := *random
+	clash.Name, clash.Slug = "general", ""
+	if err := c.UpdateTopic(&clash); !errors.Is(err, ErrTopicExists) {
+		t.Errorf("UpdateTopic to a taken name = %v, want ErrTopicExists", err)
+	}
+	if err := c.ArchiveTopic(random.ID, true); err != nil {
+		t.Errorf("ArchiveTopic: %v", err)
+	}
+}
+
+func TestMoveThread(t *testing.T) {
+	testServer(t)
+	c := testDevice(t, WithModerators([]string{"harper"}))
+
+	from := models.NewTopic("general", "", "harper@cli")
+	to := models.NewTopic("archive", "", "harper@cli")
+	locked := models.NewTopic("announcements", "", "harper@cli")
+	locked.ReadOnly = true
+	for _, topic := range []*models.Topic{from, to, locked} {
+		if err := c.CreateTopic(topic); err != nil {
+			t.Fatalf("CreateTopic: %v", err)
+		}
+	}
+	thread := models.NewThread(from.ID, "Deploy", "claude@mcp")
+	if err := c.CreateThread(thread); err != nil {
+		t.Fatalf("CreateThread: %v", err)
+	}
+	if err := c.CreateMessage(models.NewMessage(thread.ID, "shipping today", "claude@mcp")); err != nil {
+		t.Fatalf("CreateMessage: %v", err)
+	}
+
+	if err := c.MoveThread(thread.ID, uuid.New(), "claude@mcp"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("moving to a missing topic = %v, want ErrNotFound", err)
+	}
+	if err := c.MoveThread(thread.ID, locked.ID, "claude@mcp"); !errors.Is(err, ErrTopicReadOnly) {
+		t.Errorf("non-moderator moving into a read-only topic = %v, want ErrTopicReadOnly", err)
+	}
+
+	if err := c.MoveThread(thread.ID, to.ID, "claude@mcp"); err != nil {
+		t.Fatalf("MoveThread: %v", err)
+	}
+	threads, err := c.ListThreads(to.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(threads) != 1 || threads[0].ID != thread.ID {
+		t.Fatalf("destination threads = %v, want the moved thread", threads)
+	}
+	if h := threads[0].History; len(h) != 1 || h[0].Field != "TopicID" || h[0].From != from.ID.String() {
+		t.Errorf("history = %+v, want one TopicID change from %s", h, from.ID)
+	}
+	if messages, err := c.ListMessages(thread.ID); err != nil || len(messages) != 1 {
+		t.Errorf("moved thread has %d messages (%v), want 1", len(messages), err)
+	}
+
+	if err := c.MoveThread(thread.ID, locked.ID, "harper@cli"); err != nil {
+		t.Errorf("moderator moving into a read-only topic: %v", err)
+	}
+}
+
+func TestCreateThreadWithPost(t *testing.T) {
+	testServer(t)
+	c := testDevice(t)
+
+	topic := models.NewTopic("general", "", "harper@cli")
+	locked := models.NewTopic("announcements", "", "harper@cli")
+	locked.ReadOnly = true
+	for _, tp := range []*models.Topic{topic, locked} {
+		if err := c.CreateTopic(tp); err != nil {
+			t.Fatalf("CreateTopic: %v", err)
+		}
+	}
+
+	thread := models.NewThread(topic.ID, "Deploy", "claude@mcp")
+	post := models.NewMessage(thread.ID, "shipping today", "claude@mcp")
+	if err := c.CreateThreadWithPost(thread, post); err != nil {
+		t.Fatalf("CreateThreadWithPost: %v", err)
+	}
+	got, err := c.GetThread(thread.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.MessageCount != 1 || got.LastPoster != "claude@mcp" {
+		t.Errorf("thread stats = %d posts by %q, want 1 by claude@mcp", got.MessageCount, got.LastPoster)
+	}
+	if messages, err := c.ListMessages(thread.ID); err != nil || len(messages) != 1 {
+		t.Errorf("thread has %d messages (%v), want 1", len(messages), err)
+	}
+
+	rejected := models.NewThread(locked.ID, "Hello", "claude@mcp")
+	err = c.CreateThreadWithPost(rejected, models.NewMessage(rejected.ID, "hi", "claude@mcp"))
+	if !errors.Is(err, ErrTopicReadOnly) {
+		t.Fatalf("posting into a read-only topic = %v, want ErrTopicReadOnly", err)
+	}
+	if _, err := c.GetThread(rejected.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("rejected thread lookup = %v, want ErrNotFound", err)
+	}
+	if messages, err := c.ListMessages(rejected.ID); err != nil || len(messages) != 0 {
+		t.Errorf("rejected thread has %d messages (%v), want none", len(messages), err)
+	}
+}
+
+func TestRetitleThread(t *testing.T) {
+	testServer(t)
+	c := testDevice(t)
+
+	topic := models.NewTopic("general", "", "harper@cli")
+	if err := c.CreateTopic(topic); err != nil {
+		t.Fatalf("CreateTopic: %v", err)
+	}
+	thread := models.NewThread(topic.ID, "Deploy", "harper@cli")
+	if err := c.CreateThread(thread); err != nil {
+		t.Fatalf("CreateThread: %v", err)
+	}
+
+	if err := c.RetitleThread(thread.ID, "", "harper@cli"); !errors.Is(err, ErrEmptyValue) {
+		t.Errorf("blank subject = %v, want ErrEmptyValue", err)
+	}
+	for _, subject := range []string{"Deploy v2", "Deploy v2", "Deploy v3"} {
+		if err := c.RetitleThread(thread.ID, subject, "claude@mcp"); err != nil {
+			t.Fatalf("RetitleThread(%q): %v", subject, err)
+		}
+	}
+
+	got, err := c.GetThread(thread.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Subject != "Deploy v3" {
+		t.Errorf("subject = %q, want Deploy v3", got.Subject)
+	}
+	var changes []string
+	for _, ch := range got.History {
+		changes = append(changes, ch.From+" -> "+ch.To)
+	}
+	if want := "Deploy -> Deploy v2,Deploy v2 -> Deploy v3"; strings.Join(changes, ",") != want {
+		t.Errorf("history = %v, want %s (unchanged subjects are not recorded)", changes, want)
+	}
+}
+
+func TestIsModerator(t *testing.T) {
+	c, err := NewClient(WithModerators([]string{"harper", "Ops-Bot"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"harper@cli", true},
+		{"harper@mcp", true},
+		{"ops-bot@mcp", true},
+		{"claude@mcp", false},
+		{"harper", true},
+	}
+
+	for _, tt := range tests {
+		if got := c.IsModerator(tt.id); got != tt.want {
+			t.Errorf("IsModerator(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+
+	if c, _ := NewClient(); c.IsModerator("harper@cli") {
+		t.Error("expected no moderators when none are configured")
+	}
+}
+
+func TestReactionKeys(t *testing.T) {
+	msgID := uuid.New()
+	other := uuid.New()
+
+	key := reactionKey(msgID, "ack", "harper@cli")
+	if !hasAnyPrefix(key, [][]byte{messageReactionPrefix(msgID)}) {
+		t.Error("reaction key should start with its message prefix")
+	}
+	if hasAnyPrefix(key, [][]byte{messageReactionPrefix(other)}) {
+		t.Error("reaction key should not match another message's prefix")
+	}
+	if string(key) == string(reactionKey(msgID, "ack", "claude@mcp")) {
+		t.Error("reactions from different identities should use different keys")
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 12, 20, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2h", now.Add(-2 * time.Hour)},
+		{"30m", now.Add(-30 * time.Minute)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"2025-12-19", time.Date(2025, 12, 19, 0, 0, 0, 0, time.UTC)},
+		{"2025-12-19T08:30:00Z", time.Date(2025, 12, 19, 8, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.in, now)
+		if err != nil {
+			t.Errorf("ParseSince(%q) error: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := ParseSince("yesterday-ish", now); err == nil {
+		t.Error("expected error for unparseable time")
+	}
+}
+
+func TestSnapshotActivity(t *testing.T) {
+	base := time.Date(2025, 12, 20, 12, 0, 0, 0, time.UTC)
+
+	topic := &models.Topic{ID: uuid.New(), Name: "general"}
+	watched := &models.Thread{ID: uuid.New(), TopicID: topic.ID, Subject: "watched", CreatedAt: base}
+	other := &models.Thread{ID: uuid.New(), TopicID: topic.ID, Subject: "other", CreatedAt: base}
+	snap := &boardSnapshot{
+		topics:  map[uuid.UUID]*models.Topic{topic.ID: topic},
+		threads: map[uuid.UUID]*models.Thread{watched.ID: watched, other.ID: other},
+		messages: []*models.Message{
+			{ID: uuid.New(), ThreadID: watched.ID, Content: "second", CreatedAt: base.Add(2 * time.Minute)},
+			{ID: uuid.New(), ThreadID: watched.ID, Content: "first", CreatedAt: base.Add(time.Minute)},
+			{ID: uuid.New(), ThreadID: other.ID, Content: "elsewhere", CreatedAt: base.Add(time.Minute)},
+		},
+	}
+
+	items := snap.activity(base, func(th *models.Thread) bool { return th.ID == watched.ID })
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2 (thread creation is not after since)", len(items))
+	}
+	if items[0].Excerpt != "first" || items[1].Excerpt != "second" {
+		t.Errorf("items not oldest first: %q, %q", items[0].Excerpt, items[1].Excerpt)
+	}
+	if items[0].Topic != "general" || items[0].Subject != "watched" {
+		t.Errorf("activity context = %q/%q, want general/watched", items[0].Topic, items[0].Subject)
+	}
+
+	all := snap.activity(base.Add(-time.Second), nil)
+	if len(all) != 5 {
+		t.Errorf("got %d items with no filter, want 5", len(all))
+	}
+
+	topic.ReadOnly, watched.Locked = true, true
+	for _, item := range snap.activity(base.Add(-time.Second), nil) {
+		if !item.ReadOnly || item.Locked != (item.ThreadID == watched.ID) {
+			t.Errorf("%s in %q: locked=%v read-only=%v, want the thread's and topic's state", item.Kind, item.Subject, item.Locked, item.ReadOnly)
+		}
+	}
+	topic.ReadOnly, watched.Locked = false, false
+
+	edited := base.Add(time.Hour)
+	snap.messages[0].EditedAt = &edited
+	recent := newestFirst(snap.activity(base.Add(30*time.Minute), nil), 0)
+	if len(recent) != 1 || recent[0].Kind != models.ActivityMessageEdited {
+		t.Errorf("expected only the edit after the cutoff, got %d items", len(recent))
+	}
+
+	limited := newestFirst(snap.activity(base.Add(-time.Second), nil), 2)
+	if len(limited) != 2 || !limited[0].At.Equal(edited) {
+		t.Errorf("newestFirst should keep the 2 newest entries, got %d", len(limited))
+	}
+}
+
+func TestSnapshotPostsBy(t *testing.T) {
+	base := time.Date(2025, 12, 20, 12, 0, 0, 0, time.UTC)
+	thread := &models.Thread{ID: uuid.New(), Subject: "deploy"}
+	snap := &boardSnapshot{
+		threads: map[uuid.UUID]*models.Thread{thread.ID: thread},
+		messages: []*models.Message{
+			{ID: uuid.New(), ThreadID: thread.ID, Content: "older", CreatedBy: "bot@mcp", CreatedAt: base.Add(time.Minute)},
+			{ID: uuid.New(), ThreadID: thread.ID, Content: "newer", CreatedBy: "bot@mcp", CreatedAt: base.Add(2 * time.Minute)},
+			{ID: uuid.New(), ThreadID: thread.ID, Content: "someone else", CreatedBy: "harper@cli", CreatedAt: base.Add(time.Minute)},
+			{ID: uuid.New(), ThreadID: thread.ID, Content: "too old", CreatedBy: "bot@mcp", CreatedAt: base.Add(-time.Minute)},
+			{ID: uuid.New(), ThreadID: uuid.New(), Content: "orphan", CreatedBy: "bot@mcp", CreatedAt: base.Add(time.Minute)},
+		},
+	}
+
+	posts := snap.postsBy("bot@mcp", base)
+	if len(posts) != 2 {
+		t.Fatalf("got %d posts, want 2", len(posts))
+	}
+	if posts[0].Content != "newer" || posts[1].Content != "older" {
+		t.Errorf("posts not newest first: %q, %q", posts[0].Content, posts[1].Content)
+	}
+}
+
+func TestTemplateSameScope(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	a2 := a
+
+	if !sameScope(nil, nil) {
+		t.Error("two global templates share a scope")
+	}
+	if sameScope(nil, &a) || sameScope(&a, nil) {
+		t.Error("a global and a topic template do not share a scope")
+	}
+	if !sameScope(&a, &a2) || sameScope(&a, &b) {
+		t.Error("topic templates share a scope only within the same topic")
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind error
+	}{
+		{ErrTopicExists, ErrConflict},
+		{ErrTemplateExists, ErrConflict},
+		{ErrThreadLocked, ErrForbidden},
+		{ErrTopicReadOnly, ErrForbidden},
+		{ErrNotModerator, ErrForbidden},
+		{ErrEmptyValue, ErrInvalidArgument},
+		{notFound("thread", "abc"), ErrNotFound},
+		{&AmbiguousError{Kind: "thread", Prefix: "a"}, ErrAmbiguous},
+		{syncFailed(errors.New("connection refused")), ErrSyncFailed},
+	}
+	for _, tt := range tests {
+		if !errors.Is(fmt.Errorf("wrapped: %w", tt.err), tt.kind) {
+			t.Errorf("%q does not match %q", tt.err, tt.kind)
+		}
+	}
+
+	if got := notFound("thread", "abc").Error(); got != "thread not found: abc" {
+		t.Errorf("not found message = %q", got)
+	}
+	if _, err := ParseSince("soon", time.Now()); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ParseSince error %v should be an invalid argument", err)
+	}
+
+	id := uuid.New()
+	amb := &AmbiguousError{Kind: "thread", Prefix: "a", Candidates: []Candidate{{ID: id, Name: "Deploy"}, {ID: uuid.New(), Name: "Standup"}}}
+	if msg := amb.Error(); !strings.Contains(msg, id.String()) || !strings.Contains(msg, "matches 2 threads") {
+		t.Errorf("ambiguous message should list candidates, got %q", msg)
+	}
+}
+
+func TestBatchRef(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"$0", 0, true},
+		{"$12", 12, true},
+		{"$", 0, false},
+		{"$-1", 0, false},
+		{"$x", 0, false},
+		{"0", 0, false},
+		{"general", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := batchRef(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("batchRef(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestValidateBatchOp(t *testing.T) {
+	ops := []BatchOp{
+		{Op: BatchCreateThread, Topic: "general", Subject: "Deploy"},
+		{Op: BatchPostMessage, Thread: "$0", Content: "first"},
+		{Op: BatchSticky, Thread: "$0", Sticky: true},
+		{Op: BatchEdit, Message: "$1", Content: "fixed"},
+		{Op: BatchEdit, Message: "$0", Content: "no opening post"},
+		{Op: BatchPostMessage, Thread: "$9", Content: "later"},
+		{Op: BatchArchive, Topic: "$0"},
+		{Op: "delete", Thread: "$0"},
+		{Op: BatchPostMessage, Thread: "abc"},
+		{Op: BatchCreateThread, Topic: "general"},
+	}
+	valid := map[int]bool{0: true, 1: true, 2: true, 3: true}
+	for i := range ops {
+		err := validateBatchOp(ops, i)
+		if valid[i] && err != nil {
+			t.Errorf("operation %d: unexpected error %v", i, err)
+		}
+		if !valid[i] && !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("operation %d: error %v should be an invalid argument", i, err)
+		}
+	}
+
+	self := []BatchOp{{Op: BatchPostMessage, Thread: "$0", Content: "loop"}}
+	if err := validateBatchOp(self, 0); err == nil {
+		t.Error("an operation must not refer to itself")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{5, 32 * time.Second},
+		{20, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.failures); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestSyncStateQueue(t *testing.T) {
+	st := &SyncState{}
+	st.queue([]OutboxEntry{
+		{Key: "topic:a", Value: []byte("v1"), Base: []byte("v0")},
+		{Key: "thread:b", Value: []byte("new")},
+	})
+	st.queue([]OutboxEntry{
+		{Key: "topic:a", Value: []byte("v2"), Base: []byte("v1")},
+		{Key: "thread:b", Value: nil, Base: []byte("new")},
+	})
+
+	if len(st.Pending) != 1 {
+		t.Fatalf("pending = %+v, want only topic:a", st.Pending)
+	}
+	if e := st.Pending[0]; e.Key != "topic:a" || string(e.Value) != "v2" || string(e.Base) != "v0" {
+		t.Errorf("merged entry = %+v, want value v2 over base v0", e)
+	}
+}
+
+func TestSyncStateReconcile(t *testing.T) {
+	now := time.Now()
+	st := &SyncState{Pending: []OutboxEntry{
+		{Key: "pushed", Value: []byte("mine"), Base: []byte("old")},
+		{Key: "undone", Value: []byte("mine"), Base: []byte("old")},
+		{Key: "deleted", Value: nil, Base: []byte("old")},
+		{Key: "conflict", Value: []byte("mine"), Base: []byte("old")},
+	}}
+	remote := map[string][]byte{
+		"pushed":   []byte("mine"),
+		"undone":   []byte("old"),
+		"conflict": []byte("theirs"),
+	}
+
+	noMerge := func(key string, base, local, remote []byte) ([]byte, error) { return nil, models.ErrMergeConflict }
+	reapply, err := st.reconcile(func(key string) ([]byte, error) { return remote[key], nil }, noMerge, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reapply) != 1 || reapply[0].Key != "undone" {
+		t.Errorf("reapply = %+v, want only undone", reapply)
+	}
+	if len(st.Pending) != 1 || st.Pending[0].Key != "undone" {
+		t.Errorf("pending = %+v, want only undone", st.Pending)
+	}
+	if len(st.Conflicts) != 1 {
+		t.Fatalf("conflicts = %+v, want one", st.Conflicts)
+	}
+	if c := st.Conflicts[0]; c.Key != "conflict" || string(c.Local) != "mine" || string(c.Remote) != "theirs" || !c.DetectedAt.Equal(now) {
+		t.Errorf("conflict = %+v", c)
+	}
+}
+
+func TestSyncStateDue(t *testing.T) {
+	now := time.Now()
+	st := &SyncState{}
+	if st.Due(now) {
+		t.Error("an empty outbox is never due")
+	}
+	st.Pending = []OutboxEntry{{Key: "topic:a", QueuedAt: now.Add(-time.Minute)}}
+	if !st.Due(now) {
+		t.Error("queued writes with no backoff should be due")
+	}
+	st.NextAttempt = now.Add(time.Minute)
+	if st.Due(now) {
+		t.Error("queued writes should wait out the backoff")
+	}
+	if got := st.OldestPending(); !got.Equal(now.Add(-time.Minute)) {
+		t.Errorf("OldestPending = %v", got)
+	}
+}
+
+func TestUpdateSyncState(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	c := &Client{dbName: "test"}
+
+	if err := c.updateSyncState(func(st *SyncState) error {
+		st.queue([]OutboxEntry{{Key: "topic:a", Value: []byte("v1")}})
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.recordSync(errors.New("connection refused"), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := c.SyncState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Pending) != 1 || st.LastError != "connection refused" || st.Failures != 1 {
+		t.Errorf("state = %+v", st)
+	}
+	if st.Due(time.Now()) {
+		t.Error("a failed sync should back off before the next attempt")
+	}
+}
+
+func TestSyncIfStaleBacksOff(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	// Nothing is reachable on port 1, so a sync attempt would fail.
+	c := &Client{dbName: "test", charmHost: "127.0.0.1:1", staleThreshold: time.Nanosecond}
+
+	failedAt := time.Now()
+	if err := c.recordSync(errors.New("connection refused"), failedAt); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for range 3 {
+		if err := c.SyncIfStale(); err != nil {
+			t.Fatalf("SyncIfStale during backoff = %v, want nil", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("SyncIfStale took %v during backoff", elapsed)
+	}
+	st, err := c.SyncState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.LastAttempt.Equal(failedAt) || st.Failures != 1 {
+		t.Errorf("SyncIfStale retried during backoff: %+v", st)
+	}
+}
+
+func TestMergeRecord(t *testing.T) {
+	base := models.NewThread(uuid.New(), "Deploy", "harper@cli")
+	local, remote := *base, *base
+	local.Sticky = true
+	remote.Subject = "Deploy Friday"
+	encode := func(v any) []byte {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	data, err := mergeRecord(ThreadPrefix+base.ID.String(), encode(base), encode(&local), encode(&remote))
+	if err != nil {
+		t.Fatalf("mergeRecord: %v", err)
+	}
+	var merged models.Thread
+	if err := json.Unmarshal(data, &merged); err != nil {
+		t.Fatal(err)
+	}
+	if !merged.Sticky || merged.Subject != "Deploy Friday" {
+		t.Errorf("merged = %+v, want both edits", merged)
+	}
+
+	key := ThreadPrefix + base.ID.String()
+	if _, err := mergeRecord(key, encode(base), nil, encode(&remote)); !errors.Is(err, models.ErrMergeConflict) {
+		t.Errorf("a local delete of a remote edit should conflict, got %v", err)
+	}
+	if _, err := mergeRecord("feed:harper", []byte(`"a"`), []byte(`"b"`), []byte(`"c"`)); !errors.Is(err, models.ErrMergeConflict) {
+		t.Errorf("records without a merge should conflict, got %v", err)
+	}
+}
+
+func TestFindConflict(t *testing.T) {
+	conflicts := []Conflict{
+		{Key: "thread:abc123"},
+		{Key: "message:abd456"},
+		{Key: "topic:fff000"},
+	}
+	if c, err := FindConflict(conflicts, "topic:fff000"); err != nil || c.Key != "topic:fff000" {
+		t.Errorf("full key: %v, %v", c, err)
+	}
+	if c, err := FindConflict(conflicts, "abc"); err != nil || c.Key != "thread:abc123" {
+		t.Errorf("ID prefix: %v, %v", c, err)
+	}
+	if _, err := FindConflict(conflicts, "ab"); !errors.Is(err, ErrAmbiguous) {
+		t.Errorf("shared prefix should be ambiguous, got %v", err)
+	}
+	if _, err := FindConflict(conflicts, "zzz"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown key should be not found, got %v", err)
+	}
+}
+
+func TestWithVersion(t *testing.T) {
+	data, err := withVersion([]byte(`{"ID":"x","Version":2}`), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := recordVersion(data); v != 7 {
+		t.Errorf("version = %d, want 7", v)
+	}
+	if v := recordVersion(nil); v != 0 {
+		t.Errorf("version of a deleted record = %d, want 0", v)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	d := 10 * time.Second
+	if got := jitter(d, 0); got != 8*time.Second {
+		t.Errorf("jitter(r=0) = %v, want 8s", got)
+	}
+	if got := jitter(d, 0.5); got != d {
+		t.Errorf("jitter(r=0.5) = %v, want %v", got, d)
+	}
+	if got := jitter(d, 0.999); got < 11*time.Second || got > 12*time.Second {
+		t.Errorf("jitter(r≈1) = %v, want just under 12s", got)
+	}
+}
+
+func TestDaemonHealth(t *testing.T) {
+	start := time.Now()
+	h := &DaemonHealth{StartedAt: start, Interval: time.Minute, UpdatedAt: start}
+
+	if !h.Running(start.Add(time.Minute)) || h.Running(start.Add(time.Hour)) {
+		t.Error("a daemon is running only while its heartbeat is fresh")
+	}
+	var missing *DaemonHealth
+	if missing.Running(start) {
+		t.Error("no health file means no daemon")
+	}
+	if lag := h.Lag(start.Add(30 * time.Second)); lag != 30*time.Second {
+		t.Errorf("lag before the first sync = %v, want time since start", lag)
+	}
+
+	failed := start.Add(time.Second)
+	h.record(errors.New("connection refused"), failed, 0.5)
+	h.record(errors.New("connection refused"), failed, 0.5)
+	if h.Failures != 2 || !h.NextSync.Equal(failed.Add(backoff(2))) {
+		t.Errorf("after failures: %d failures, next sync %v", h.Failures, h.NextSync.Sub(failed))
+	}
+
+	ok := start.Add(time.Minute)
+	h.record(nil, ok, 0.5)
+	if h.Failures != 0 || h.LastError != "" || !h.NextSync.Equal(ok.Add(time.Minute)) {
+		t.Errorf("after success: %+v", h)
+	}
+	if lag := h.Lag(ok.Add(5 * time.Second)); lag != 5*time.Second {
+		t.Errorf("lag = %v, want time since last success", lag)
+	}
+}
+
+func TestCountRecords(t *testing.T) {
+	keys := [][]byte{
+		[]byte("topic:a"), []byte("topic:b"),
+		[]byte("thread:c"),
+		[]byte("reaction:m:harper:👍"),
+		[]byte("schema_version"),
+	}
+	got := countRecords(keys)
+	want := map[string]int{"topic": 2, "thread": 1, "reaction": 1, "other": 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("countRecords = %v, want %v", got, want)
+	}
+}
+
+func TestStatsStale(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		last      time.Time
+		threshold time.Duration
+		want      bool
+	}{
+		{"never synced", time.Time{}, time.Minute, true},
+		{"recent", now.Add(-30 * time.Second), time.Minute, false},
+		{"old", now.Add(-time.Hour), time.Minute, true},
+		{"check disabled", time.Time{}, 0, false},
+	}
+	for _, tt := range tests {
+		s := &Stats{LastSync: tt.last, StaleThreshold: tt.threshold}
+		if got := s.Stale(now); got != tt.want {
+			t.Errorf("%s: Stale = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveWatchTargetWith(t *testing.T) {
+	topic := models.NewTopic("general", "", "harper@cli")
+	thread := models.NewThread(topic.ID, "Deploy", "harper@cli")
+	ambiguous := &AmbiguousError{Kind: "topic", Prefix: "ab", Candidates: []Candidate{{ID: uuid.New(), Name: "a"}, {ID: uuid.New(), Name: "b"}}}
+	topics := func(ref string) (*models.Topic, error) {
+		switch ref {
+		case "general":
+			return topic, nil
+		case "ab":
+			return nil, ambiguous
+		}
+		return nil, notFound("topic", ref)
+	}
+	threads := func(ref string) (*models.Thread, error) {
+		if ref == "deploy" {
+			return thread, nil
+		}
+		return nil, notFound("thread", ref)
+	}
+
+	if kind, id, _, err := ResolveWatchTargetWith("general", "", topics, threads); err != nil || kind != models.WatchTopic || id != topic.ID {
+		t.Errorf("topic target = %s %s %v", kind, id, err)
+	}
+	if kind, id, _, err := ResolveWatchTargetWith("deploy", "", topics, threads); err != nil || kind != models.WatchThread || id != thread.ID {
+		t.Errorf("thread target = %s %s %v", kind, id, err)
+	}
+	var amb *AmbiguousError
+	if _, _, _, err := ResolveWatchTargetWith("ab", "", topics, threads); !errors.As(err, &amb) || len(amb.Candidates) != 2 {
+		t.Errorf("ambiguous topic prefix = %v, want the AmbiguousError", err)
+	}
+	var nf *NotFoundError
+	if _, _, _, err := ResolveWatchTargetWith("nope", "", topics, threads); !errors.As(err, &nf) || nf.Kind != "topic or thread" {
+		t.Errorf("missing target = %v, want topic or thread not found", err)
+	}
+	if _, _, _, err := ResolveWatchTargetWith("general", "forum", topics, threads); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("unknown kind = %v, want ErrInvalidArgument", err)
+	}
+}
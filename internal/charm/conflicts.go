@@ -0,0 +1,171 @@
+// ABOUTME: Merging concurrent edits found during sync and resolving the rest
+// ABOUTME: Conflicts are held in the sync state until kept locally or dropped
+
+package charm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/charm/kv"
+
+	"github.com/harper/bbs/internal/models"
+)
+
+// mergeFunc combines a local write with a remote change to the same key
+// made since base. It fails with models.ErrMergeConflict when the two
+// cannot be combined.
+type mergeFunc func(key string, base, local, remote []byte) ([]byte, error)
+
+// mergeRecord merges topics, threads and messages field by field. Other
+// records such as reactions and subscriptions are stored one per key, so
+// concurrent additions never share a key; a key changed on both sides is
+// a conflict.
+func mergeRecord(key string, base, local, remote []byte) ([]byte, error) {
+	if local == nil {
+		return nil, fmt.Errorf("%w: deleted locally but changed remotely", models.ErrMergeConflict)
+	}
+	if remote == nil {
+		return nil, fmt.Errorf("%w: changed locally but deleted remotely", models.ErrMergeConflict)
+	}
+	switch {
+	case strings.HasPrefix(key, TopicPrefix):
+		return mergeJSON(base, local, remote, models.MergeTopic)
+	case strings.HasPrefix(key, ThreadPrefix):
+		return mergeJSON(base, local, remote, models.MergeThread)
+	case strings.HasPrefix(key, MessagePrefix):
+		return mergeJSON(base, local, remote, models.MergeMessage)
+	}
+	return nil, models.ErrMergeConflict
+}
+
+// mergeJSON decodes three versions of a record, merges them and encodes the result.
+func mergeJSON[T any](base, local, remote []byte, merge func(base, local, remote *T) (*T, error)) ([]byte, error) {
+	var b, l, r T
+	if base != nil {
+		if err := json.Unmarshal(base, &b); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(local, &l); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(remote, &r); err != nil {
+		return nil, err
+	}
+	merged, err := merge(&b, &l, &r)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merged)
+}
+
+// Versions returns the record versions on each side of the conflict, or
+// zero for a side that was deleted or is not a versioned record.
+func (c Conflict) Versions() (local, remote uint64) {
+	return recordVersion(c.Local), recordVersion(c.Remote)
+}
+
+// Conflicts returns the sync conflicts waiting to be resolved.
+func (c *Client) Conflicts() ([]Conflict, error) {
+	st, err := c.SyncState()
+	if err != nil {
+		return nil, err
+	}
+	return st.Conflicts, nil
+}
+
+// FindConflict matches ref against the conflicts' keys, either in full or
+// as a prefix of the record ID after the key's kind.
+func FindConflict(conflicts []Conflict, ref string) (Conflict, error) {
+	var matches []Conflict
+	for _, c := range conflicts {
+		if c.Key == ref {
+			return c, nil
+		}
+		if _, id, ok := strings.Cut(c.Key, ":"); ok && strings.HasPrefix(id, ref) {
+			matches = append(matches, c)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Conflict{}, notFound("conflict", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		keys := make([]string, len(matches))
+		for i, m := range matches {
+			keys[i] = m.Key
+		}
+		return Conflict{}, newError(ErrAmbiguous, "conflict '"+ref+"' matches "+strings.Join(keys, ", "))
+	}
+}
+
+// ResolveConflict settles the conflict ref names. Keeping the local
+// version writes it over the remote one with a newer version, and it syncs
+// like any other write; otherwise the remote version already in place is
+// kept and the local one discarded.
+func (c *Client) ResolveConflict(ref string, keepLocal bool) (*Conflict, error) {
+	conflicts, err := c.Conflicts()
+	if err != nil {
+		return nil, err
+	}
+	conflict, err := FindConflict(conflicts, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if keepLocal {
+		err := c.Do(func(k *Tx) error {
+			key := []byte(conflict.Key)
+			if conflict.Local == nil {
+				return k.Delete(key)
+			}
+			current, err := k.Get(key)
+			if err != nil && !errors.Is(err, kv.ErrMissingKey) {
+				return err
+			}
+			data := conflict.Local
+			if isRecordKey(conflict.Key) {
+				if data, err = withVersion(data, max(recordVersion(data), recordVersion(current))+1); err != nil {
+					return err
+				}
+			}
+			return k.Set(key, data)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = c.updateSyncState(func(st *SyncState) error {
+		st.Conflicts = slices.DeleteFunc(st.Conflicts, func(c Conflict) bool { return c.Key == conflict.Key })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &conflict, nil
+}
+
+// isRecordKey reports whether key holds a versioned topic, thread or message.
+func isRecordKey(key string) bool {
+	return strings.HasPrefix(key, TopicPrefix) || strings.HasPrefix(key, ThreadPrefix) || strings.HasPrefix(key, MessagePrefix)
+}
+
+// withVersion returns a stored record with its Version replaced.
+func withVersion(data []byte, version uint64) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	v, err := json.Marshal(version)
+	if err != nil {
+		return nil, err
+	}
+	fields["Version"] = v
+	return json.Marshal(fields)
+}
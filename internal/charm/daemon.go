@@ -0,0 +1,163 @@
+// ABOUTME: Long-running sync daemon that keeps the local replica warm
+// ABOUTME: Reports its health in a status file read by 'bbs sync status' and the TUI
+
+package charm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultSyncInterval is how often the daemon syncs when not configured.
+const DefaultSyncInterval = time.Minute
+
+// The daemon rewrites its health file every daemonHeartbeat. A file not
+// updated for daemonStaleAfter belongs to a daemon that is no longer
+// running; a sync in progress can hold up the heartbeat for syncTimeout.
+const (
+	daemonHeartbeat  = 15 * time.Second
+	daemonStaleAfter = syncTimeout + 2*daemonHeartbeat
+)
+
+// jitterFraction spreads retries by up to this share of the delay either
+// way, so devices that lost the server together do not retry together.
+const jitterFraction = 0.2
+
+// jitter scales d by a factor between 1-jitterFraction and 1+jitterFraction,
+// picked by r in [0, 1).
+func jitter(d time.Duration, r float64) time.Duration {
+	return d + time.Duration((2*r-1)*jitterFraction*float64(d))
+}
+
+// DaemonHealth is what a sync daemon reports about itself.
+type DaemonHealth struct {
+	PID         int           `json:"pid"`
+	StartedAt   time.Time     `json:"started_at"`
+	Interval    time.Duration `json:"interval"`
+	LastAttempt time.Time     `json:"last_attempt"`
+	LastSuccess time.Time     `json:"last_success"`
+	LastError   string        `json:"last_error,omitempty"`
+	Failures    int           `json:"failures"`
+	NextSync    time.Time     `json:"next_sync"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// Running reports whether the daemon has checked in recently.
+func (h *DaemonHealth) Running(now time.Time) bool {
+	return h != nil && now.Sub(h.UpdatedAt) < daemonStaleAfter
+}
+
+// Lag returns how far the local replica may be behind the server: the time
+// since the last successful sync, or since the daemon started if it has
+// not synced yet.
+func (h *DaemonHealth) Lag(now time.Time) time.Duration {
+	if h.LastSuccess.IsZero() {
+		return now.Sub(h.StartedAt)
+	}
+	return now.Sub(h.LastSuccess)
+}
+
+// record notes the outcome of a sync and schedules the next one: after
+// interval on success, or after a jittered backoff on failure.
+func (h *DaemonHealth) record(err error, now time.Time, r float64) {
+	h.LastAttempt = now
+	if err != nil {
+		h.LastError = err.Error()
+		h.Failures++
+		h.NextSync = now.Add(jitter(backoff(h.Failures), r))
+	} else {
+		h.LastSuccess = now
+		h.LastError = ""
+		h.Failures = 0
+		h.NextSync = now.Add(h.Interval)
+	}
+}
+
+// daemonPath returns the health file for the client's database.
+func (c *Client) daemonPath() string {
+	return filepath.Join(c.stateDir(), c.dbName+"-daemon.json")
+}
+
+// DaemonHealth returns the health the sync daemon last reported, or nil if
+// no daemon has run. Check Running before trusting it.
+func (c *Client) DaemonHealth() (*DaemonHealth, error) {
+	data, err := os.ReadFile(c.daemonPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var h DaemonHealth
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("read daemon health: %w", err)
+	}
+	return &h, nil
+}
+
+func (c *Client) writeDaemonHealth(h *DaemonHealth) error {
+	path := c.daemonPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// RunDaemon syncs every interval until ctx is done, backing off with
+// jitter while the server is unreachable. onSync, if set, is called with
+// the daemon's health after each attempt. Only one daemon runs per
+// database; the health file is removed when it stops.
+func (c *Client) RunDaemon(ctx context.Context, interval time.Duration, onSync func(DaemonHealth)) error {
+	if interval <= 0 {
+		return newError(ErrInvalidArgument, "sync interval must be positive")
+	}
+	now := time.Now()
+	if h, err := c.DaemonHealth(); err == nil && h.Running(now) && h.PID != os.Getpid() {
+		return newError(ErrConflict, fmt.Sprintf("sync daemon already running (pid %d)", h.PID))
+	}
+
+	h := &DaemonHealth{PID: os.Getpid(), StartedAt: now, Interval: interval, NextSync: now, UpdatedAt: now}
+	if err := c.writeDaemonHealth(h); err != nil {
+		return fmt.Errorf("write daemon health: %w", err)
+	}
+	defer func() { _ = os.Remove(c.daemonPath()) }()
+
+	next := time.NewTimer(0)
+	defer next.Stop()
+	heartbeat := time.NewTicker(daemonHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+		case <-next.C:
+			err := c.SyncContext(ctx)
+			if ctx.Err() != nil {
+				return nil
+			}
+			h.record(err, time.Now(), rand.Float64())
+			next.Reset(time.Until(h.NextSync))
+			if onSync != nil {
+				onSync(*h)
+			}
+		}
+		h.UpdatedAt = time.Now()
+		if err := c.writeDaemonHealth(h); err != nil {
+			return fmt.Errorf("write daemon health: %w", err)
+		}
+	}
+}
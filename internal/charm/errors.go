@@ -0,0 +1,92 @@
+// ABOUTME: Error kinds shared by every client operation
+// ABOUTME: Callers match them with errors.Is to map failures to stable codes
+
+package charm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Error kinds. Every error the client returns for these conditions
+// matches one of them with errors.Is; the message carries the details.
+var (
+	// ErrNotFound is returned when a topic, thread, message or other record does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrAmbiguous is returned when an ID prefix matches more than one record.
+	// The error is an *AmbiguousError listing the candidates.
+	ErrAmbiguous = errors.New("ambiguous")
+
+	// ErrConflict is returned when a write collides with an existing record.
+	ErrConflict = errors.New("conflict")
+
+	// ErrForbidden is returned when the identity may not make a change.
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrSyncFailed is returned when syncing with the charm server fails.
+	ErrSyncFailed = errors.New("sync failed")
+
+	// ErrInvalidArgument is returned for malformed input such as an unparseable time.
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// kindError is a fixed error message that belongs to one of the error kinds.
+type kindError struct {
+	msg  string
+	kind error
+}
+
+func (e *kindError) Error() string { return e.msg }
+func (e *kindError) Unwrap() error { return e.kind }
+
+// newError returns an error with message msg that matches kind.
+func newError(kind error, msg string) error {
+	return &kindError{msg: msg, kind: kind}
+}
+
+// NotFoundError reports a missing record of the given kind.
+type NotFoundError struct {
+	Kind string // "topic", "thread", "message", ...
+	Key  string // the ID, name or prefix that was looked up
+}
+
+func (e *NotFoundError) Error() string { return e.Kind + " not found: " + e.Key }
+func (e *NotFoundError) Unwrap() error { return ErrNotFound }
+
+// notFound returns a NotFoundError for a kind of record and the key that was looked up.
+func notFound(kind string, key any) error {
+	return &NotFoundError{Kind: kind, Key: fmt.Sprint(key)}
+}
+
+// Candidate is one of the records an ambiguous ID prefix matched.
+type Candidate struct {
+	ID   uuid.UUID
+	Name string
+}
+
+// AmbiguousError reports an ID prefix that matched several records.
+type AmbiguousError struct {
+	Kind       string
+	Prefix     string
+	Candidates []Candidate
+}
+
+func (e *AmbiguousError) Error() string {
+	names := make([]string, 0, len(e.Candidates))
+	for _, c := range e.Candidates {
+		names = append(names, fmt.Sprintf("%s (%s)", c.ID, c.Name))
+	}
+	return fmt.Sprintf("ambiguous %s ID prefix '%s' matches %d %ss: %s",
+		e.Kind, e.Prefix, len(e.Candidates), e.Kind, strings.Join(names, ", "))
+}
+
+func (e *AmbiguousError) Unwrap() error { return ErrAmbiguous }
+
+// syncFailed marks err as a failed sync with the charm server.
+func syncFailed(err error) error {
+	return fmt.Errorf("%w: %w", ErrSyncFailed, err)
+}
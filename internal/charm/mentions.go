@@ -0,0 +1,172 @@
+// ABOUTME: Per-identity inbox of @mentions
+// ABOUTME: Mentions are recorded in the same transaction as the message write
+
+package charm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+	"github.com/google/uuid"
+
+	"github.com/harper/bbs/internal/identity"
+	"github.com/harper/bbs/internal/models"
+)
+
+// MentionPrefix is the key prefix for inbox entries.
+const MentionPrefix = "mention:"
+
+// mentionKey is "mention:<username>:<message-id>" so each inbox shares a prefix.
+func mentionKey(username string, messageID uuid.UUID) []byte {
+	return []byte(MentionPrefix + strings.ToLower(username) + ":" + messageID.String())
+}
+
+func inboxPrefix(username string) []byte {
+	return []byte(MentionPrefix + strings.ToLower(username) + ":")
+}
+
+// recordMentions adds an inbox entry for every @username in the message.
+// Existing entries are left alone so that edits don't mark mentions unread again,
+// and authors are never notified about mentioning themselves.
+func recordMentions(k *Tx, msg *models.Message) error {
+	author, _ := identity.ParseIdentity(msg.CreatedBy)
+	for _, username := range models.ParseMentions(msg.Content) {
+		if strings.EqualFold(username, author) {
+			continue
+		}
+
+		key := mentionKey(username, msg.ID)
+		if _, err := k.Get(key); err == nil {
+			continue
+		} else if !errors.Is(err, kv.ErrMissingKey) {
+			return err
+		}
+
+		data, err := json.Marshal(models.NewMention(username, msg))
+		if err != nil {
+			return fmt.Errorf("marshal mention: %w", err)
+		}
+		if err := k.Set(key, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteMentions removes every inbox entry pointing at a message (used by cascade delete).
+func deleteMentions(k *Tx, messageID uuid.UUID) error {
+	keys, err := k.Keys()
+	if err != nil {
+		return err
+	}
+	prefix := []byte(MentionPrefix)
+	suffix := []byte(":" + messageID.String())
+	for _, key := range keys {
+		if bytes.HasPrefix(key, prefix) && bytes.HasSuffix(key, suffix) {
+			if err := k.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readInbox loads all mentions for a username, newest first.
+func readInbox(k *Tx, username string) ([]*models.Mention, error) {
+	var mentions []*models.Mention
+	prefix := inboxPrefix(username)
+
+	keys, err := k.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if !bytes.HasPrefix(key, prefix) {
+			continue
+		}
+
+		data, err := k.Get(key)
+		if err != nil {
+			if errors.Is(err, kv.ErrMissingKey) {
+				continue // Key was deleted between Keys() and Get()
+			}
+			return nil, err
+		}
+
+		var m models.Mention
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		mentions = append(mentions, &m)
+	}
+
+	sort.Slice(mentions, func(i, j int) bool {
+		return mentions[i].CreatedAt.After(mentions[j].CreatedAt)
+	})
+	return mentions, nil
+}
+
+// ListMentions returns the inbox for the identity's username, newest first.
+func (c *Client) ListMentions(id string, includeRead bool) ([]*models.Mention, error) {
+	username, _ := identity.ParseIdentity(id)
+
+	var mentions []*models.Mention
+	err := c.DoReadOnly(func(k *Tx) error {
+		all, err := readInbox(k, username)
+		if err != nil {
+			return err
+		}
+		for _, m := range all {
+			if includeRead || m.ReadAt == nil {
+				mentions = append(mentions, m)
+			}
+		}
+		return nil
+	})
+	return mentions, err
+}
+
+// CountUnreadMentions returns how many unread mentions the identity has.
+func (c *Client) CountUnreadMentions(id string) (int, error) {
+	mentions, err := c.ListMentions(id, false)
+	return len(mentions), err
+}
+
+// MarkMentionsRead marks the given mentions as read, or the whole inbox when none are given.
+func (c *Client) MarkMentionsRead(id string, messageIDs ...uuid.UUID) error {
+	username, _ := identity.ParseIdentity(id)
+	only := make(map[uuid.UUID]bool, len(messageIDs))
+	for _, mid := range messageIDs {
+		only[mid] = true
+	}
+
+	return c.Do(func(k *Tx) error {
+		mentions, err := readInbox(k, username)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, m := range mentions {
+			if m.ReadAt != nil || (len(only) > 0 && !only[m.MessageID]) {
+				continue
+			}
+			m.ReadAt = &now
+			data, err := json.Marshal(m)
+			if err != nil {
+				return fmt.Errorf("marshal mention: %w", err)
+			}
+			if err := k.Set(mentionKey(username, m.MessageID), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,136 @@
+// ABOUTME: Data migrations for records written by older versions
+// ABOUTME: Backfills topic slugs and renames duplicate topic names
+
+package charm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/harper/bbs/internal/models"
+)
+
+// TopicRename describes a duplicate topic that was (or would be) renamed.
+type TopicRename struct {
+	ID   uuid.UUID
+	From string
+	To   string
+}
+
+// TopicMigration reports what MigrateTopics found and changed.
+type TopicMigration struct {
+	// SlugsBackfilled counts topics that had no stored slug.
+	SlugsBackfilled int
+
+	// Duplicates groups topics whose names or slugs collide, oldest first.
+	Duplicates [][]*models.Topic
+
+	// Renames lists the new names given to every duplicate except the oldest.
+	Renames []TopicRename
+}
+
+// MigrateTopics backfills missing slugs and plans renames for duplicate topics.
+// Nothing is written unless apply is true.
+func (c *Client) MigrateTopics(apply bool) (*TopicMigration, error) {
+	result := &TopicMigration{}
+
+	err := c.Do(func(k *Tx) error {
+		topics, err := readTopics(k)
+		if err != nil {
+			return err
+		}
+
+		changed := make(map[uuid.UUID]*models.Topic)
+		for _, t := range topics {
+			if t.Slug == "" {
+				t.EnsureSlug()
+				result.SlugsBackfilled++
+				changed[t.ID] = t
+			}
+		}
+
+		result.Duplicates = duplicateTopicGroups(topics)
+		result.Renames = planTopicRenames(topics, result.Duplicates)
+		for _, r := range result.Renames {
+			for _, t := range topics {
+				if t.ID == r.ID {
+					t.Name = r.To
+					t.Slug = models.Slugify(r.To)
+					changed[t.ID] = t
+				}
+			}
+		}
+
+		if !apply {
+			return nil
+		}
+		for _, t := range changed {
+			if err := putTopic(k, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// duplicateTopicGroups groups topics that share a slug, which covers names
+// that differ only by case or punctuation. Each group is sorted oldest first.
+func duplicateTopicGroups(topics []*models.Topic) [][]*models.Topic {
+	bySlug := make(map[string][]*models.Topic)
+	var order []string
+	for _, t := range topics {
+		slug := t.Slug
+		if slug == "" {
+			slug = models.Slugify(t.Name)
+		}
+		if _, seen := bySlug[slug]; !seen {
+			order = append(order, slug)
+		}
+		bySlug[slug] = append(bySlug[slug], t)
+	}
+
+	var groups [][]*models.Topic
+	for _, slug := range order {
+		group := bySlug[slug]
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].CreatedAt.Before(group[j].CreatedAt)
+		})
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// planTopicRenames keeps the oldest topic in each group and numbers the rest
+// ("general 2", "general 3", ...), skipping names that are already taken.
+func planTopicRenames(topics []*models.Topic, groups [][]*models.Topic) []TopicRename {
+	taken := make(map[string]bool)
+	for _, t := range topics {
+		taken[models.Slugify(t.Name)] = true
+	}
+
+	var renames []TopicRename
+	for _, group := range groups {
+		base := group[0].Name
+		n := 2
+		for _, t := range group[1:] {
+			name := fmt.Sprintf("%s %d", base, n)
+			for taken[models.Slugify(name)] {
+				n++
+				name = fmt.Sprintf("%s %d", base, n)
+			}
+			taken[models.Slugify(name)] = true
+			n++
+			renames = append(renames, TopicRename{ID: t.ID, From: t.Name, To: name})
+		}
+	}
+	return renames
+}
@@ -0,0 +1,102 @@
+// ABOUTME: Thread locking and read-only topics
+// ABOUTME: Only configured moderators may toggle these or post past them
+
+package charm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/harper/bbs/internal/identity"
+)
+
+var (
+	// ErrThreadLocked is returned when posting to a locked thread.
+	ErrThreadLocked = newError(ErrForbidden, "thread is locked")
+
+	// ErrTopicReadOnly is returned when posting to a read-only topic.
+	ErrTopicReadOnly = newError(ErrForbidden, "topic is read-only")
+
+	// ErrNotModerator is returned when a non-moderator tries to lock or unlock.
+	ErrNotModerator = newError(ErrForbidden, "only moderators can do that")
+)
+
+// IsModerator reports whether the identity is listed in the configured moderators.
+func (c *Client) IsModerator(id string) bool {
+	return IsModeratorIn(c.moderators, id)
+}
+
+// IsModeratorIn reports whether the identity's username is in moderators.
+func IsModeratorIn(moderators []string, id string) bool {
+	username, _ := identity.ParseIdentity(id)
+	for _, m := range moderators {
+		if strings.EqualFold(m, username) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetThreadLocked locks or unlocks a thread. Only moderators may do this.
+func (c *Client) SetThreadLocked(id uuid.UUID, locked bool, by string) error {
+	if !c.IsModerator(by) {
+		return fmt.Errorf("%w: %s cannot lock threads", ErrNotModerator, by)
+	}
+	return c.Do(func(k *Tx) error {
+		thread, err := getThreadTx(k, id)
+		if err != nil {
+			return err
+		}
+		if thread.Locked == locked {
+			return nil
+		}
+		thread.RecordChange("Locked", strconv.FormatBool(thread.Locked), strconv.FormatBool(locked), by)
+		thread.Locked = locked
+		return putThread(k, thread)
+	})
+}
+
+// SetTopicReadOnly makes a topic read-only or writable again. Only moderators may do this.
+func (c *Client) SetTopicReadOnly(id uuid.UUID, readOnly bool, by string) error {
+	if !c.IsModerator(by) {
+		return fmt.Errorf("%w: %s cannot change read-only topics", ErrNotModerator, by)
+	}
+	return c.Do(func(k *Tx) error {
+		topic, err := getTopicTx(k, id)
+		if err != nil {
+			return err
+		}
+		if topic.ReadOnly == readOnly {
+			return nil
+		}
+		topic.RecordChange("ReadOnly", strconv.FormatBool(topic.ReadOnly), strconv.FormatBool(readOnly), by)
+		topic.ReadOnly = readOnly
+		return putTopic(k, topic)
+	})
+}
+
+// checkCanPost verifies that a new message may be added to the thread.
+// Moderators may still post to locked threads and read-only topics.
+func (c *Client) checkCanPost(k *Tx, threadID uuid.UUID, author string) error {
+	thread, err := getThreadTx(k, threadID)
+	if err != nil {
+		return err
+	}
+	if c.IsModerator(author) {
+		return nil
+	}
+	if thread.Locked {
+		return fmt.Errorf("%w: %s", ErrThreadLocked, thread.Subject)
+	}
+	topic, err := getTopicTx(k, thread.TopicID)
+	if err != nil {
+		return err
+	}
+	if topic.ReadOnly {
+		return fmt.Errorf("%w: %s", ErrTopicReadOnly, topic.Name)
+	}
+	return nil
+}
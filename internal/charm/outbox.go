@@ -0,0 +1,316 @@
+// ABOUTME: Durable outbox of local writes waiting to reach the charm server
+// ABOUTME: Re-applies writes a sync overwrote and records keys changed on both sides
+
+package charm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+)
+
+// Tx is an open database inside one Do or DoReadOnly call. Writes made
+// through it are queued in the outbox once the call succeeds.
+type Tx struct {
+	*kv.KV
+	writes []OutboxEntry
+}
+
+// Set stores value under key and records the write.
+func (tx *Tx) Set(key, value []byte) error {
+	if err := tx.record(key, value); err != nil {
+		return err
+	}
+	return tx.KV.Set(key, value)
+}
+
+// Delete removes key and records the write.
+func (tx *Tx) Delete(key []byte) error {
+	if err := tx.record(key, nil); err != nil {
+		return err
+	}
+	return tx.KV.Delete(key)
+}
+
+// record notes a write to key, reading the value it replaces the first
+// time the key is written in this call.
+func (tx *Tx) record(key, value []byte) error {
+	for i := range tx.writes {
+		if tx.writes[i].Key == string(key) {
+			tx.writes[i].Value = value
+			return nil
+		}
+	}
+	base, err := tx.KV.Get(key)
+	if errors.Is(err, kv.ErrMissingKey) {
+		base = nil
+	} else if err != nil {
+		return err
+	}
+	tx.writes = append(tx.writes, OutboxEntry{Key: string(key), Value: value, Base: base, QueuedAt: time.Now()})
+	return nil
+}
+
+// OutboxEntry is a local write that has not been confirmed on the charm
+// server. Value is nil for a delete; Base is the value the write replaced,
+// nil if the key did not exist.
+type OutboxEntry struct {
+	Key      string    `json:"key"`
+	Value    []byte    `json:"value"`
+	Base     []byte    `json:"base"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+// Conflict is a key that changed on the charm server while a local write
+// to it was waiting in the outbox, and the two could not be merged. The
+// remote value stays in place until the conflict is resolved; Local is
+// the write that is held back.
+type Conflict struct {
+	Key        string    `json:"key"`
+	Reason     string    `json:"reason"`
+	Local      []byte    `json:"local"`
+	Remote     []byte    `json:"remote"`
+	Base       []byte    `json:"base"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// SyncState is the outbox and sync history for one database, kept in a
+// file next to it so it survives restarts.
+type SyncState struct {
+	Pending     []OutboxEntry `json:"pending"`
+	Conflicts   []Conflict    `json:"conflicts"`
+	LastAttempt time.Time     `json:"last_attempt"`
+	LastSuccess time.Time     `json:"last_success"`
+	LastError   string        `json:"last_error,omitempty"`
+	Failures    int           `json:"failures"`
+	NextAttempt time.Time     `json:"next_attempt"`
+}
+
+// OldestPending returns when the oldest queued write was made, or the
+// zero time if nothing is queued.
+func (st *SyncState) OldestPending() time.Time {
+	var oldest time.Time
+	for _, e := range st.Pending {
+		if oldest.IsZero() || e.QueuedAt.Before(oldest) {
+			oldest = e.QueuedAt
+		}
+	}
+	return oldest
+}
+
+// Due reports whether queued writes should be pushed now, rather than
+// waiting out the backoff after a failed sync.
+func (st *SyncState) Due(now time.Time) bool {
+	return len(st.Pending) > 0 && !now.Before(st.NextAttempt)
+}
+
+// queue merges writes into the outbox. A key already waiting keeps its
+// original base; a key written back to its base no longer needs pushing.
+func (st *SyncState) queue(writes []OutboxEntry) {
+	for _, w := range writes {
+		i := st.pendingIndex(w.Key)
+		if i < 0 {
+			if !bytes.Equal(w.Value, w.Base) {
+				st.Pending = append(st.Pending, w)
+			}
+			continue
+		}
+		st.Pending[i].Value = w.Value
+		if bytes.Equal(st.Pending[i].Value, st.Pending[i].Base) {
+			st.Pending = append(st.Pending[:i], st.Pending[i+1:]...)
+		}
+	}
+}
+
+func (st *SyncState) pendingIndex(key string) int {
+	for i, e := range st.Pending {
+		if e.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// addConflict records a conflict, replacing an older one for the same key.
+func (st *SyncState) addConflict(c Conflict) {
+	for i := range st.Conflicts {
+		if st.Conflicts[i].Key == c.Key {
+			st.Conflicts[i] = c
+			return
+		}
+	}
+	st.Conflicts = append(st.Conflicts, c)
+}
+
+// reconcile compares each queued write with the value the database holds
+// after a sync. A write still in place has been pushed and is dropped. A
+// write undone by the sync while the key kept its base value is returned
+// in reapply and stays queued. Any other value means the key also changed
+// remotely: merge combines the two into a write to reapply, or the write
+// is dropped and a conflict recorded.
+func (st *SyncState) reconcile(current func(key string) ([]byte, error), merge mergeFunc, now time.Time) (reapply []OutboxEntry, err error) {
+	var keep []OutboxEntry
+	for _, e := range st.Pending {
+		cur, err := current(e.Key)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case bytes.Equal(cur, e.Value):
+		case bytes.Equal(cur, e.Base):
+			reapply = append(reapply, e)
+			keep = append(keep, e)
+		default:
+			merged, err := merge(e.Key, e.Base, e.Value, cur)
+			if err != nil {
+				st.addConflict(Conflict{Key: e.Key, Reason: err.Error(), Local: e.Value, Remote: cur, Base: e.Base, DetectedAt: now})
+				continue
+			}
+			e.Value, e.Base = merged, cur
+			reapply = append(reapply, e)
+			keep = append(keep, e)
+		}
+	}
+	st.Pending = keep
+	return reapply, nil
+}
+
+// StateDir returns the directory holding sync state files.
+func StateDir() string {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		home, _ := os.UserHomeDir()
+		stateHome = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(stateHome, "bbs")
+}
+
+// statePath returns the sync state file for the client's database.
+func (c *Client) statePath() string {
+	return filepath.Join(c.stateDir(), c.dbName+"-sync.json")
+}
+
+// stateMu serialises state updates within the process; the lock file
+// guards against other bbs processes.
+var stateMu sync.Mutex
+
+const (
+	stateLockWait  = 5 * time.Second
+	stateLockStale = 30 * time.Second
+)
+
+// SyncState returns the client's outbox and sync history.
+func (c *Client) SyncState() (*SyncState, error) {
+	return readSyncState(c.statePath())
+}
+
+// updateSyncState applies fn to the sync state and saves the result.
+func (c *Client) updateSyncState(fn func(st *SyncState) error) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	path := c.statePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	st, err := readSyncState(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(st); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal sync state: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func readSyncState(path string) (*SyncState, error) {
+	st := &SyncState{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("read sync state %s: %w", path, err)
+	}
+	return st, nil
+}
+
+// lockFile creates path exclusively, waiting for another holder to finish
+// and breaking locks left behind by a crashed process.
+func lockFile(path string) (func(), error) {
+	deadline := time.Now().Add(stateLockWait)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > stateLockStale {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("sync state is locked: %s", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// reconcileOutbox checks the outbox against the database after a sync and
+// writes back any queued changes the sync undid. It returns how many
+// writes were re-applied and still need pushing.
+func (c *Client) reconcileOutbox(k *kv.KV) (int, error) {
+	var reapply []OutboxEntry
+	err := c.updateSyncState(func(st *SyncState) error {
+		var err error
+		reapply, err = st.reconcile(func(key string) ([]byte, error) {
+			v, err := k.Get([]byte(key))
+			if errors.Is(err, kv.ErrMissingKey) {
+				return nil, nil
+			}
+			return v, err
+		}, mergeRecord, time.Now())
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range reapply {
+		if e.Value == nil {
+			err = k.Delete([]byte(e.Key))
+		} else {
+			err = k.Set([]byte(e.Key), e.Value)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(reapply), nil
+}
@@ -0,0 +1,127 @@
+// ABOUTME: Reaction storage for messages
+// ABOUTME: One key per message, reaction and identity so repeats are idempotent
+
+package charm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/charm/kv"
+	"github.com/google/uuid"
+
+	"github.com/harper/bbs/internal/models"
+)
+
+// ReactionPrefix is the key prefix for message reactions.
+const ReactionPrefix = "reaction:"
+
+// reactionKey is "reaction:<message-id>:<reaction>:<identity>", so all
+// reactions for a message share a prefix and reacting twice is a no-op.
+func reactionKey(messageID uuid.UUID, reaction, by string) []byte {
+	return []byte(ReactionPrefix + messageID.String() + ":" + reaction + ":" + by)
+}
+
+func messageReactionPrefix(messageID uuid.UUID) []byte {
+	return []byte(ReactionPrefix + messageID.String() + ":")
+}
+
+// AddReaction stores a reaction on an existing message.
+func (c *Client) AddReaction(r *models.Reaction) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("marshal reaction: %w", err)
+	}
+	return c.Do(func(k *Tx) error {
+		if _, err := k.Get(messageKey(r.MessageID)); err != nil {
+			if errors.Is(err, kv.ErrMissingKey) {
+				return notFound("message", r.MessageID)
+			}
+			return err
+		}
+		return k.Set(reactionKey(r.MessageID, r.Reaction, r.CreatedBy), data)
+	})
+}
+
+// RemoveReaction deletes one identity's reaction from a message.
+func (c *Client) RemoveReaction(messageID uuid.UUID, reaction, by string) error {
+	return c.Do(func(k *Tx) error {
+		return k.Delete(reactionKey(messageID, reaction, by))
+	})
+}
+
+// ListReactions returns every reaction on a message.
+func (c *Client) ListReactions(messageID uuid.UUID) ([]*models.Reaction, error) {
+	byMessage, err := c.ListReactionsForMessages([]uuid.UUID{messageID})
+	if err != nil {
+		return nil, err
+	}
+	return byMessage[messageID], nil
+}
+
+// ListReactionsForMessages returns reactions grouped by message in a single scan.
+func (c *Client) ListReactionsForMessages(messageIDs []uuid.UUID) (map[uuid.UUID][]*models.Reaction, error) {
+	result := make(map[uuid.UUID][]*models.Reaction)
+	prefixes := make([][]byte, 0, len(messageIDs))
+	for _, id := range messageIDs {
+		prefixes = append(prefixes, messageReactionPrefix(id))
+	}
+
+	err := c.DoReadOnly(func(k *Tx) error {
+		keys, err := k.Keys()
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if !hasAnyPrefix(key, prefixes) {
+				continue
+			}
+
+			data, err := k.Get(key)
+			if err != nil {
+				if errors.Is(err, kv.ErrMissingKey) {
+					continue // Key was deleted between Keys() and Get()
+				}
+				return err
+			}
+
+			var r models.Reaction
+			if err := json.Unmarshal(data, &r); err != nil {
+				return err
+			}
+			result[r.MessageID] = append(result[r.MessageID], &r)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// deleteReactions removes all reactions on a message (used by cascade delete).
+func deleteReactions(k *Tx, messageID uuid.UUID) error {
+	keys, err := k.Keys()
+	if err != nil {
+		return err
+	}
+	prefix := messageReactionPrefix(messageID)
+	for _, key := range keys {
+		if bytes.HasPrefix(key, prefix) {
+			if err := k.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func hasAnyPrefix(key []byte, prefixes [][]byte) bool {
+	for _, p := range prefixes {
+		if bytes.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,7 +4,6 @@
 package charm
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/google/uuid"
@@ -12,15 +11,34 @@ import (
 	"github.com/harper/bbs/internal/models"
 )
 
-// ResolveTopic finds a topic by ID, ID prefix, or name.
+// ResolveTopic finds a topic by ID, name, slug, or ID prefix.
 func (c *Client) ResolveTopic(idOrName string) (*models.Topic, error) {
+	return c.ResolveTopicFunc(idOrName, nil)
+}
+
+// ResolveTopicFunc is ResolveTopic over the topics keep accepts; a nil keep
+// accepts all. Other topics are never matched or listed as candidates.
+func (c *Client) ResolveTopicFunc(idOrName string, keep func(*models.Topic) bool) (*models.Topic, error) {
+	if keep == nil {
+		keep = func(*models.Topic) bool { return true }
+	}
+
 	// Try as full UUID first
 	if id, err := uuid.Parse(idOrName); err == nil {
-		return c.GetTopic(id)
+		topic, err := c.GetTopic(id)
+		if err == nil && !keep(topic) {
+			return nil, notFound("topic", idOrName)
+		}
+		return topic, err
 	}
 
 	// Try by name
-	if topic, err := c.GetTopicByName(idOrName); err == nil {
+	if topic, err := c.GetTopicByName(idOrName); err == nil && keep(topic) {
+		return topic, nil
+	}
+
+	// Try by slug
+	if topic, err := c.GetTopicBySlug(idOrName); err == nil && keep(topic) {
 		return topic, nil
 	}
 
@@ -32,26 +50,44 @@ func (c *Client) ResolveTopic(idOrName string) (*models.Topic, error) {
 
 	var matches []*models.Topic
 	for _, t := range topics {
-		if strings.HasPrefix(t.ID.String(), idOrName) {
+		if strings.HasPrefix(t.ID.String(), idOrName) && keep(t) {
 			matches = append(matches, t)
 		}
 	}
 
 	switch len(matches) {
 	case 0:
-		return nil, fmt.Errorf("topic not found: %s", idOrName)
+		return nil, notFound("topic", idOrName)
 	case 1:
 		return matches[0], nil
 	default:
-		return nil, fmt.Errorf("ambiguous topic ID prefix '%s' matches %d topics", idOrName, len(matches))
+		amb := &AmbiguousError{Kind: "topic", Prefix: idOrName}
+		for _, t := range matches {
+			amb.Candidates = append(amb.Candidates, Candidate{ID: t.ID, Name: t.Name})
+		}
+		return nil, amb
 	}
 }
 
 // ResolveThread finds a thread by ID or ID prefix.
 func (c *Client) ResolveThread(idPrefix string) (*models.Thread, error) {
+	return c.ResolveThreadFunc(idPrefix, nil)
+}
+
+// ResolveThreadFunc is ResolveThread over the threads keep accepts; a nil
+// keep accepts all.
+func (c *Client) ResolveThreadFunc(idPrefix string, keep func(*models.Thread) bool) (*models.Thread, error) {
+	if keep == nil {
+		keep = func(*models.Thread) bool { return true }
+	}
+
 	// Try as full UUID first
 	if id, err := uuid.Parse(idPrefix); err == nil {
-		return c.GetThread(id)
+		thread, err := c.GetThread(id)
+		if err == nil && !keep(thread) {
+			return nil, notFound("thread", idPrefix)
+		}
+		return thread, err
 	}
 
 	// Try as ID prefix - need to scan all threads
@@ -62,26 +98,44 @@ func (c *Client) ResolveThread(idPrefix string) (*models.Thread, error) {
 
 	var matches []*models.Thread
 	for _, t := range threads {
-		if strings.HasPrefix(t.ID.String(), idPrefix) {
+		if strings.HasPrefix(t.ID.String(), idPrefix) && keep(t) {
 			matches = append(matches, t)
 		}
 	}
 
 	switch len(matches) {
 	case 0:
-		return nil, fmt.Errorf("thread not found: %s", idPrefix)
+		return nil, notFound("thread", idPrefix)
 	case 1:
 		return matches[0], nil
 	default:
-		return nil, fmt.Errorf("ambiguous thread ID prefix '%s' matches %d threads", idPrefix, len(matches))
+		amb := &AmbiguousError{Kind: "thread", Prefix: idPrefix}
+		for _, t := range matches {
+			amb.Candidates = append(amb.Candidates, Candidate{ID: t.ID, Name: t.Subject})
+		}
+		return nil, amb
 	}
 }
 
 // ResolveMessage finds a message by ID or ID prefix.
 func (c *Client) ResolveMessage(idPrefix string) (*models.Message, error) {
+	return c.ResolveMessageFunc(idPrefix, nil)
+}
+
+// ResolveMessageFunc is ResolveMessage over the messages keep accepts; a
+// nil keep accepts all.
+func (c *Client) ResolveMessageFunc(idPrefix string, keep func(*models.Message) bool) (*models.Message, error) {
+	if keep == nil {
+		keep = func(*models.Message) bool { return true }
+	}
+
 	// Try as full UUID first
 	if id, err := uuid.Parse(idPrefix); err == nil {
-		return c.GetMessage(id)
+		msg, err := c.GetMessage(id)
+		if err == nil && !keep(msg) {
+			return nil, notFound("message", idPrefix)
+		}
+		return msg, err
 	}
 
 	// Try as ID prefix - need to scan all messages
@@ -92,18 +146,22 @@ func (c *Client) ResolveMessage(idPrefix string) (*models.Message, error) {
 
 	var matches []*models.Message
 	for _, m := range messages {
-		if strings.HasPrefix(m.ID.String(), idPrefix) {
+		if strings.HasPrefix(m.ID.String(), idPrefix) && keep(m) {
 			matches = append(matches, m)
 		}
 	}
 
 	switch len(matches) {
 	case 0:
-		return nil, fmt.Errorf("message not found: %s", idPrefix)
+		return nil, notFound("message", idPrefix)
 	case 1:
 		return matches[0], nil
 	default:
-		return nil, fmt.Errorf("ambiguous message ID prefix '%s' matches %d messages", idPrefix, len(matches))
+		amb := &AmbiguousError{Kind: "message", Prefix: idPrefix}
+		for _, m := range matches {
+			amb.Candidates = append(amb.Candidates, Candidate{ID: m.ID, Name: m.CreatedBy + ": " + models.Excerpt(m.Content)})
+		}
+		return nil, amb
 	}
 }
 
@@ -0,0 +1,169 @@
+// ABOUTME: Embedded Charm server for self-hosting and local testing
+// ABOUTME: Wraps charm's SQLite-backed server with a data dir, ports and client settings
+
+package charm
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/charm/server"
+	"github.com/charmbracelet/keygen"
+)
+
+// Default ports of a self-hosted Charm server.
+const (
+	DefaultServerSSHPort    = 35353
+	DefaultServerHTTPPort   = 35354
+	DefaultServerHealthPort = 35356
+)
+
+// serverStartTimeout bounds how long StartServer waits for the health check.
+const serverStartTimeout = 10 * time.Second
+
+// ServerConfig configures an embedded Charm server. A zero port picks a
+// free one.
+type ServerConfig struct {
+	DataDir    string // SQLite database, host keys and stored files
+	Host       string // name clients connect to (default: localhost)
+	BindAddr   string // address to listen on (default: all interfaces)
+	SSHPort    int
+	HTTPPort   int
+	HealthPort int
+}
+
+// ServerDataDir returns the default data dir for a self-hosted server.
+func ServerDataDir() string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, _ := os.UserHomeDir()
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "bbs", "server")
+}
+
+// Server is a running embedded Charm server.
+type Server struct {
+	cfg   ServerConfig
+	srv   *server.Server
+	errCh chan error
+}
+
+// StartServer starts a Charm server and waits until it is healthy. Host
+// keys are generated in the data dir on first start and reused after.
+func StartServer(cfg ServerConfig) (*Server, error) {
+	if cfg.DataDir == "" {
+		return nil, newError(ErrInvalidArgument, "server data dir is required")
+	}
+	if cfg.Host == "" {
+		cfg.Host = "localhost"
+	}
+	for _, port := range []*int{&cfg.SSHPort, &cfg.HTTPPort, &cfg.HealthPort} {
+		if *port == 0 {
+			p, err := freePort()
+			if err != nil {
+				return nil, fmt.Errorf("pick server port: %w", err)
+			}
+			*port = p
+		}
+	}
+
+	kp, err := keygen.New(filepath.Join(cfg.DataDir, ".ssh", "charm_server_ed25519"), keygen.WithKeyType(keygen.Ed25519), keygen.WithWrite())
+	if err != nil {
+		return nil, fmt.Errorf("server host key: %w", err)
+	}
+	sc := server.DefaultConfig()
+	sc.DataDir = cfg.DataDir
+	sc.Host = cfg.Host
+	sc.BindAddr = cfg.BindAddr
+	sc.SSHPort = cfg.SSHPort
+	sc.HTTPPort = cfg.HTTPPort
+	sc.HealthPort = cfg.HealthPort
+	sc = sc.WithKeys(kp.RawAuthorizedKey(), kp.RawPrivateKey())
+	srv, err := server.NewServer(sc)
+	if err != nil {
+		return nil, fmt.Errorf("create server: %w", err)
+	}
+
+	s := &Server{cfg: cfg, srv: srv, errCh: make(chan error, 1)}
+	go func() { s.errCh <- srv.Start() }()
+	if err := s.waitHealthy(); err != nil {
+		_ = srv.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// waitHealthy polls the health port until the server answers.
+func (s *Server) waitHealthy() error {
+	url := fmt.Sprintf("http://localhost:%d", s.cfg.HealthPort)
+	deadline := time.Now().Add(serverStartTimeout)
+	for {
+		select {
+		case err := <-s.errCh:
+			return fmt.Errorf("server failed to start: %w", err)
+		default:
+		}
+		resp, err := http.Get(url) // nolint:gosec
+		if err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("server not healthy after %s", serverStartTimeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Config returns the server's settings with the ports it listens on.
+func (s *Server) Config() ServerConfig {
+	return s.cfg
+}
+
+// ClientEnv returns the environment variables that point a charm client,
+// and so bbs, at this server.
+func (s *Server) ClientEnv() map[string]string {
+	return map[string]string{
+		"CHARM_HOST":      s.cfg.Host,
+		"CHARM_SSH_PORT":  strconv.Itoa(s.cfg.SSHPort),
+		"CHARM_HTTP_PORT": strconv.Itoa(s.cfg.HTTPPort),
+	}
+}
+
+// Done returns a channel that receives the error if the server stops on its own.
+func (s *Server) Done() <-chan error {
+	return s.errCh
+}
+
+// Shutdown stops the server gracefully, closing it outright if ctx ends first.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.srv.Shutdown(ctx); err != nil {
+		_ = s.srv.Close()
+		return err
+	}
+	return s.srv.Config.DB.Close()
+}
+
+// Close stops the server immediately.
+func (s *Server) Close() error {
+	return s.srv.Close()
+}
+
+// freePort asks the kernel for an unused TCP port.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close() //nolint:errcheck
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
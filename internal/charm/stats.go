@@ -0,0 +1,85 @@
+// ABOUTME: Local database statistics for sync status reporting
+// ABOUTME: Counts records per key prefix and reports size, staleness and unsent ops
+
+package charm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+)
+
+// Stats describes the local replica of the database.
+type Stats struct {
+	// Records counts keys by prefix without the colon, e.g. "topic".
+	Records        map[string]int
+	Path           string
+	SizeBytes      int64 // database and write-ahead log on disk
+	LastSync       time.Time
+	StaleThreshold time.Duration
+	UnsentOps      int64 // writes the charm library has not backed up yet
+}
+
+// Stale reports whether the replica is older than the stale threshold.
+func (s *Stats) Stale(now time.Time) bool {
+	return isStale(s.LastSync, s.StaleThreshold, now)
+}
+
+// isStale mirrors kv.IsStale: a zero threshold disables the check, and a
+// database that never synced is stale.
+func isStale(last time.Time, threshold time.Duration, now time.Time) bool {
+	if threshold == 0 {
+		return false
+	}
+	return last.IsZero() || now.Sub(last) > threshold
+}
+
+// countRecords tallies keys by the prefix before their first colon; keys
+// without one are counted as "other".
+func countRecords(keys [][]byte) map[string]int {
+	counts := make(map[string]int)
+	for _, key := range keys {
+		prefix, _, ok := strings.Cut(string(key), ":")
+		if !ok {
+			prefix = "other"
+		}
+		counts[prefix]++
+	}
+	return counts
+}
+
+// Stats reads record counts and sync metadata from the local database
+// without syncing it.
+func (c *Client) Stats() (*Stats, error) {
+	s := &Stats{StaleThreshold: c.staleThreshold}
+	err := c.withKV(true, func(k *kv.KV) error {
+		keys, err := k.Keys()
+		if err != nil {
+			return err
+		}
+		s.Records = countRecords(keys)
+		s.LastSync = k.LastSyncTime()
+		if doc, err := k.Doctor(); err == nil {
+			s.UnsentOps = doc.PendingOpsCount
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dataDir, err := c.DataPath()
+	if err != nil {
+		return s, nil
+	}
+	s.Path = filepath.Join(dataDir, "kv", c.dbName+".db")
+	for _, p := range []string{s.Path, s.Path + "-wal"} {
+		if info, err := os.Stat(p); err == nil {
+			s.SizeBytes += info.Size()
+		}
+	}
+	return s, nil
+}
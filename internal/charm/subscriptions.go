@@ -0,0 +1,248 @@
+// ABOUTME: Topic and thread subscriptions with a per-identity feed
+// ABOUTME: The feed shows activity on watched items since the last check
+
+package charm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+	"github.com/google/uuid"
+
+	"github.com/harper/bbs/internal/identity"
+	"github.com/harper/bbs/internal/models"
+)
+
+// Key prefixes for subscriptions and feed cursors.
+const (
+	SubscriptionPrefix = "subscription:"
+	FeedCursorPrefix   = "feedcursor:"
+)
+
+// subscriptionKey is "subscription:<username>:<target-id>".
+func subscriptionKey(username string, targetID uuid.UUID) []byte {
+	return []byte(SubscriptionPrefix + strings.ToLower(username) + ":" + targetID.String())
+}
+
+func feedCursorKey(username string) []byte {
+	return []byte(FeedCursorPrefix + strings.ToLower(username))
+}
+
+// Feed is the activity on an identity's watched topics and threads.
+type Feed struct {
+	Since         time.Time
+	Subscriptions int
+	Items         []*models.Activity
+}
+
+// ResolveWatchTarget finds the topic or thread a watch target refers to and
+// returns its kind, ID and display name. kind forces models.WatchTopic or
+// models.WatchThread; empty tries a topic first, then a thread.
+func (c *Client) ResolveWatchTarget(target, kind string) (string, uuid.UUID, string, error) {
+	return ResolveWatchTargetWith(target, kind, c.ResolveTopic, c.ResolveThread)
+}
+
+// ResolveWatchTargetWith is ResolveWatchTarget with the topic and thread
+// lookups supplied by the caller, for callers that limit what they can see.
+func ResolveWatchTargetWith(target, kind string, resolveTopic func(string) (*models.Topic, error), resolveThread func(string) (*models.Thread, error)) (string, uuid.UUID, string, error) {
+	switch kind {
+	case "", models.WatchTopic, models.WatchThread:
+	default:
+		return "", uuid.UUID{}, "", fmt.Errorf("%w: unknown kind %q: use topic or thread", ErrInvalidArgument, kind)
+	}
+
+	if kind != models.WatchThread {
+		topic, err := resolveTopic(target)
+		if err == nil {
+			return models.WatchTopic, topic.ID, topic.Name, nil
+		}
+		// Only a missing topic falls through to threads; ambiguous prefixes
+		// and read failures are reported as they are.
+		if kind == models.WatchTopic || !errors.Is(err, ErrNotFound) {
+			return "", uuid.UUID{}, "", err
+		}
+	}
+
+	thread, err := resolveThread(target)
+	if err != nil {
+		if kind == models.WatchThread || !errors.Is(err, ErrNotFound) {
+			return "", uuid.UUID{}, "", err
+		}
+		return "", uuid.UUID{}, "", &NotFoundError{Kind: "topic or thread", Key: target}
+	}
+	return models.WatchThread, thread.ID, thread.Subject, nil
+}
+
+// Subscribe adds a topic or thread to the identity's watch list.
+func (c *Client) Subscribe(id, kind string, targetID uuid.UUID) error {
+	username, _ := identity.ParseIdentity(id)
+	sub := models.NewSubscription(username, kind, targetID)
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return fmt.Errorf("marshal subscription: %w", err)
+	}
+
+	return c.Do(func(k *Tx) error {
+		switch kind {
+		case models.WatchTopic:
+			if _, err := getTopicTx(k, targetID); err != nil {
+				return err
+			}
+		case models.WatchThread:
+			if _, err := getThreadTx(k, targetID); err != nil {
+				return err
+			}
+		default:
+			return newError(ErrInvalidArgument, "unknown subscription kind: "+kind)
+		}
+		return k.Set(subscriptionKey(username, targetID), data)
+	})
+}
+
+// Unsubscribe removes a topic or thread from the identity's watch list.
+func (c *Client) Unsubscribe(id string, targetID uuid.UUID) error {
+	username, _ := identity.ParseIdentity(id)
+	return c.Do(func(k *Tx) error {
+		key := subscriptionKey(username, targetID)
+		if _, err := k.Get(key); err != nil {
+			if errors.Is(err, kv.ErrMissingKey) {
+				return newError(ErrNotFound, "not watching: "+targetID.String())
+			}
+			return err
+		}
+		return k.Delete(key)
+	})
+}
+
+// ListSubscriptions returns the identity's watch list.
+func (c *Client) ListSubscriptions(id string) ([]*models.Subscription, error) {
+	username, _ := identity.ParseIdentity(id)
+	var subs []*models.Subscription
+	err := c.DoReadOnly(func(k *Tx) error {
+		var err error
+		subs, err = readSubscriptions(k, username)
+		return err
+	})
+	return subs, err
+}
+
+// readSubscriptions loads every subscription for a username.
+func readSubscriptions(k *Tx, username string) ([]*models.Subscription, error) {
+	var subs []*models.Subscription
+	prefix := []byte(SubscriptionPrefix + strings.ToLower(username) + ":")
+
+	keys, err := k.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if !bytes.HasPrefix(key, prefix) {
+			continue
+		}
+
+		data, err := k.Get(key)
+		if err != nil {
+			if errors.Is(err, kv.ErrMissingKey) {
+				continue // Key was deleted between Keys() and Get()
+			}
+			return nil, err
+		}
+
+		var sub models.Subscription
+		if err := json.Unmarshal(data, &sub); err != nil {
+			return nil, err
+		}
+		subs = append(subs, &sub)
+	}
+	return subs, nil
+}
+
+// LastFeedCheck returns when the identity last marked its feed as read.
+// The zero time means the feed has never been checked.
+func (c *Client) LastFeedCheck(id string) (time.Time, error) {
+	username, _ := identity.ParseIdentity(id)
+	var at time.Time
+	err := c.DoReadOnly(func(k *Tx) error {
+		var err error
+		at, err = readFeedCursor(k, username)
+		return err
+	})
+	return at, err
+}
+
+func readFeedCursor(k *Tx, username string) (time.Time, error) {
+	var at time.Time
+	data, err := k.Get(feedCursorKey(username))
+	if err != nil {
+		if errors.Is(err, kv.ErrMissingKey) {
+			return at, nil
+		}
+		return at, err
+	}
+	err = json.Unmarshal(data, &at)
+	return at, err
+}
+
+// MarkFeedChecked records that the identity has seen its feed up to at.
+func (c *Client) MarkFeedChecked(id string, at time.Time) error {
+	username, _ := identity.ParseIdentity(id)
+	data, err := json.Marshal(at)
+	if err != nil {
+		return fmt.Errorf("marshal feed cursor: %w", err)
+	}
+	return c.Do(func(k *Tx) error {
+		return k.Set(feedCursorKey(username), data)
+	})
+}
+
+// GetFeed returns activity on the identity's watched topics and threads.
+// A nil since means "since the last feed check".
+func (c *Client) GetFeed(id string, since *time.Time) (*Feed, error) {
+	username, _ := identity.ParseIdentity(id)
+	feed := &Feed{}
+
+	err := c.DoReadOnly(func(k *Tx) error {
+		if since != nil {
+			feed.Since = *since
+		} else {
+			at, err := readFeedCursor(k, username)
+			if err != nil {
+				return err
+			}
+			feed.Since = at
+		}
+
+		subs, err := readSubscriptions(k, username)
+		if err != nil {
+			return err
+		}
+		feed.Subscriptions = len(subs)
+		if len(subs) == 0 {
+			return nil
+		}
+
+		watched := make(map[uuid.UUID]bool, len(subs))
+		for _, sub := range subs {
+			watched[sub.TargetID] = true
+		}
+
+		snap, err := readSnapshot(k)
+		if err != nil {
+			return err
+		}
+		feed.Items = snap.activity(feed.Since, func(t *models.Thread) bool {
+			return watched[t.ID] || watched[t.TopicID]
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
@@ -0,0 +1,142 @@
+// ABOUTME: Pushes the outbox to the charm server with retry and backoff
+// ABOUTME: Runs in the background for long-lived processes like the TUI and MCP server
+
+package charm
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+)
+
+// Retry delays after failed syncs: the first retry waits syncRetryMin,
+// then the delay doubles up to syncRetryMax.
+const (
+	syncRetryMin = 2 * time.Second
+	syncRetryMax = 5 * time.Minute
+)
+
+// syncTimeout bounds a Sync call without its own deadline.
+const syncTimeout = 60 * time.Second
+
+// maxSyncRounds bounds how often one sync pushes writes it had to re-apply.
+const maxSyncRounds = 3
+
+// backoff returns how long to wait after the given number of consecutive failures.
+func backoff(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	d := syncRetryMin
+	for i := 1; i < failures && d < syncRetryMax; i++ {
+		d *= 2
+	}
+	return min(d, syncRetryMax)
+}
+
+// Sync pushes queued writes and pulls remote changes, then reconciles the
+// outbox with what the server returned.
+func (c *Client) Sync() error {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+	return c.SyncContext(ctx)
+}
+
+// SyncContext is Sync with a deadline or cancellation.
+func (c *Client) SyncContext(ctx context.Context) error {
+	err := c.withKV(false, func(k *kv.KV) error {
+		for round := 1; ; round++ {
+			if err := k.SyncWithContext(ctx); err != nil {
+				return syncFailed(err)
+			}
+			reapplied, err := c.reconcileOutbox(k)
+			if err != nil || reapplied == 0 || round == maxSyncRounds {
+				return err
+			}
+		}
+	})
+	if stateErr := c.recordSync(err, time.Now()); err == nil {
+		err = stateErr
+	}
+	return err
+}
+
+// recordSync notes the outcome of a sync attempt and schedules the next retry.
+func (c *Client) recordSync(syncErr error, now time.Time) error {
+	return c.updateSyncState(func(st *SyncState) error {
+		st.LastAttempt = now
+		if syncErr != nil {
+			st.LastError = syncErr.Error()
+			st.Failures++
+		} else {
+			st.LastSuccess = now
+			st.LastError = ""
+			st.Failures = 0
+		}
+		st.NextAttempt = now.Add(backoff(st.Failures))
+		return nil
+	})
+}
+
+// FlushIfDue syncs when auto-sync is on, writes are queued and no backoff
+// is in effect. It reports whether a sync was attempted.
+func (c *Client) FlushIfDue(ctx context.Context) (bool, error) {
+	if !c.autoSync {
+		return false, nil
+	}
+	st, err := c.SyncState()
+	if err != nil {
+		return false, err
+	}
+	if !st.Due(time.Now()) {
+		return false, nil
+	}
+	return true, c.SyncContext(ctx)
+}
+
+// StartSyncer pushes queued writes in the background until ctx is done.
+// Writes made through the client wake it up at once; after a failure it
+// retries with exponential backoff.
+func (c *Client) StartSyncer(ctx context.Context) {
+	c.kick = make(chan struct{}, 1)
+	c.kickSyncer()
+	go func() {
+		retry := time.NewTimer(0)
+		if !retry.Stop() {
+			<-retry.C
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				retry.Stop()
+				return
+			case <-c.kick:
+			case <-retry.C:
+			}
+			st, err := c.SyncState()
+			if err != nil || len(st.Pending) == 0 {
+				continue
+			}
+			if wait := time.Until(st.NextAttempt); wait > 0 {
+				retry.Reset(wait)
+				continue
+			}
+			_ = c.SyncContext(ctx) // recorded in the sync state
+			if st, err := c.SyncState(); err == nil && len(st.Pending) > 0 {
+				retry.Reset(max(time.Until(st.NextAttempt), syncRetryMin))
+			}
+		}
+	}()
+}
+
+// kickSyncer wakes the background syncer, if one is running.
+func (c *Client) kickSyncer() {
+	if c.kick == nil {
+		return
+	}
+	select {
+	case c.kick <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,197 @@
+// ABOUTME: Message template storage
+// ABOUTME: Global and per-topic templates plus lookup of posts made from them
+
+package charm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/charm/kv"
+	"github.com/google/uuid"
+
+	"github.com/harper/bbs/internal/models"
+)
+
+// TemplatePrefix is the key prefix for message templates.
+const TemplatePrefix = "template:"
+
+// ErrTemplateExists is returned when a template name is already used in the same scope.
+var ErrTemplateExists = newError(ErrConflict, "template already exists")
+
+func templateKey(id uuid.UUID) []byte {
+	return []byte(TemplatePrefix + id.String())
+}
+
+// sameScope reports whether two templates apply to the same topic (or are both global).
+func sameScope(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// CreateTemplate stores a new template. Names are unique per topic and
+// among global templates; a topic template may shadow a global one.
+func (c *Client) CreateTemplate(t *models.Template) error {
+	if err := t.Check(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("marshal template: %w", err)
+	}
+
+	return c.Do(func(k *Tx) error {
+		if t.TopicID != nil {
+			if _, err := getTopicTx(k, *t.TopicID); err != nil {
+				return err
+			}
+		}
+
+		existing, err := readTemplates(k)
+		if err != nil {
+			return err
+		}
+		for _, other := range existing {
+			if other.Name == t.Name && sameScope(other.TopicID, t.TopicID) {
+				return fmt.Errorf("%w: %s", ErrTemplateExists, t.Name)
+			}
+		}
+		return k.Set(templateKey(t.ID), data)
+	})
+}
+
+// readTemplates loads every template, sorted by name with global ones first.
+func readTemplates(k *Tx) ([]*models.Template, error) {
+	var templates []*models.Template
+	keys, err := k.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if !bytes.HasPrefix(key, []byte(TemplatePrefix)) {
+			continue
+		}
+		data, err := k.Get(key)
+		if err != nil {
+			if errors.Is(err, kv.ErrMissingKey) {
+				continue // Key was deleted between Keys() and Get()
+			}
+			return nil, err
+		}
+		var t models.Template
+		if err := json.Unmarshal(data, &t); err != nil {
+			return nil, err
+		}
+		templates = append(templates, &t)
+	}
+
+	sort.SliceStable(templates, func(i, j int) bool {
+		if templates[i].Name != templates[j].Name {
+			return templates[i].Name < templates[j].Name
+		}
+		return templates[i].TopicID == nil && templates[j].TopicID != nil
+	})
+	return templates, nil
+}
+
+// ListTemplates returns all templates, or with a topic, the global templates
+// plus those scoped to that topic.
+func (c *Client) ListTemplates(topicID *uuid.UUID) ([]*models.Template, error) {
+	var templates []*models.Template
+	err := c.DoReadOnly(func(k *Tx) error {
+		all, err := readTemplates(k)
+		if err != nil {
+			return err
+		}
+		for _, t := range all {
+			if topicID == nil || t.TopicID == nil || *t.TopicID == *topicID {
+				templates = append(templates, t)
+			}
+		}
+		return nil
+	})
+	return templates, err
+}
+
+// GetTemplate finds a template by name, preferring one scoped to topicID
+// over a global one.
+func (c *Client) GetTemplate(name string, topicID *uuid.UUID) (*models.Template, error) {
+	templates, err := c.ListTemplates(topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	var global *models.Template
+	for _, t := range templates {
+		if t.Name != name {
+			continue
+		}
+		if t.TopicID == nil {
+			global = t
+		} else if topicID != nil {
+			return t, nil
+		}
+	}
+	if global != nil {
+		return global, nil
+	}
+	return nil, notFound("template", name)
+}
+
+// DeleteTemplate removes a template. Only its creator or a moderator may delete it.
+func (c *Client) DeleteTemplate(id uuid.UUID, by string) error {
+	return c.Do(func(k *Tx) error {
+		data, err := k.Get(templateKey(id))
+		if err != nil {
+			if errors.Is(err, kv.ErrMissingKey) {
+				return notFound("template", id)
+			}
+			return err
+		}
+		var t models.Template
+		if err := json.Unmarshal(data, &t); err != nil {
+			return err
+		}
+		if t.CreatedBy != by && !c.IsModerator(by) {
+			return ErrNotModerator
+		}
+		return k.Delete(templateKey(id))
+	})
+}
+
+// ListTemplatePosts returns messages posted from the named template whose
+// fields match every given value, newest first. A topicID limits the
+// search to threads in that topic.
+func (c *Client) ListTemplatePosts(name string, fields map[string]string, topicID *uuid.UUID) ([]*models.Message, error) {
+	var messages []*models.Message
+	err := c.DoReadOnly(func(k *Tx) error {
+		snap, err := readSnapshot(k)
+		if err != nil {
+			return err
+		}
+		for _, msg := range snap.messages {
+			if msg.Template != name || !msg.MatchesFields(fields) {
+				continue
+			}
+			if topicID != nil {
+				thread, ok := snap.threads[msg.ThreadID]
+				if !ok || thread.TopicID != *topicID {
+					continue
+				}
+			}
+			messages = append(messages, msg)
+		}
+		return nil
+	})
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.After(messages[j].CreatedAt)
+	})
+	return messages, err
+}
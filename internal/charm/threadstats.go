@@ -0,0 +1,106 @@
+// ABOUTME: Denormalized thread activity fields
+// ABOUTME: Recomputes last activity, message count and participants from messages
+
+package charm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+	"github.com/google/uuid"
+
+	"github.com/harper/bbs/internal/models"
+)
+
+// refreshThreadStats recomputes a thread's activity fields from its messages.
+// A missing thread is ignored.
+func refreshThreadStats(k *Tx, threadID uuid.UUID) error {
+	if _, err := k.Get(threadKey(threadID)); errors.Is(err, kv.ErrMissingKey) {
+		return nil
+	}
+	thread, err := getThreadTx(k, threadID)
+	if err != nil {
+		return err
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		return err
+	}
+
+	var messages []*models.Message
+	for _, key := range keys {
+		if !bytes.HasPrefix(key, []byte(MessagePrefix)) {
+			continue
+		}
+		data, err := k.Get(key)
+		if err != nil {
+			if errors.Is(err, kv.ErrMissingKey) {
+				continue // Key was deleted between Keys() and Get()
+			}
+			return err
+		}
+		var msg models.Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		if msg.ThreadID == threadID {
+			messages = append(messages, &msg)
+		}
+	}
+
+	thread.RebuildStats(messages)
+	return putThread(k, thread)
+}
+
+// ReindexThreads recomputes the activity fields of every thread from its
+// messages, for data written before the fields existed or after a bad merge.
+// It returns the number of threads whose fields changed.
+func (c *Client) ReindexThreads() (int, error) {
+	changed := 0
+	err := c.Do(func(k *Tx) error {
+		snap, err := readSnapshot(k)
+		if err != nil {
+			return err
+		}
+
+		byThread := make(map[uuid.UUID][]*models.Message)
+		for _, msg := range snap.messages {
+			byThread[msg.ThreadID] = append(byThread[msg.ThreadID], msg)
+		}
+
+		for _, thread := range snap.threads {
+			before := threadStats{thread.LastActivityAt, thread.MessageCount, thread.LastPoster, slices.Clone(thread.Participants)}
+			thread.RebuildStats(byThread[thread.ID])
+			after := threadStats{thread.LastActivityAt, thread.MessageCount, thread.LastPoster, thread.Participants}
+			if before.equal(after) {
+				continue
+			}
+			if err := putThread(k, thread); err != nil {
+				return err
+			}
+			changed++
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// threadStats is a comparable view of a thread's activity fields.
+type threadStats struct {
+	lastActivityAt time.Time
+	messageCount   int
+	lastPoster     string
+	participants   []string
+}
+
+func (a threadStats) equal(b threadStats) bool {
+	return a.lastActivityAt.Equal(b.lastActivityAt) &&
+		a.messageCount == b.messageCount &&
+		a.lastPoster == b.lastPoster &&
+		slices.Equal(a.participants, b.participants)
+}
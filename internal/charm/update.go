@@ -0,0 +1,111 @@
+// ABOUTME: Field-level updates for topics and threads
+// ABOUTME: Each change is recorded on the record with who made it and when
+
+package charm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrEmptyValue is returned when a rename or retitle would leave a required field blank.
+var ErrEmptyValue = newError(ErrInvalidArgument, "value must not be empty")
+
+// RenameTopic changes a topic's name and slug, keeping names unique.
+func (c *Client) RenameTopic(id uuid.UUID, name, by string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("topic name: %w", ErrEmptyValue)
+	}
+	return c.Do(func(k *Tx) error {
+		topic, err := getTopicTx(k, id)
+		if err != nil {
+			return err
+		}
+		if topic.Name == name {
+			return nil
+		}
+
+		topics, err := readTopics(k)
+		if err != nil {
+			return err
+		}
+		for _, other := range topics {
+			other.EnsureSlug()
+		}
+
+		oldName := topic.Name
+		topic.Name = name
+		topic.Slug = ""
+		topic.EnsureSlug()
+		if err := checkTopicUnique(topics, topic); err != nil {
+			return err
+		}
+
+		topic.RecordChange("Name", oldName, name, by)
+		return putTopic(k, topic)
+	})
+}
+
+// DescribeTopic replaces a topic's description.
+func (c *Client) DescribeTopic(id uuid.UUID, description, by string) error {
+	return c.Do(func(k *Tx) error {
+		topic, err := getTopicTx(k, id)
+		if err != nil {
+			return err
+		}
+		if topic.Description == description {
+			return nil
+		}
+		topic.RecordChange("Description", topic.Description, description, by)
+		topic.Description = description
+		return putTopic(k, topic)
+	})
+}
+
+// RetitleThread changes a thread's subject.
+func (c *Client) RetitleThread(id uuid.UUID, subject, by string) error {
+	subject = strings.TrimSpace(subject)
+	if subject == "" {
+		return fmt.Errorf("thread subject: %w", ErrEmptyValue)
+	}
+	return c.Do(func(k *Tx) error {
+		thread, err := getThreadTx(k, id)
+		if err != nil {
+			return err
+		}
+		if thread.Subject == subject {
+			return nil
+		}
+		thread.RecordChange("Subject", thread.Subject, subject, by)
+		thread.Subject = subject
+		return putThread(k, thread)
+	})
+}
+
+// MoveThread moves a thread, and with it all of its messages, to another topic.
+// Like CreateThread, it fails with ErrTopicReadOnly when the destination only
+// accepts new threads from moderators.
+func (c *Client) MoveThread(id, topicID uuid.UUID, by string) error {
+	return c.Do(func(k *Tx) error {
+		thread, err := getThreadTx(k, id)
+		if err != nil {
+			return err
+		}
+		if thread.TopicID == topicID {
+			return nil
+		}
+		topic, err := getTopicTx(k, topicID)
+		if err != nil {
+			return err
+		}
+		if topic.ReadOnly && !c.IsModerator(by) {
+			return fmt.Errorf("%w: %s", ErrTopicReadOnly, topic.Name)
+		}
+		thread.RecordChange("TopicID", thread.TopicID.String(), topicID.String(), by)
+		thread.TopicID = topicID
+		return putThread(k, thread)
+	})
+}
@@ -1,52 +1,258 @@
 // ABOUTME: BBS configuration management
-// ABOUTME: Handles Charm server settings and preferences
+// ABOUTME: Layers defaults, the config file, board profiles and environment variables
 
 package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
 )
 
 // DefaultCharmHost is the default Charm server
 const DefaultCharmHost = "charm.2389.dev"
 
-// Config stores BBS configuration
+// DefaultBoard is the board used when none is selected or configured.
+const DefaultBoard = "default"
+
+// DefaultBoardDB is the database of the default board, the one bbs has
+// always used.
+const DefaultBoardDB = "bbs"
+
+// Built-in defaults for settings not in the config file or environment.
+const (
+	DefaultSyncInterval    = time.Minute
+	DefaultMCPTransport    = "stdio"
+	DefaultMCPListen       = "127.0.0.1:8080"
+	DefaultMCPPollInterval = 5 * time.Second
+	DefaultTUISyncRefresh  = 15 * time.Second
+)
+
+// Config stores BBS configuration. Read from the file, unset fields are
+// empty; Resolve fills them from the other layers.
 type Config struct {
 	// CharmHost is the Charm server URL (default: charm.2389.dev)
 	CharmHost string `json:"charm_host,omitempty"`
+
+	// Identity is the default username for posts (default: $USER)
+	Identity string `json:"identity,omitempty"`
+
+	// AutoSync enables automatic sync after writes (default: true)
+	AutoSync *bool `json:"auto_sync,omitempty"`
+
+	// StaleThreshold is the maximum age before a read syncs first (default: 1 hour)
+	StaleThreshold Duration `json:"stale_threshold,omitempty"`
+
+	// SyncInterval is how often 'bbs sync daemon' syncs (default: 1 minute)
+	SyncInterval Duration `json:"sync_interval,omitempty"`
+
+	// Moderators lists usernames allowed to lock threads and make topics read-only
+	Moderators []string `json:"moderators,omitempty"`
+
+	// MCP holds defaults for 'bbs mcp'
+	MCP MCPConfig `json:"mcp,omitzero"`
+
+	// TUI holds settings for the terminal UI
+	TUI TUIConfig `json:"tui,omitzero"`
+
+	// Boards are named profiles, each with its own board database
+	Boards map[string]Board `json:"boards,omitempty"`
+
+	// CurrentBoard is the board used without --board (default: "default")
+	CurrentBoard string `json:"current_board,omitempty"`
+}
+
+// MCPConfig holds defaults for the MCP server's flags.
+type MCPConfig struct {
+	// Transport is stdio or http (default: stdio)
+	Transport string `json:"transport,omitempty"`
+
+	// Listen is the address for the http transport (default: 127.0.0.1:8080)
+	Listen string `json:"listen,omitempty"`
+
+	// PollInterval is how often subscribed resources are checked (default: 5 seconds)
+	PollInterval Duration `json:"poll_interval,omitempty"`
+}
+
+// TUIConfig holds settings for the terminal UI.
+type TUIConfig struct {
+	// SyncRefresh is how often the status bar rereads sync health (default: 15 seconds)
+	SyncRefresh Duration `json:"sync_refresh,omitempty"`
+}
+
+// Board is a named profile: which board database to use and how to reach it.
+// Empty fields fall back to the top-level settings.
+type Board struct {
+	// DB is the Charm KV database holding the board
+	DB string `json:"db"`
+
+	// CharmHost overrides the Charm server for this board
+	CharmHost string `json:"charm_host,omitempty"`
+
+	// Identity is the default username for posts on this board
+	Identity string `json:"identity,omitempty"`
+
+	// AutoSync overrides auto-sync for this board
+	AutoSync *bool `json:"auto_sync,omitempty"`
+}
+
+// Duration is a time.Duration written as a string such as "5m". It also
+// reads the nanosecond counts older config files stored.
+type Duration time.Duration
+
+// MarshalJSON writes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON reads a duration string or a count of nanoseconds.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var ns int64
+	if err := json.Unmarshal(data, &ns); err == nil {
+		*d = Duration(ns)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5m\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// boardNamePattern keeps board names safe to use in database file names.
+var boardNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,39}$`)
+
+// ValidateBoardName checks that name can name a board and its database.
+func ValidateBoardName(name string) error {
+	if !boardNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid board name %q: use up to 40 lowercase letters, digits, '-' or '_'", name)
+	}
+	return nil
+}
+
+// Board returns the named board, or the current one when name is empty.
+// The default board exists even when not configured.
+func (c *Config) Board(name string) (string, Board, error) {
+	if name == "" {
+		name = c.CurrentBoard
+	}
+	if name == "" {
+		name = DefaultBoard
+	}
+	b, ok := c.Boards[name]
+	if !ok && name != DefaultBoard {
+		return "", Board{}, fmt.Errorf("unknown board %q (see 'bbs board list')", name)
+	}
+	if b.DB == "" {
+		b.DB = DefaultBoardDB
+		if name != DefaultBoard {
+			b.DB = DefaultBoardDB + "-" + name
+		}
+	}
+	if b.CharmHost == "" {
+		b.CharmHost = c.CharmHost
+	}
+	return name, b, nil
+}
+
+// BoardNames returns the configured boards and the default board, sorted.
+func (c *Config) BoardNames() []string {
+	names := []string{DefaultBoard}
+	for name := range c.Boards {
+		if name != DefaultBoard {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddBoard adds a board profile. Its database defaults to bbs-<name>.
+func (c *Config) AddBoard(name string, b Board) error {
+	if err := ValidateBoardName(name); err != nil {
+		return err
+	}
+	if _, ok := c.Boards[name]; ok || name == DefaultBoard {
+		return fmt.Errorf("board %q already exists", name)
+	}
+	if b.DB == "" {
+		b.DB = DefaultBoardDB + "-" + name
+	} else if err := ValidateBoardName(b.DB); err != nil {
+		return fmt.Errorf("invalid database name %q", b.DB)
+	}
+	if c.Boards == nil {
+		c.Boards = make(map[string]Board)
+	}
+	c.Boards[name] = b
+	return nil
+}
+
+// UseBoard makes name the current board.
+func (c *Config) UseBoard(name string) error {
+	if _, _, err := c.Board(name); err != nil {
+		return err
+	}
+	c.CurrentBoard = name
+	if name == DefaultBoard {
+		c.CurrentBoard = ""
+	}
+	return nil
 }
 
 // GetConfigPath returns the config file path
 func GetConfigPath() string {
+	return filepath.Join(configDir(), "config.json")
+}
+
+// legacyConfigPath is the charm.json that held sync settings before they
+// moved into config.json.
+func legacyConfigPath() string {
+	return filepath.Join(configDir(), "charm.json")
+}
+
+func configDir() string {
 	configDir := os.Getenv("XDG_CONFIG_HOME")
 	if configDir == "" {
 		homeDir, _ := os.UserHomeDir()
 		configDir = filepath.Join(homeDir, ".config")
 	}
-	return filepath.Join(configDir, "bbs", "config.json")
+	return filepath.Join(configDir, "bbs")
 }
 
-// Load reads config from disk
+// Load reads the config file. Settings still in a legacy charm.json are
+// read first, so config.json overrides them; Save folds them in.
 func Load() (*Config, error) {
-	path := GetConfigPath()
-	data, err := os.ReadFile(path)
-	if err != nil {
+	var cfg Config
+	for _, path := range []string{legacyConfigPath(), GetConfigPath()} {
+		data, err := os.ReadFile(path)
 		if os.IsNotExist(err) {
-			return &Config{}, nil
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
 		}
-		return nil, err
-	}
-
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, err
 	}
 	return &cfg, nil
 }
 
-// Save writes config to disk
+// Save writes config to disk, retiring a legacy charm.json whose settings
+// Load merged in.
 func (c *Config) Save() error {
 	path := GetConfigPath()
 	dir := filepath.Dir(path)
@@ -58,28 +264,147 @@ func (c *Config) Save() error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0600)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	legacy := legacyConfigPath()
+	if err := os.Rename(legacy, legacy+".bak"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Defaults returns the built-in settings, the lowest layer.
+func Defaults() *Config {
+	autoSync := true
+	return &Config{
+		CharmHost:      DefaultCharmHost,
+		AutoSync:       &autoSync,
+		StaleThreshold: Duration(kv.DefaultStaleThreshold),
+		SyncInterval:   Duration(DefaultSyncInterval),
+		MCP: MCPConfig{
+			Transport:    DefaultMCPTransport,
+			Listen:       DefaultMCPListen,
+			PollInterval: Duration(DefaultMCPPollInterval),
+		},
+		TUI: TUIConfig{SyncRefresh: Duration(DefaultTUISyncRefresh)},
+	}
+}
+
+// Resolved is the configuration a command runs with.
+type Resolved struct {
+	// Config holds the effective value of every setting
+	Config
+
+	// BoardName and DB identify the selected board
+	BoardName string
+	DB        string
+}
+
+// Resolve layers the settings for one command: built-in defaults, then the
+// config file with the selected board's profile over it, then environment
+// variables read with getenv. Flags are the caller's to apply last. board
+// picks the board, as --board does; empty means BBS_BOARD, then the
+// current board.
+func Resolve(file *Config, board string, getenv func(string) string) (*Resolved, error) {
+	if board == "" {
+		board = getenv("BBS_BOARD")
+	}
+	name, b, err := file.Board(board)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Resolved{Config: *Defaults(), BoardName: name, DB: b.DB}
+	r.merge(file)
+	r.merge(&Config{CharmHost: b.CharmHost, Identity: b.Identity, AutoSync: b.AutoSync})
+	r.Boards = file.Boards
+	r.CurrentBoard = name
+
+	env, err := fromEnv(getenv)
+	if err != nil {
+		return nil, err
+	}
+	r.merge(env)
+	return r, nil
+}
+
+// merge copies the settings set in over onto c. Boards and the current
+// board are left to the caller.
+func (c *Config) merge(over *Config) {
+	if over.CharmHost != "" {
+		c.CharmHost = over.CharmHost
+	}
+	if over.Identity != "" {
+		c.Identity = over.Identity
+	}
+	if over.AutoSync != nil {
+		v := *over.AutoSync
+		c.AutoSync = &v
+	}
+	if over.StaleThreshold != 0 {
+		c.StaleThreshold = over.StaleThreshold
+	}
+	if over.SyncInterval != 0 {
+		c.SyncInterval = over.SyncInterval
+	}
+	if over.Moderators != nil {
+		c.Moderators = over.Moderators
+	}
+	if over.MCP.Transport != "" {
+		c.MCP.Transport = over.MCP.Transport
+	}
+	if over.MCP.Listen != "" {
+		c.MCP.Listen = over.MCP.Listen
+	}
+	if over.MCP.PollInterval != 0 {
+		c.MCP.PollInterval = over.MCP.PollInterval
+	}
+	if over.TUI.SyncRefresh != 0 {
+		c.TUI.SyncRefresh = over.TUI.SyncRefresh
+	}
 }
 
-// GetCharmHost returns the Charm host, preferring environment variable.
-func (c *Config) GetCharmHost() string {
-	// Environment variable takes precedence
-	if host := os.Getenv("CHARM_HOST"); host != "" {
-		return host
+// fromEnv reads the environment layer: each setting's variable in Keys.
+func fromEnv(getenv func(string) string) (*Config, error) {
+	cfg := &Config{}
+	for _, k := range Keys {
+		if k.Env == "" || k.Env == "BBS_BOARD" {
+			continue
+		}
+		value := getenv(k.Env)
+		if value == "" {
+			continue
+		}
+		if err := k.set(cfg, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", k.Env, err)
+		}
 	}
-	// Then config file
-	if c.CharmHost != "" {
-		return c.CharmHost
+	return cfg, nil
+}
+
+// AutoSyncEnabled reports whether writes sync automatically; unset means on.
+func (c *Config) AutoSyncEnabled() bool {
+	return c.AutoSync == nil || *c.AutoSync
+}
+
+// parseBool accepts the forms strconv.ParseBool does, with a clearer error.
+func parseBool(value string) (bool, error) {
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean %q: use true or false", value)
 	}
-	// Then default
-	return DefaultCharmHost
+	return v, nil
 }
 
-// ApplyEnvironment sets environment variables from config.
-// Call this before initializing Charm client.
-func (c *Config) ApplyEnvironment() {
-	if os.Getenv("CHARM_HOST") == "" {
-		host := c.GetCharmHost()
-		os.Setenv("CHARM_HOST", host)
+// parseDuration parses a positive duration such as "90s" or "5m".
+func parseDuration(value string) (Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: use a number with a unit, like 30s or 5m", value)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q: must be positive", value)
 	}
+	return Duration(d), nil
 }
@@ -6,7 +6,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetConfigPath(t *testing.T) {
@@ -55,40 +57,200 @@ func TestSaveAndLoad(t *testing.T) {
 	}
 }
 
-func TestGetCharmHost(t *testing.T) {
-	tests := []struct {
-		name        string
-		cfg         Config
-		envHost     string
-		expectedLen int // Just check non-empty since default varies
-	}{
-		{"empty config uses default", Config{}, "", 1},
-		{"config CharmHost used", Config{CharmHost: "custom.example.com"}, "", 1},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.envHost != "" {
-				t.Setenv("CHARM_HOST", tt.envHost)
-			}
-			host := tt.cfg.GetCharmHost()
-			if len(host) < tt.expectedLen {
-				t.Errorf("GetCharmHost() returned empty or short string")
-			}
-		})
+func TestResolve(t *testing.T) {
+	off := false
+	file := &Config{
+		CharmHost:      "file.example.com",
+		StaleThreshold: Duration(10 * time.Minute),
+		Moderators:     []string{"harper"},
+		Boards: map[string]Board{
+			"sandbox": {DB: "bbs-sandbox", CharmHost: "board.example.com", Identity: "agent", AutoSync: &off},
+		},
+	}
+	env := map[string]string{}
+	getenv := func(name string) string { return env[name] }
+
+	r, err := Resolve(file, "", getenv)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if r.BoardName != DefaultBoard || r.DB != DefaultBoardDB || r.CharmHost != "file.example.com" || !r.AutoSyncEnabled() {
+		t.Errorf("default board = %+v", r)
+	}
+	if time.Duration(r.StaleThreshold) != 10*time.Minute || time.Duration(r.SyncInterval) != DefaultSyncInterval || r.MCP.Transport != DefaultMCPTransport {
+		t.Errorf("file and defaults not layered: %+v", r.Config)
+	}
+
+	r, err = Resolve(file, "sandbox", getenv)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if r.DB != "bbs-sandbox" || r.CharmHost != "board.example.com" || r.Identity != "agent" || r.AutoSyncEnabled() {
+		t.Errorf("board profile should override the file: %+v", r)
+	}
+	if got := r.Source(mustKey(t, "charm_host"), file, getenv); got != "board" {
+		t.Errorf("charm_host source = %q, want board", got)
+	}
+
+	env["BBS_BOARD"] = "sandbox"
+	env["CHARM_HOST"] = "env.example.com"
+	env["BBS_SYNC_INTERVAL"] = "30s"
+	r, err = Resolve(file, "", getenv)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if r.BoardName != "sandbox" || r.CharmHost != "env.example.com" || time.Duration(r.SyncInterval) != 30*time.Second {
+		t.Errorf("environment should override the file and board: %+v", r)
+	}
+	if got := r.Source(mustKey(t, "charm_host"), file, getenv); got != "env" {
+		t.Errorf("charm_host source = %q, want env", got)
+	}
+	if got := r.Source(mustKey(t, "mcp.listen"), file, getenv); got != "default" {
+		t.Errorf("mcp.listen source = %q, want default", got)
+	}
+
+	env["BBS_AUTO_SYNC"] = "sometimes"
+	if _, err := Resolve(file, "", getenv); err == nil || !strings.Contains(err.Error(), "BBS_AUTO_SYNC") {
+		t.Errorf("an invalid environment value should name the variable, got %v", err)
+	}
+	if _, err := Resolve(file, "nope", getenv); err == nil {
+		t.Error("an unknown board should be an error")
 	}
 }
 
-func TestApplyEnvironment(t *testing.T) {
-	cfg := &Config{CharmHost: "custom.example.com"}
+func TestLoadLegacyCharmConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	legacy := filepath.Join(filepath.Dir(GetConfigPath()), "charm.json")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0750); err != nil {
+		t.Fatal(err)
+	}
+	// charm.json stored durations as nanoseconds
+	data := `{"charm_host":"legacy.example.com","auto_sync":false,"stale_threshold":300000000000,"moderators":["harper"]}`
+	if err := os.WriteFile(legacy, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Config{CharmHost: "new.example.com"}).Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(legacy+".bak", legacy); err != nil {
+		t.Fatal(err)
+	}
 
-	// Clear any existing CHARM_HOST
-	os.Unsetenv("CHARM_HOST")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.CharmHost != "new.example.com" || cfg.AutoSyncEnabled() || time.Duration(cfg.StaleThreshold) != 5*time.Minute || len(cfg.Moderators) != 1 {
+		t.Errorf("legacy settings not merged under config.json: %+v", cfg)
+	}
 
-	cfg.ApplyEnvironment()
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Error("Save should retire charm.json")
+	}
+	cfg, err = Load()
+	if err != nil || cfg.AutoSyncEnabled() || time.Duration(cfg.StaleThreshold) != 5*time.Minute {
+		t.Errorf("legacy settings lost after Save: %+v, %v", cfg, err)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cfg := &Config{Boards: map[string]Board{"sandbox": {DB: "bbs-sandbox"}}}
+	set := []struct{ key, value, want string }{
+		{"charm_host", "charm.example.com", "charm.example.com"},
+		{"identity", "harper", "harper"},
+		{"board", "sandbox", "sandbox"},
+		{"auto_sync", "false", "false"},
+		{"stale_threshold", "90s", "1m30s"},
+		{"moderators", "harper, dylan,", "harper,dylan"},
+		{"mcp.transport", "http", "http"},
+		{"mcp.listen", "127.0.0.1:9000", "127.0.0.1:9000"},
+		{"tui.sync_refresh", "1m", "1m0s"},
+	}
+	for _, tt := range set {
+		k := mustKey(t, tt.key)
+		if err := k.Set(cfg, tt.value); err != nil {
+			t.Errorf("Set(%s, %q): %v", tt.key, tt.value, err)
+		}
+		if got := k.Get(cfg); got != tt.want {
+			t.Errorf("Get(%s) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	invalid := []struct{ key, value string }{
+		{"charm_host", "https://charm.example.com"},
+		{"identity", "harper@cli"},
+		{"board", "nope"},
+		{"auto_sync", "maybe"},
+		{"stale_threshold", "5"},
+		{"sync_interval", "-1m"},
+		{"mcp.transport", "grpc"},
+		{"mcp.listen", "8080"},
+	}
+	for _, tt := range invalid {
+		if err := mustKey(t, tt.key).Set(cfg, tt.value); err == nil || !strings.HasPrefix(err.Error(), tt.key+": ") {
+			t.Errorf("Set(%s, %q) = %v, want an error naming the key", tt.key, tt.value, err)
+		}
+	}
 
-	// After ApplyEnvironment, CHARM_HOST should be set
-	if os.Getenv("CHARM_HOST") != "custom.example.com" {
-		t.Errorf("ApplyEnvironment did not set CHARM_HOST correctly")
+	k := mustKey(t, "stale_threshold")
+	if err := k.Set(cfg, ""); err != nil || k.Get(cfg) != "" {
+		t.Errorf("an empty value should unset the key: %q, %v", k.Get(cfg), err)
+	}
+	if _, err := LookupKey("colour"); err == nil {
+		t.Error("LookupKey should reject unknown keys")
+	}
+}
+
+func mustKey(t *testing.T, name string) Key {
+	t.Helper()
+	k, err := LookupKey(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestBoards(t *testing.T) {
+	cfg := &Config{CharmHost: "charm.example.com"}
+
+	name, b, err := cfg.Board("")
+	if err != nil || name != DefaultBoard || b.DB != DefaultBoardDB || b.CharmHost != "charm.example.com" {
+		t.Errorf("unconfigured board = %q %+v, %v; want the default board", name, b, err)
+	}
+	if _, _, err := cfg.Board("sandbox"); err == nil {
+		t.Error("an unknown board should be an error")
+	}
+
+	if err := cfg.AddBoard("sandbox", Board{Identity: "agent", CharmHost: "localhost"}); err != nil {
+		t.Fatalf("AddBoard: %v", err)
+	}
+	for _, bad := range []string{"Sandbox", "", "a/b", DefaultBoard, "sandbox"} {
+		if err := cfg.AddBoard(bad, Board{}); err == nil {
+			t.Errorf("AddBoard(%q) should fail", bad)
+		}
+	}
+	if err := cfg.AddBoard("shared", Board{DB: "../etc"}); err == nil {
+		t.Error("a database name must be safe to use as a file name")
+	}
+
+	if err := cfg.UseBoard("sandbox"); err != nil {
+		t.Fatalf("UseBoard: %v", err)
+	}
+	name, b, err = cfg.Board("")
+	if err != nil || name != "sandbox" || b.DB != "bbs-sandbox" || b.CharmHost != "localhost" || b.Identity != "agent" {
+		t.Errorf("current board = %q %+v, %v", name, b, err)
+	}
+	if got := cfg.BoardNames(); len(got) != 2 || got[0] != DefaultBoard || got[1] != "sandbox" {
+		t.Errorf("BoardNames = %v", got)
+	}
+
+	if err := cfg.UseBoard(DefaultBoard); err != nil || cfg.CurrentBoard != "" {
+		t.Errorf("switching back to the default board should clear it: %q, %v", cfg.CurrentBoard, err)
+	}
+	if err := cfg.UseBoard("nope"); err == nil {
+		t.Error("UseBoard should reject unknown boards")
 	}
 }
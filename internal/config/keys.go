@@ -0,0 +1,188 @@
+// ABOUTME: Named settings for 'bbs config get|set|list'
+// ABOUTME: Maps each key to its config field, environment variable and validation
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Key is a setting that can be read and written by name.
+type Key struct {
+	Name string
+	Env  string // environment variable that overrides the file, if any
+	Help string
+
+	get func(c *Config) string              // "" when unset
+	set func(c *Config, value string) error // validates value; "" unsets
+}
+
+// Keys lists every setting in the order 'bbs config list' shows them.
+var Keys = []Key{
+	{
+		Name: "charm_host", Env: "CHARM_HOST", Help: "Charm server for sync",
+		get: func(c *Config) string { return c.CharmHost },
+		set: func(c *Config, v string) error {
+			if v != "" && (strings.Contains(v, "://") || strings.ContainsAny(v, " /")) {
+				return fmt.Errorf("invalid host %q: give a host name such as charm.example.com", v)
+			}
+			c.CharmHost = v
+			return nil
+		},
+	},
+	{
+		Name: "identity", Env: "BBS_USER", Help: "default username for posts",
+		get: func(c *Config) string { return c.Identity },
+		set: func(c *Config, v string) error {
+			if strings.ContainsAny(v, "@ \t") {
+				return fmt.Errorf("invalid identity %q: give a username without '@' or spaces", v)
+			}
+			c.Identity = v
+			return nil
+		},
+	},
+	{
+		Name: "board", Env: "BBS_BOARD", Help: "board used without --board",
+		get: func(c *Config) string { return c.CurrentBoard },
+		set: func(c *Config, v string) error {
+			if v == "" {
+				c.CurrentBoard = ""
+				return nil
+			}
+			return c.UseBoard(v)
+		},
+	},
+	{
+		Name: "auto_sync", Env: "BBS_AUTO_SYNC", Help: "sync writes to the Charm server",
+		get: func(c *Config) string { return formatBool(c.AutoSync) },
+		set: func(c *Config, v string) error {
+			if v == "" {
+				c.AutoSync = nil
+				return nil
+			}
+			b, err := parseBool(v)
+			c.AutoSync = &b
+			return err
+		},
+	},
+	durationKey("stale_threshold", "BBS_STALE_THRESHOLD", "age at which reads sync first",
+		func(c *Config) *Duration { return &c.StaleThreshold }),
+	durationKey("sync_interval", "BBS_SYNC_INTERVAL", "time between syncs in 'bbs sync daemon'",
+		func(c *Config) *Duration { return &c.SyncInterval }),
+	{
+		Name: "moderators", Help: "comma-separated usernames who can moderate",
+		get: func(c *Config) string { return strings.Join(c.Moderators, ",") },
+		set: func(c *Config, v string) error {
+			var names []string
+			for _, name := range strings.Split(v, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					names = append(names, name)
+				}
+			}
+			c.Moderators = names
+			return nil
+		},
+	},
+	{
+		Name: "mcp.transport", Env: "BBS_MCP_TRANSPORT", Help: "MCP server transport: stdio or http",
+		get: func(c *Config) string { return c.MCP.Transport },
+		set: func(c *Config, v string) error {
+			if v != "" && v != "stdio" && v != "http" {
+				return fmt.Errorf("invalid transport %q: use stdio or http", v)
+			}
+			c.MCP.Transport = v
+			return nil
+		},
+	},
+	{
+		Name: "mcp.listen", Env: "BBS_MCP_LISTEN", Help: "listen address for the MCP http transport",
+		get: func(c *Config) string { return c.MCP.Listen },
+		set: func(c *Config, v string) error {
+			if v != "" {
+				if _, _, err := net.SplitHostPort(v); err != nil {
+					return fmt.Errorf("invalid listen address %q: use host:port or :port", v)
+				}
+			}
+			c.MCP.Listen = v
+			return nil
+		},
+	},
+	durationKey("mcp.poll_interval", "BBS_MCP_POLL_INTERVAL", "how often MCP subscriptions are checked",
+		func(c *Config) *Duration { return &c.MCP.PollInterval }),
+	durationKey("tui.sync_refresh", "BBS_TUI_SYNC_REFRESH", "how often the TUI rereads sync health",
+		func(c *Config) *Duration { return &c.TUI.SyncRefresh }),
+}
+
+func durationKey(name, env, help string, field func(*Config) *Duration) Key {
+	return Key{
+		Name: name, Env: env, Help: help,
+		get: func(c *Config) string {
+			if d := *field(c); d != 0 {
+				return time.Duration(d).String()
+			}
+			return ""
+		},
+		set: func(c *Config, v string) error {
+			if v == "" {
+				*field(c) = 0
+				return nil
+			}
+			d, err := parseDuration(v)
+			if err != nil {
+				return err
+			}
+			*field(c) = d
+			return nil
+		},
+	}
+}
+
+func formatBool(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return fmt.Sprint(*b)
+}
+
+// LookupKey returns the setting called name.
+func LookupKey(name string) (Key, error) {
+	for _, k := range Keys {
+		if k.Name == name {
+			return k, nil
+		}
+	}
+	return Key{}, fmt.Errorf("unknown config key %q (see 'bbs config list')", name)
+}
+
+// Get returns the key's value in c, or "" when unset.
+func (k Key) Get(c *Config) string {
+	return k.get(c)
+}
+
+// Set validates value and stores it in c. An empty value unsets the key,
+// so the default applies again.
+func (k Key) Set(c *Config, value string) error {
+	if err := k.set(c, strings.TrimSpace(value)); err != nil {
+		return fmt.Errorf("%s: %w", k.Name, err)
+	}
+	return nil
+}
+
+// Source names the layer the key's value in r comes from: "env", "board",
+// "file" or "default". file is the config as loaded from disk.
+func (r *Resolved) Source(k Key, file *Config, getenv func(string) string) string {
+	if k.Env != "" && getenv(k.Env) != "" {
+		return "env"
+	}
+	if b, ok := file.Boards[r.BoardName]; ok && k.Name != "board" &&
+		k.get(&Config{CharmHost: b.CharmHost, Identity: b.Identity, AutoSync: b.AutoSync}) != "" {
+		return "board"
+	}
+	if k.get(file) != "" {
+		return "file"
+	}
+	return "default"
+}
@@ -0,0 +1,164 @@
+// ABOUTME: MCP argument completion for prompts and resource templates
+// ABOUTME: Suggests topics, threads and template field options from the board
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/harper/bbs/internal/models"
+)
+
+// maxCompletionValues is the most values a completion may return.
+const maxCompletionValues = 100
+
+func (s *Server) handleComplete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	arg := req.Params.Argument
+	var resolved map[string]string
+	if req.Params.Context != nil {
+		resolved = req.Params.Context.Arguments
+	}
+	forResource := req.Params.Ref != nil && req.Params.Ref.Type == "ref/resource"
+
+	var values []string
+	var err error
+	switch arg.Name {
+	case "topic":
+		values, err = s.completeTopics(arg.Value, forResource)
+	case "thread":
+		values, err = s.completeThreads(arg.Value, resolved["topic"])
+	default:
+		if req.Params.Ref != nil && req.Params.Ref.Type == "ref/prompt" {
+			values = s.completeTemplateField(req.Params.Ref.Name, arg.Name, arg.Value)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return completionResult(values), nil
+}
+
+// completeTopics suggests active topics whose name or slug starts with value.
+// Resource URIs get slugs; prompts get the more readable names.
+func (s *Server) completeTopics(value string, slugs bool) ([]string, error) {
+	topics, err := s.listTopics(false)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for _, t := range topics {
+		if !hasPrefixFold(t.Name, value) && !hasPrefixFold(t.Slug, value) && !strings.HasPrefix(t.ID.String(), value) {
+			continue
+		}
+		if slugs {
+			values = append(values, t.Slug)
+		} else {
+			values = append(values, t.Name)
+		}
+	}
+	return values, nil
+}
+
+// completeThreads suggests short IDs of threads whose ID starts with value or
+// whose subject contains it, most recently active first. A topic already
+// chosen for the same prompt narrows the list to that topic.
+func (s *Server) completeThreads(value, topic string) ([]string, error) {
+	var topics []*models.Topic
+	if topic != "" {
+		t, err := s.resolveTopic(topic)
+		if err != nil {
+			return nil, nil
+		}
+		topics = []*models.Topic{t}
+	} else {
+		var err error
+		topics, err = s.listTopics(false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var threads []*models.Thread
+	for _, t := range topics {
+		list, err := s.client.ListThreads(t.ID)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, list...)
+	}
+	models.SortThreadsByActivity(threads)
+	return matchThreads(threads, value), nil
+}
+
+// matchThreads returns the short IDs of threads matching value by ID prefix
+// or by case-insensitive subject substring.
+func matchThreads(threads []*models.Thread, value string) []string {
+	needle := strings.ToLower(value)
+	var values []string
+	for _, t := range threads {
+		id := t.ID.String()
+		if strings.HasPrefix(id, value) || strings.Contains(strings.ToLower(t.Subject), needle) {
+			values = append(values, id[:8])
+		}
+	}
+	return values
+}
+
+// completeTemplateField suggests options for an enum field of a template prompt.
+func (s *Server) completeTemplateField(prompt, field, value string) []string {
+	s.promptsMu.Lock()
+	version, ok := s.templatePrompts[prompt]
+	s.promptsMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	var t models.Template
+	if err := json.Unmarshal([]byte(version), &t); err != nil {
+		return nil
+	}
+	f := t.Field(field)
+	if f == nil {
+		return nil
+	}
+
+	var values []string
+	switch f.Type {
+	case models.FieldEnum:
+		for _, opt := range f.Options {
+			if hasPrefixFold(opt, value) {
+				values = append(values, opt)
+			}
+		}
+	case models.FieldBool:
+		for _, opt := range []string{"true", "false"} {
+			if hasPrefixFold(opt, value) {
+				values = append(values, opt)
+			}
+		}
+	}
+	return values
+}
+
+// completionResult caps values at the protocol limit and reports the total.
+func completionResult(values []string) *mcp.CompleteResult {
+	result := &mcp.CompleteResult{
+		Completion: mcp.CompletionResultDetails{Values: values, Total: len(values)},
+	}
+	if values == nil {
+		result.Completion.Values = []string{}
+	}
+	if len(values) > maxCompletionValues {
+		result.Completion.Values = values[:maxCompletionValues]
+		result.Completion.HasMore = true
+	}
+	return result
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
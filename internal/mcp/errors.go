@@ -0,0 +1,93 @@
+// ABOUTME: Error codes for MCP tool and resource failures
+// ABOUTME: Maps charm error kinds to stable codes agents can act on
+
+package mcp
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/models"
+)
+
+// Stable error codes returned in tool error results.
+const (
+	CodeInvalidArgument = "invalid_argument"
+	CodeNotFound        = "not_found"
+	CodeAmbiguous       = "ambiguous"
+	CodeConflict        = "conflict"
+	CodeForbidden       = "forbidden"
+	CodeSyncFailed      = "sync_failed"
+	CodeInternal        = "internal"
+)
+
+// errInvalidArguments wraps tool arguments that do not decode.
+var errInvalidArguments = errors.New("invalid arguments")
+
+// errorCode returns the stable code for err.
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, charm.ErrNotFound):
+		return CodeNotFound
+	case errors.Is(err, charm.ErrAmbiguous):
+		return CodeAmbiguous
+	case errors.Is(err, charm.ErrConflict):
+		return CodeConflict
+	case errors.Is(err, charm.ErrForbidden):
+		return CodeForbidden
+	case errors.Is(err, charm.ErrSyncFailed):
+		return CodeSyncFailed
+	case errors.Is(err, errInvalidArguments),
+		errors.Is(err, charm.ErrInvalidArgument),
+		errors.Is(err, models.ErrInvalidReaction),
+		errors.Is(err, models.ErrInvalidTemplate),
+		errors.Is(err, models.ErrInvalidFields):
+		return CodeInvalidArgument
+	}
+	return CodeInternal
+}
+
+// toolErrorDetail is the structured content of a failed tool call.
+// Candidates lists the matches when an ID prefix was ambiguous, so the
+// call can be retried with one of the full IDs.
+type toolErrorDetail struct {
+	Code       string            `json:"code"`
+	Message    string            `json:"message"`
+	Candidates []charm.Candidate `json:"candidates,omitempty"`
+}
+
+type toolErrorOutput struct {
+	Error toolErrorDetail `json:"error"`
+}
+
+// toolError reports err as a failed tool call with a stable code.
+func toolError(err error) *mcp.CallToolResult {
+	out := toolErrorOutput{Error: toolErrorDetail{Code: errorCode(err), Message: err.Error()}}
+	var amb *charm.AmbiguousError
+	if errors.As(err, &amb) {
+		out.Error.Candidates = amb.Candidates
+	}
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("%s: %s", out.Error.Code, out.Error.Message)}},
+		StructuredContent: out,
+		IsError:           true,
+	}
+}
+
+// invalidArguments reports tool arguments that failed to decode.
+func invalidArguments(err error) *mcp.CallToolResult {
+	return toolError(fmt.Errorf("%w: %v", errInvalidArguments, err))
+}
+
+// resourceError converts err into the error a resource read returns:
+// the protocol's resource-not-found error for missing records, and the
+// message prefixed with its code otherwise.
+func resourceError(uri string, err error) error {
+	if errors.Is(err, charm.ErrNotFound) {
+		return mcp.ResourceNotFoundError(uri)
+	}
+	return fmt.Errorf("%s: %w", errorCode(err), err)
+}
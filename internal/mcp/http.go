@@ -0,0 +1,214 @@
+// ABOUTME: Streamable HTTP and SSE transports for the MCP server
+// ABOUTME: Serves many agent sessions from one process with bearer-token auth
+
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/identity"
+)
+
+// DefaultTokenName is the identity used for tokens given without a name.
+const DefaultTokenName = "agent"
+
+// tokenNameKey is the TokenInfo.Extra key holding the token's identity name.
+const tokenNameKey = "name"
+
+// HTTPOptions configures the HTTP transport.
+type HTTPOptions struct {
+	// Addr is the listen address, e.g. "127.0.0.1:8080". Addresses other
+	// than loopback require Tokens.
+	Addr string
+	// Tokens maps bearer secrets to the identity name they authenticate.
+	// An empty map disables authentication.
+	Tokens map[string]string
+}
+
+// ParseTokens parses token specs of the form "name=secret" or "secret".
+// Unnamed tokens authenticate as DefaultTokenName.
+func ParseTokens(specs []string) (map[string]string, error) {
+	tokens := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		name, secret, ok := strings.Cut(spec, "=")
+		if !ok {
+			name, secret = DefaultTokenName, spec
+		}
+		name, secret = strings.TrimSpace(name), strings.TrimSpace(secret)
+		if name == "" || secret == "" {
+			return nil, fmt.Errorf("invalid token %q: use name=secret or secret", spec)
+		}
+		if strings.Contains(name, "@") {
+			return nil, fmt.Errorf("invalid token name %q: must not contain @", name)
+		}
+		if _, dup := tokens[secret]; dup {
+			return nil, fmt.Errorf("duplicate token secret for %q", name)
+		}
+		tokens[secret] = name
+	}
+	return tokens, nil
+}
+
+// ReadTokenFile reads token specs from a file, one per line. Blank lines
+// and lines starting with # are skipped.
+func ReadTokenFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read token file: %w", err)
+	}
+	var specs []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			specs = append(specs, line)
+		}
+	}
+	return specs, nil
+}
+
+// isLoopback reports whether a listen address only accepts connections
+// from this machine. An empty host listens on every interface.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// tokenVerifier checks bearer tokens against a fixed set of secrets.
+func tokenVerifier(tokens map[string]string) auth.TokenVerifier {
+	return func(ctx context.Context, token string, req *http.Request) (*auth.TokenInfo, error) {
+		for secret, name := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1 {
+				return &auth.TokenInfo{
+					// Static tokens do not expire; the SDK requires a non-zero expiration.
+					Expiration: time.Now().Add(24 * time.Hour),
+					Extra:      map[string]any{tokenNameKey: name},
+				}, nil
+			}
+		}
+		return nil, auth.ErrInvalidToken
+	}
+}
+
+// agentIdentity returns the identity a request acts as. A session that
+// authenticated with a named token always acts as that name. Otherwise the
+// agent_name argument is used, or the session's own identity: over stdio
+// that is BBS_USER or the server's default agent; each HTTP session without
+// a named token gets its own, DefaultTokenName plus a suffix of its session
+// ID, so clients do not share read marks and feed cursors.
+//
+// Moderator rights go to the token's name or the server's own identity,
+// never to a name the client merely claims: agent_name may not name a
+// moderator other than the session's own identity.
+func (s *Server) agentIdentity(req mcp.Request, agentName string) (string, error) {
+	var extra *mcp.RequestExtra
+	if req != nil {
+		extra = req.GetExtra()
+	}
+	if extra != nil && extra.TokenInfo != nil {
+		if name, ok := extra.TokenInfo.Extra[tokenNameKey].(string); ok && name != "" && name != DefaultTokenName {
+			return identity.GetIdentity(name, "mcp"), nil
+		}
+	}
+	own := sessionName(req)
+	if own == "" {
+		own = os.Getenv("BBS_USER")
+	}
+	if own == "" {
+		own = s.defaultAgent
+	}
+	if agentName == "" {
+		return identity.GetIdentity(own, "mcp"), nil
+	}
+	if s.client.IsModerator(agentName) && !strings.EqualFold(agentName, own) {
+		return "", fmt.Errorf("%w: agent_name %q is a moderator; connect with that moderator's named token to act as them", charm.ErrForbidden, agentName)
+	}
+	return identity.GetIdentity(agentName, "mcp"), nil
+}
+
+// sessionName returns the per-session name for an HTTP session, or "" for
+// stdio, whose connection has no session ID.
+func sessionName(req mcp.Request) string {
+	if req == nil {
+		return ""
+	}
+	ss, ok := req.GetSession().(*mcp.ServerSession)
+	if !ok || ss == nil {
+		return ""
+	}
+	id := ss.ID()
+	if id == "" {
+		return ""
+	}
+	return DefaultTokenName + "-" + id[:min(len(id), 8)]
+}
+
+// Handler returns an http.Handler serving streamable HTTP at /mcp and the
+// older SSE transport at /sse, behind bearer-token auth when tokens are set.
+func (s *Server) Handler(tokens map[string]string) http.Handler {
+	getServer := func(*http.Request) *mcp.Server { return s.mcp }
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(getServer, nil))
+	mux.Handle("/sse", mcp.NewSSEHandler(getServer, nil))
+
+	if len(tokens) == 0 {
+		return mux
+	}
+	return auth.RequireBearerToken(tokenVerifier(tokens), nil)(mux)
+}
+
+// ListenAndServe serves MCP over HTTP until ctx is cancelled.
+func (s *Server) ListenAndServe(ctx context.Context, opts HTTPOptions) error {
+	if len(opts.Tokens) == 0 && !isLoopback(opts.Addr) {
+		return fmt.Errorf("refusing to serve %s without tokens: give a token or listen on a loopback address such as 127.0.0.1:8080", opts.Addr)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.start(ctx)
+
+	srv := &http.Server{
+		Addr:              opts.Addr,
+		Handler:           s.Handler(opts.Tokens),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe()
+	}()
+	log.Printf("bbs MCP server listening on %s (streamable HTTP at /mcp, SSE at /sse)", opts.Addr)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, done := context.WithTimeout(context.Background(), 5*time.Second)
+		defer done()
+		if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,313 @@
+// ABOUTME: Structured tool results and their output schemas
+// ABOUTME: Every tool returns full records as structuredContent plus a short text summary
+
+package mcp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/harper/bbs/internal/models"
+)
+
+type topicsOutput struct {
+	Topics []*models.Topic `json:"topics"`
+}
+
+type topicOutput struct {
+	Topic *models.Topic `json:"topic"`
+}
+
+type threadsOutput struct {
+	Topic   *models.Topic    `json:"topic"`
+	Threads []*models.Thread `json:"threads"`
+}
+
+// threadOutput is a thread after a change. Message is the opening post
+// when create_thread was given one.
+type threadOutput struct {
+	Thread  *models.Thread  `json:"thread"`
+	Message *models.Message `json:"message,omitempty"`
+}
+
+type messagesOutput struct {
+	Thread   *models.Thread    `json:"thread"`
+	Messages []*models.Message `json:"messages"`
+}
+
+type messageOutput struct {
+	Message *models.Message `json:"message"`
+}
+
+type reactionsOutput struct {
+	MessageID uuid.UUID              `json:"message_id"`
+	Reactions []models.ReactionCount `json:"reactions"`
+}
+
+// reactionOutput reports one reaction change and the message's reactions afterwards.
+type reactionOutput struct {
+	MessageID uuid.UUID              `json:"message_id"`
+	Reaction  string                 `json:"reaction"`
+	Removed   bool                   `json:"removed"`
+	Reactions []models.ReactionCount `json:"reactions"`
+}
+
+type mentionsOutput struct {
+	Identity   string            `json:"identity"`
+	Mentions   []*models.Mention `json:"mentions"`
+	MarkedRead bool              `json:"marked_read"`
+}
+
+type subscriptionOutput struct {
+	Identity string    `json:"identity"`
+	Kind     string    `json:"kind"`
+	TargetID uuid.UUID `json:"target_id"`
+	Name     string    `json:"name"`
+	Watching bool      `json:"watching"`
+}
+
+type feedOutput struct {
+	Identity      string             `json:"identity"`
+	Since         time.Time          `json:"since"`
+	Subscriptions int                `json:"subscriptions"`
+	Items         []*models.Activity `json:"items"`
+}
+
+type activityOutput struct {
+	Items []*models.Activity `json:"items"`
+}
+
+type templatesOutput struct {
+	Templates []*models.Template `json:"templates"`
+}
+
+type templatePostsOutput struct {
+	Template string            `json:"template"`
+	Messages []*models.Message `json:"messages"`
+}
+
+// batchResult is the record one batch operation created or changed.
+type batchResult struct {
+	Op      string          `json:"op"`
+	Topic   *models.Topic   `json:"topic,omitempty"`
+	Thread  *models.Thread  `json:"thread,omitempty"`
+	Message *models.Message `json:"message,omitempty"`
+}
+
+type batchOutput struct {
+	Results []batchResult `json:"results"`
+}
+
+// schemaTypes overrides how For describes types that marshal differently
+// from their Go shape.
+var schemaTypes = map[reflect.Type]*jsonschema.Schema{
+	reflect.TypeFor[uuid.UUID](): {Type: "string", Format: "uuid"},
+}
+
+// outputSchema describes the JSON a tool returns as structured content.
+// It panics on types For cannot describe, which is a programming error
+// caught as soon as the tools are registered.
+func outputSchema[T any]() *jsonschema.Schema {
+	schema, err := jsonschema.For[T](&jsonschema.ForOptions{TypeSchemas: schemaTypes})
+	if err != nil {
+		panic(fmt.Sprintf("output schema for %T: %v", *new(T), err))
+	}
+	allowNull(schema, reflect.TypeFor[T]())
+	return schema
+}
+
+// allowNull lets pointer, slice and map fields be null, since nil values
+// marshal that way and For describes only the non-nil shape.
+func allowNull(s *jsonschema.Schema, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "" {
+				name = f.Name
+			}
+			p := s.Properties[name]
+			if !f.IsExported() || p == nil {
+				continue
+			}
+			switch f.Type.Kind() {
+			case reflect.Pointer, reflect.Slice, reflect.Map:
+				if p.Type != "" {
+					p.Types = []string{"null", p.Type}
+					p.Type = ""
+				}
+			}
+			allowNull(p, f.Type)
+		}
+	case reflect.Slice:
+		if s.Items != nil {
+			allowNull(s.Items, t.Elem())
+		}
+	case reflect.Map:
+		if s.AdditionalProperties != nil {
+			allowNull(s.AdditionalProperties, t.Elem())
+		}
+	}
+}
+
+// toolResult returns out as structured content with a short text summary.
+func toolResult(text string, out any) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+		StructuredContent: out,
+	}
+}
+
+// count formats n with a singular or plural noun.
+func count(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func topicsText(topics []*models.Topic) string {
+	var sb strings.Builder
+	sb.WriteString(count(len(topics), "topic"))
+	for _, t := range topics {
+		sb.WriteString(fmt.Sprintf("\n- %s (%s) · ID %s", t.Name, t.Slug, t.ID))
+		if t.Archived {
+			sb.WriteString(" · archived")
+		}
+		if t.ReadOnly {
+			sb.WriteString(" · read-only")
+		}
+	}
+	return sb.String()
+}
+
+func threadsText(topic *models.Topic, threads []*models.Thread) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s in %s", count(len(threads), "thread"), topic.Name))
+	for _, t := range threads {
+		sb.WriteString(fmt.Sprintf("\n- %s · %s", t.Subject, count(t.MessageCount, "post")))
+		if last := t.LastActive(); !last.IsZero() {
+			sb.WriteString(" · last activity " + last.Format("2006-01-02 15:04"))
+		}
+		if t.Sticky {
+			sb.WriteString(" · pinned")
+		}
+		if t.Locked {
+			sb.WriteString(" · locked")
+		}
+		sb.WriteString(fmt.Sprintf(" · ID %s", t.ID))
+	}
+	return sb.String()
+}
+
+func reactionsText(counts []models.ReactionCount) string {
+	if len(counts) == 0 {
+		return "No reactions"
+	}
+	parts := make([]string, 0, len(counts))
+	for _, rc := range counts {
+		parts = append(parts, fmt.Sprintf("%s %d (%s)", rc.Reaction, rc.Count, strings.Join(rc.By, ", ")))
+	}
+	return strings.Join(parts, "\n")
+}
+
+func mentionsText(mentions []*models.Mention) string {
+	var sb strings.Builder
+	sb.WriteString(count(len(mentions), "mention"))
+	for _, m := range mentions {
+		sb.WriteString(fmt.Sprintf("\n- %s · %s: %s (message %s)", m.CreatedAt.Format("2006-01-02 15:04"), m.MentionedBy, m.Excerpt, m.MessageID))
+	}
+	return sb.String()
+}
+
+func activityText(items []*models.Activity) string {
+	var sb strings.Builder
+	sb.WriteString(count(len(items), "event"))
+	for _, item := range items {
+		sb.WriteString("\n" + activityLine(item))
+	}
+	return sb.String()
+}
+
+// activityLine renders one activity entry as a Markdown list item.
+// Locked threads get a 🔒 and read-only topics are marked, as in other listings.
+func activityLine(item *models.Activity) string {
+	when := item.At.Format("2006-01-02 15:04")
+	thread := "`" + item.ThreadID.String()[:8] + "`"
+	subject := item.Subject
+	if item.Locked {
+		subject = "🔒 " + subject
+	}
+
+	if item.Kind == models.ActivityThreadCreated {
+		topic := item.Topic
+		if item.ReadOnly {
+			topic += " (read-only)"
+		}
+		return fmt.Sprintf("- %s · %s started **%s** in %s (%s)", when, item.Actor, subject, topic, thread)
+	}
+
+	if item.ReadOnly {
+		thread += ", read-only topic"
+	}
+	verb := "posted in"
+	if item.Kind == models.ActivityMessageEdited {
+		verb = "edited a post in"
+	}
+	return fmt.Sprintf("- %s · %s %s **%s** (%s): %s", when, item.Actor, verb, subject, thread, item.Excerpt)
+}
+
+func templatesText(templates []*models.Template) string {
+	var sb strings.Builder
+	sb.WriteString(count(len(templates), "template"))
+	for _, t := range templates {
+		names := make([]string, 0, len(t.Fields))
+		for _, f := range t.Fields {
+			names = append(names, f.Name)
+		}
+		sb.WriteString(fmt.Sprintf("\n- %s (%s)", t.Name, strings.Join(names, ", ")))
+		if t.Description != "" {
+			sb.WriteString(": " + t.Description)
+		}
+	}
+	return sb.String()
+}
+
+func messagesText(messages []*models.Message) string {
+	var sb strings.Builder
+	sb.WriteString(count(len(messages), "message"))
+	for _, m := range messages {
+		sb.WriteString(fmt.Sprintf("\n- %s · %s: %s (ID %s)", m.CreatedAt.Format("2006-01-02 15:04"), m.CreatedBy, models.Excerpt(m.Content), m.ID))
+	}
+	return sb.String()
+}
+
+func batchText(results []batchResult) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Ran %s", count(len(results), "operation")))
+	for i, r := range results {
+		sb.WriteString(fmt.Sprintf("\n- $%d %s: ", i, r.Op))
+		switch {
+		case r.Topic != nil:
+			sb.WriteString(fmt.Sprintf("topic %s (ID %s)", r.Topic.Name, r.Topic.ID))
+		case r.Message != nil:
+			sb.WriteString(fmt.Sprintf("message %s", r.Message.ID))
+			if r.Thread != nil {
+				sb.WriteString(fmt.Sprintf(" in %s (thread ID %s)", r.Thread.Subject, r.Thread.ID))
+			}
+		case r.Thread != nil:
+			sb.WriteString(fmt.Sprintf("thread %s (ID %s)", r.Thread.Subject, r.Thread.ID))
+		}
+	}
+	return sb.String()
+}
@@ -1,24 +1,35 @@
 // ABOUTME: MCP prompt templates
-// ABOUTME: Guided workflows for common tasks
+// ABOUTME: Guided workflows for common tasks, filled with live board data
 
 package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/models"
 )
 
+// registerPrompts adds the built-in prompts. A prompt that tells the agent
+// to call tools is left out unless the server registers those tools.
 func (s *Server) registerPrompts() {
-	s.mcp.AddPrompt(&mcp.Prompt{
-		Name:        "post-update",
-		Description: "Post a status update to a topic",
-		Arguments: []*mcp.PromptArgument{
-			{Name: "topic", Description: "Topic to post to", Required: true},
-			{Name: "subject", Description: "Thread subject", Required: true},
-		},
-	}, s.handlePostUpdatePrompt)
+	if s.toolAllowed("create_thread") {
+		s.mcp.AddPrompt(&mcp.Prompt{
+			Name:        "post-update",
+			Description: "Post a status update to a topic",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "topic", Description: "Topic to post to", Required: true},
+				{Name: "subject", Description: "Thread subject", Required: true},
+			},
+		}, s.handlePostUpdatePrompt)
+	}
 
 	s.mcp.AddPrompt(&mcp.Prompt{
 		Name:        "summarize-thread",
@@ -27,6 +38,67 @@ func (s *Server) registerPrompts() {
 			{Name: "thread", Description: "Thread ID to summarize", Required: true},
 		},
 	}, s.handleSummarizePrompt)
+
+	s.mcp.AddPrompt(&mcp.Prompt{
+		Name:        "catch-up",
+		Description: "Catch up on unread mentions and new activity in watched topics and threads",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "agent", Description: "Whose inbox and feed to read (defaults to the session identity)"},
+			{Name: "since", Description: "Show activity since this time (duration like 2h or 7d, date, or RFC 3339) instead of the last feed check"},
+		},
+	}, s.handleCatchUpPrompt)
+
+	if s.toolAllowed("post_message") && s.toolAllowed("lock_thread") && s.toolAllowed("sticky_thread") {
+		s.mcp.AddPrompt(&mcp.Prompt{
+			Name:        "triage-topic",
+			Description: "Triage the open threads in a topic",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "topic", Description: "Topic name, slug or ID", Required: true},
+			},
+		}, s.handleTriagePrompt)
+	}
+
+	if s.toolAllowed("post_message") {
+		s.mcp.AddPrompt(&mcp.Prompt{
+			Name:        "draft-reply",
+			Description: "Draft a reply to a thread with the whole discussion as context",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "thread", Description: "Thread ID to reply to", Required: true},
+				{Name: "guidance", Description: "What the reply should say or achieve"},
+			},
+		}, s.handleDraftReplyPrompt)
+	}
+
+	if s.toolAllowed("create_thread") && s.toolAllowed("post_message") {
+		s.mcp.AddPrompt(&mcp.Prompt{
+			Name:        "handoff",
+			Description: "Write a handoff note from an agent's own recent posts",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "agent", Description: "Whose posts to collect (defaults to the session identity)"},
+				{Name: "since", Description: "Collect posts since this time (duration like 2h or 7d, date, or RFC 3339; default 24h)"},
+			},
+		}, s.handleHandoffPrompt)
+	}
+}
+
+// Limits that keep prompts with embedded board data to a reasonable size.
+const (
+	catchUpLimit = 50
+	triageLimit  = 30
+	handoffLimit = 50
+)
+
+// userPrompt wraps text as a single user message.
+func userPrompt(description, text string) *mcp.GetPromptResult {
+	return &mcp.GetPromptResult{
+		Description: description,
+		Messages: []*mcp.PromptMessage{
+			{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: text},
+			},
+		},
+	}
 }
 
 func (s *Server) handlePostUpdatePrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
@@ -52,22 +124,480 @@ Please use the create_thread tool with your update message. Keep it concise and
 }
 
 func (s *Server) handleSummarizePrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-	threadID := req.Params.Arguments["thread"]
+	thread, messages, err := s.threadWithMessages(req.Params.Arguments["thread"])
+	if err != nil {
+		return nil, err
+	}
 
-	return &mcp.GetPromptResult{
-		Description: fmt.Sprintf("Summarize thread %s", threadID),
-		Messages: []*mcp.PromptMessage{
-			{
-				Role: "user",
-				Content: &mcp.TextContent{
-					Text: fmt.Sprintf(`Please summarize the discussion in thread %s.
-
-First, use the list_messages tool to read the thread, then provide a concise summary of:
+	var sb strings.Builder
+	sb.WriteString("Please summarize this discussion from the BBS.\n\n")
+	writeTranscript(&sb, thread, messages)
+	sb.WriteString(`
+Provide a concise summary of:
 1. The main topic/question
 2. Key points discussed
-3. Any conclusions or action items`, threadID),
+3. Any conclusions or action items`)
+
+	return userPrompt(fmt.Sprintf("Summarize thread %s", thread.Subject), sb.String()), nil
+}
+
+func (s *Server) handleCatchUpPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	id, err := s.agentIdentity(req, req.Params.Arguments["agent"])
+	if err != nil {
+		return nil, err
+	}
+
+	var since *time.Time
+	if v := req.Params.Arguments["since"]; v != "" {
+		t, err := charm.ParseSince(v, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		since = &t
+	}
+
+	mentions, err := s.client.ListMentions(id, false)
+	if err != nil {
+		return nil, err
+	}
+	mentions = s.scopeMentions(mentions)
+	feed, err := s.client.GetFeed(id, since)
+	if err != nil {
+		return nil, err
+	}
+	feed.Items = s.scopeActivity(feed.Items)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Help %s catch up on the BBS.\n\n", id))
+
+	sb.WriteString(fmt.Sprintf("## Unread mentions (%d)\n\n", len(mentions)))
+	if len(mentions) == 0 {
+		sb.WriteString("*None.*\n\n")
+	}
+	for i, m := range mentions {
+		if i >= catchUpLimit {
+			sb.WriteString(fmt.Sprintf("*…and %d older mentions.*\n\n", len(mentions)-catchUpLimit))
+			break
+		}
+		subject := m.ThreadID.String()[:8]
+		if thread, err := s.client.GetThread(m.ThreadID); err == nil {
+			subject = thread.Subject
+		}
+		sb.WriteString(fmt.Sprintf("### %s mentioned you in **%s** (thread `%s`) · %s\n\n", m.MentionedBy, subject, m.ThreadID.String()[:8], m.CreatedAt.Format("2006-01-02 15:04")))
+		if msg, err := s.client.GetMessage(m.MessageID); err == nil {
+			sb.WriteString(msg.Content + "\n\n")
+		} else {
+			sb.WriteString(m.Excerpt + "\n\n")
+		}
+	}
+
+	items := feed.Items
+	heading := "Activity on watched topics and threads"
+	if feed.Subscriptions == 0 {
+		// Nothing is watched: fall back to what happened across the board.
+		heading = "Activity across the board (nothing is watched yet)"
+		if s.toolAllowed("subscribe") {
+			heading = "Activity across the board (nothing is watched yet; use the subscribe tool to narrow this)"
+		}
+		items, err = s.recentActivity(feed.Since, catchUpLimit)
+		if err != nil {
+			return nil, err
+		}
+		slices.Reverse(items)
+	}
+	if feed.Since.IsZero() {
+		sb.WriteString(fmt.Sprintf("## %s (%d)\n\n", heading, len(items)))
+	} else {
+		sb.WriteString(fmt.Sprintf("## %s since %s (%d)\n\n", heading, feed.Since.Format("2006-01-02 15:04"), len(items)))
+	}
+	if len(items) > catchUpLimit {
+		sb.WriteString(fmt.Sprintf("*Showing the latest %d of %d entries.*\n\n", catchUpLimit, len(items)))
+		items = items[len(items)-catchUpLimit:]
+	}
+	if len(items) == 0 {
+		sb.WriteString("*Nothing new.*\n\n")
+	}
+	for _, item := range items {
+		s.writeActivity(&sb, item)
+	}
+
+	sb.WriteString(`Summarize what happened, oldest to newest. Call out anything that needs a reply from me and suggest what to say.
+When we are done, mark this as read: call get_mentions with mark_read and get_feed without peek.`)
+
+	return userPrompt(fmt.Sprintf("Catch up for %s", id), sb.String()), nil
+}
+
+// writeActivity renders one activity entry, with the full post for posts and edits.
+func (s *Server) writeActivity(sb *strings.Builder, item *models.Activity) {
+	when := item.At.Format("2006-01-02 15:04")
+	thread := item.ThreadID.String()[:8]
+	subject := item.Subject
+	if item.Locked {
+		subject = "🔒 " + subject
+	}
+	topic := item.Topic
+	if item.ReadOnly {
+		topic += " (read-only)"
+	}
+	switch item.Kind {
+	case models.ActivityThreadCreated:
+		sb.WriteString(fmt.Sprintf("- %s · %s started **%s** in %s (thread `%s`)\n\n", when, item.Actor, subject, topic, thread))
+		return
+	case models.ActivityMessageEdited:
+		sb.WriteString(fmt.Sprintf("- %s · %s edited a post in **%s** (thread `%s`):\n\n", when, item.Actor, subject, thread))
+	default:
+		sb.WriteString(fmt.Sprintf("- %s · %s posted in **%s** (thread `%s`):\n\n", when, item.Actor, subject, thread))
+	}
+	content := item.Excerpt
+	if msg, err := s.client.GetMessage(item.MessageID); err == nil {
+		content = msg.Content
+	}
+	sb.WriteString(quote(content) + "\n\n")
+}
+
+func (s *Server) handleTriagePrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	topic, err := s.resolveTopic(req.Params.Arguments["topic"])
+	if err != nil {
+		return nil, err
+	}
+	threads, err := s.client.ListThreads(topic.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var open []*models.Thread
+	for _, t := range threads {
+		if !t.Locked {
+			open = append(open, t)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Triage the open threads in the **%s** topic of the BBS.\n\n", topic.Name))
+	if topic.Description != "" {
+		sb.WriteString(fmt.Sprintf("Topic description: %s\n\n", topic.Description))
+	}
+	sb.WriteString(fmt.Sprintf("## Open threads (%d of %d; locked threads are left out)\n\n", len(open), len(threads)))
+	if len(open) == 0 {
+		sb.WriteString("*No open threads.*\n\n")
+	}
+	for i, t := range open {
+		if i >= triageLimit {
+			sb.WriteString(fmt.Sprintf("*…and %d less active threads.*\n\n", len(open)-triageLimit))
+			break
+		}
+		messages, err := s.client.ListMessages(t.ID)
+		if err != nil {
+			return nil, err
+		}
+		writeThreadSummary(&sb, t, messages)
+	}
+
+	sb.WriteString(`For each thread, say whether it needs a response, is in progress, is resolved, or is stale, and who should act next.
+Then propose actions: replies to post (post_message), threads to lock once resolved (lock_thread), and threads worth pinning (sticky_thread).
+Do not make changes until I confirm.`)
+
+	return userPrompt(fmt.Sprintf("Triage %s", topic.Name), sb.String()), nil
+}
+
+// writeThreadSummary renders a thread's stats, opening post and latest reply.
+func writeThreadSummary(sb *strings.Builder, t *models.Thread, messages []*models.Message) {
+	title := t.Subject
+	if t.Sticky {
+		title = "📌 " + title
+	}
+	sb.WriteString(fmt.Sprintf("### %s (thread `%s`)\n\n", title, t.ID.String()[:8]))
+	sb.WriteString(fmt.Sprintf("Started by %s on %s · %d posts · %d people", t.CreatedBy, t.CreatedAt.Format("2006-01-02"), len(messages), len(t.Participants)))
+	if last := t.LastActive(); !last.IsZero() {
+		sb.WriteString(fmt.Sprintf(" · last activity %s", last.Format("2006-01-02 15:04")))
+		if t.LastPoster != "" {
+			sb.WriteString(" by " + t.LastPoster)
+		}
+	}
+	sb.WriteString("\n\n")
+	if len(messages) == 0 {
+		return
+	}
+	sb.WriteString("Opening post:\n\n" + quote(messages[0].Content) + "\n\n")
+	if len(messages) > 1 {
+		latest := messages[len(messages)-1]
+		sb.WriteString(fmt.Sprintf("Latest reply from %s: %s\n\n", latest.CreatedBy, models.Excerpt(latest.Content)))
+	}
+}
+
+func (s *Server) handleDraftReplyPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	thread, messages, err := s.threadWithMessages(req.Params.Arguments["thread"])
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Draft a reply to this BBS thread.\n\n")
+	writeTranscript(&sb, thread, messages)
+	if guidance := req.Params.Arguments["guidance"]; guidance != "" {
+		sb.WriteString(fmt.Sprintf("\nThe reply should: %s\n", guidance))
+	}
+	sb.WriteString(fmt.Sprintf(`
+Write a reply that moves the discussion forward: answer open questions, mention people with @name when they need to act, and keep it short.
+Show me the draft first. Once I approve it, post it with the post_message tool (thread_id %s).`, thread.ID))
+
+	return userPrompt(fmt.Sprintf("Draft a reply to %s", thread.Subject), sb.String()), nil
+}
+
+func (s *Server) handleHandoffPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	id, err := s.agentIdentity(req, req.Params.Arguments["agent"])
+	if err != nil {
+		return nil, err
+	}
+
+	sinceArg := req.Params.Arguments["since"]
+	if sinceArg == "" {
+		sinceArg = "24h"
+	}
+	since, err := charm.ParseSince(sinceArg, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.postsBy(id, since, handoffLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group posts by thread, threads in order of the latest post, posts oldest first.
+	var order []models.UUID
+	byThread := make(map[models.UUID][]*models.Message)
+	for _, p := range posts {
+		if _, ok := byThread[p.ThreadID]; !ok {
+			order = append(order, p.ThreadID)
+		}
+		byThread[p.ThreadID] = append(byThread[p.ThreadID], p)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Write a handoff note for the work %s did on the BBS since %s.\n\n", id, since.Format("2006-01-02 15:04")))
+	sb.WriteString(fmt.Sprintf("## Posts by %s (%d", id, len(posts)))
+	if len(posts) == handoffLimit {
+		sb.WriteString(", most recent only")
+	}
+	sb.WriteString(")\n\n")
+	if len(posts) == 0 {
+		sb.WriteString("*No posts in this period.*\n\n")
+	}
+	for _, threadID := range order {
+		subject, topicName := threadID.String()[:8], ""
+		if thread, err := s.client.GetThread(threadID); err == nil {
+			subject = thread.Subject
+			if topic, err := s.client.GetTopic(thread.TopicID); err == nil {
+				topicName = " in " + topic.Name
+			}
+		}
+		sb.WriteString(fmt.Sprintf("### %s%s (thread `%s`)\n\n", subject, topicName, threadID.String()[:8]))
+		thread := byThread[threadID]
+		slices.Reverse(thread)
+		for _, p := range thread {
+			sb.WriteString(fmt.Sprintf("%s:\n\n%s\n\n", p.CreatedAt.Format("2006-01-02 15:04"), quote(p.Content)))
+		}
+	}
+
+	sb.WriteString(`Write a handoff note for whoever picks up this work:
+1. What was done, with thread IDs for reference
+2. Current state and anything left unfinished
+3. Open questions and who is waiting on what
+4. Suggested next steps
+
+Show me the note first. If I ask, post it with create_thread or post_message.`)
+
+	return userPrompt(fmt.Sprintf("Handoff for %s", id), sb.String()), nil
+}
+
+// threadWithMessages resolves a thread argument and loads its messages.
+func (s *Server) threadWithMessages(idPrefix string) (*models.Thread, []*models.Message, error) {
+	if idPrefix == "" {
+		return nil, nil, fmt.Errorf("thread is required")
+	}
+	thread, err := s.resolveThread(idPrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	messages, err := s.client.ListMessages(thread.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return thread, messages, nil
+}
+
+// writeTranscript renders a whole thread, oldest post first.
+func writeTranscript(sb *strings.Builder, t *models.Thread, messages []*models.Message) {
+	sb.WriteString(fmt.Sprintf("# %s\n\n", t.Subject))
+	sb.WriteString(fmt.Sprintf("*Thread `%s`, started by %s on %s*\n\n", t.ID.String()[:8], t.CreatedBy, t.CreatedAt.Format("2006-01-02")))
+	if t.Locked {
+		sb.WriteString("🔒 *This thread is locked. New replies are not accepted.*\n\n")
+	}
+	for _, msg := range messages {
+		sb.WriteString(fmt.Sprintf("---\n\n**%s** · %s · `%s`", msg.CreatedBy, msg.CreatedAt.Format("2006-01-02 15:04"), msg.ID.String()[:8]))
+		if msg.EditedAt != nil {
+			sb.WriteString(" (edited)")
+		}
+		sb.WriteString("\n\n" + msg.Content + "\n\n")
+	}
+	if len(messages) == 0 {
+		sb.WriteString("*No messages yet.*\n\n")
+	}
+}
+
+// quote formats text as a Markdown blockquote.
+func quote(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// templatePromptName is the prompt name for a template: "template-<name>" for
+// global templates and "template-<topic-slug>-<name>" for topic templates.
+func templatePromptName(t *models.Template, topicSlug string) string {
+	if t.TopicID == nil {
+		return "template-" + t.Name
+	}
+	return "template-" + topicSlug + "-" + t.Name
+}
+
+// refreshTemplatePrompts registers a prompt for every template on the board
+// and removes prompts for templates that no longer exist. Template prompts
+// only drive post_from_template, so servers without that tool get none.
+func (s *Server) refreshTemplatePrompts() error {
+	if !s.toolAllowed("post_from_template") {
+		return nil
+	}
+	templates, err := s.client.ListTemplates(nil)
+	if err != nil {
+		return err
+	}
+	templates = s.scopeTemplates(templates)
+	// One listing resolves every slug, rather than a read per template.
+	topics, err := s.client.ListTopics(true)
+	if err != nil {
+		return err
+	}
+	slugs := make(map[models.UUID]string, len(topics))
+	for _, t := range topics {
+		slugs[t.ID] = t.Slug
+	}
+
+	s.promptsMu.Lock()
+	defer s.promptsMu.Unlock()
+
+	// Only re-add changed prompts: every AddPrompt notifies connected clients.
+	current := make(map[string]string, len(templates))
+	for _, t := range templates {
+		slug := ""
+		if t.TopicID != nil {
+			var ok bool
+			if slug, ok = slugs[*t.TopicID]; !ok {
+				continue // Topic was deleted; the template is unreachable.
+			}
+		}
+		name := templatePromptName(t, slug)
+		version, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		current[name] = string(version)
+		if s.templatePrompts[name] != current[name] {
+			s.mcp.AddPrompt(templatePrompt(name, t), s.templatePromptHandler(t.ID))
+		}
+	}
+
+	var stale []string
+	for name := range s.templatePrompts {
+		if _, ok := current[name]; !ok {
+			stale = append(stale, name)
+		}
+	}
+	if len(stale) > 0 {
+		s.mcp.RemovePrompts(stale...)
+	}
+	s.templatePrompts = current
+	return nil
+}
+
+// templatePrompt describes a template as a prompt whose arguments are its fields.
+func templatePrompt(name string, t *models.Template) *mcp.Prompt {
+	desc := t.Description
+	if desc == "" {
+		desc = "Post a " + t.Name + " message"
+	}
+	args := []*mcp.PromptArgument{
+		{Name: "thread", Description: "Thread ID to post to", Required: true},
+	}
+	for _, f := range t.Fields {
+		args = append(args, &mcp.PromptArgument{
+			Name:        f.Name,
+			Description: fieldHelp(f),
+			Required:    f.Required,
+		})
+	}
+	return &mcp.Prompt{Name: name, Description: desc, Arguments: args}
+}
+
+// fieldHelp describes a field's type and meaning for prompts.
+func fieldHelp(f models.TemplateField) string {
+	help := f.Type
+	if f.Type == models.FieldEnum {
+		help += " (one of " + strings.Join(f.Options, ", ") + ")"
+	}
+	if f.Description != "" {
+		help += ": " + f.Description
+	}
+	return help
+}
+
+func (s *Server) templatePromptHandler(id models.UUID) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		templates, err := s.client.ListTemplates(nil)
+		if err != nil {
+			return nil, err
+		}
+		var t *models.Template
+		for _, candidate := range templates {
+			if candidate.ID == id {
+				t = candidate
+			}
+		}
+		if t == nil {
+			return nil, fmt.Errorf("template no longer exists")
+		}
+
+		threadID := req.Params.Arguments["thread"]
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("Post a %s message to thread %s using the post_from_template tool with template %q.\n\n", t.Name, threadID, t.Name))
+		if t.Description != "" {
+			sb.WriteString(t.Description + "\n\n")
+		}
+		sb.WriteString("Fields:\n")
+		for _, f := range t.Fields {
+			need := "optional"
+			if f.Required {
+				need = "required"
+			}
+			sb.WriteString(fmt.Sprintf("- %s (%s, %s)", f.Name, fieldHelp(f), need))
+			if v := req.Params.Arguments[f.Name]; v != "" {
+				sb.WriteString(fmt.Sprintf(": %s", v))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\nFill in any missing fields from context. Keep values concise.")
+
+		return &mcp.GetPromptResult{
+			Description: fmt.Sprintf("Post %s to thread %s", t.Name, threadID),
+			Messages: []*mcp.PromptMessage{
+				{
+					Role:    "user",
+					Content: &mcp.TextContent{Text: sb.String()},
 				},
 			},
-		},
-	}, nil
+		}, nil
+	}
 }
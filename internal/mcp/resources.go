@@ -7,9 +7,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/harper/bbs/internal/models"
 )
 
 func (s *Server) registerResources() {
@@ -23,7 +28,7 @@ func (s *Server) registerResources() {
 	s.mcp.AddResource(&mcp.Resource{
 		URI:         "bbs://recent",
 		Name:        "Recent Activity",
-		Description: "Recent threads and messages across all topics",
+		Description: "Latest thread creations, posts and edits across all topics, newest first",
 		MIMEType:    "text/markdown",
 	}, s.handleRecentResource)
 
@@ -31,7 +36,7 @@ func (s *Server) registerResources() {
 	s.mcp.AddResourceTemplate(&mcp.ResourceTemplate{
 		URITemplate: "bbs://topics/{topic}/threads",
 		Name:        "Topic Threads",
-		Description: "Threads in a specific topic",
+		Description: "Threads in a specific topic (by name, slug, or ID)",
 		MIMEType:    "application/json",
 	}, s.handleTopicThreadsResource)
 
@@ -44,12 +49,15 @@ func (s *Server) registerResources() {
 }
 
 func (s *Server) handleTopicsResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	topics, err := s.client.ListTopics(false)
+	topics, err := s.listTopics(false)
 	if err != nil {
-		return nil, err
+		return nil, resourceError(req.Params.URI, err)
 	}
 
-	data, _ := json.MarshalIndent(topics, "", "  ")
+	data, err := json.MarshalIndent(topics, "", "  ")
+	if err != nil {
+		return nil, resourceError(req.Params.URI, err)
+	}
 	return &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{{
 			URI:      "bbs://topics",
@@ -59,30 +67,23 @@ func (s *Server) handleTopicsResource(ctx context.Context, req *mcp.ReadResource
 	}, nil
 }
 
+// recentResourceLimit is how many activity entries bbs://recent shows.
+const recentResourceLimit = 25
+
 func (s *Server) handleRecentResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	topics, _ := s.client.ListTopics(false)
+	items, err := s.recentActivity(time.Time{}, recentResourceLimit)
+	if err != nil {
+		return nil, resourceError(req.Params.URI, err)
+	}
 
 	var sb strings.Builder
 	sb.WriteString("# Recent Activity\n\n")
+	if len(items) == 0 {
+		sb.WriteString("*No activity yet.*\n")
+	}
 
-	for _, topic := range topics {
-		threads, _ := s.client.ListThreads(topic.ID)
-		if len(threads) == 0 {
-			continue
-		}
-
-		sb.WriteString(fmt.Sprintf("## %s\n\n", topic.Name))
-		for i, thread := range threads {
-			if i >= 3 {
-				break
-			}
-			prefix := ""
-			if thread.Sticky {
-				prefix = "📌 "
-			}
-			sb.WriteString(fmt.Sprintf("- %s**%s** by %s\n", prefix, thread.Subject, thread.CreatedBy))
-		}
-		sb.WriteString("\n")
+	for _, item := range items {
+		sb.WriteString(activityLine(item) + "\n")
 	}
 
 	return &mcp.ReadResourceResult{
@@ -98,21 +99,27 @@ func (s *Server) handleTopicThreadsResource(ctx context.Context, req *mcp.ReadRe
 	// Extract topic from URI
 	parts := strings.Split(req.Params.URI, "/")
 	if len(parts) < 4 {
-		return nil, fmt.Errorf("invalid URI")
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+	topicName, err := url.PathUnescape(parts[3])
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
 	}
-	topicName := parts[3]
 
-	topic, err := s.client.ResolveTopic(topicName)
+	topic, err := s.resolveTopic(topicName)
 	if err != nil {
-		return nil, err
+		return nil, resourceError(req.Params.URI, err)
 	}
 
 	threads, err := s.client.ListThreads(topic.ID)
 	if err != nil {
-		return nil, err
+		return nil, resourceError(req.Params.URI, err)
 	}
 
-	data, _ := json.MarshalIndent(threads, "", "  ")
+	data, err := json.MarshalIndent(threads, "", "  ")
+	if err != nil {
+		return nil, resourceError(req.Params.URI, err)
+	}
 	return &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{{
 			URI:      req.Params.URI,
@@ -126,28 +133,51 @@ func (s *Server) handleThreadMessagesResource(ctx context.Context, req *mcp.Read
 	// Extract thread from URI
 	parts := strings.Split(req.Params.URI, "/")
 	if len(parts) < 4 {
-		return nil, fmt.Errorf("invalid URI")
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
 	}
 	threadID := parts[3]
 
-	thread, err := s.client.ResolveThread(threadID)
+	thread, err := s.resolveThread(threadID)
 	if err != nil {
-		return nil, err
+		return nil, resourceError(req.Params.URI, err)
 	}
 
 	messages, err := s.client.ListMessages(thread.ID)
 	if err != nil {
-		return nil, err
+		return nil, resourceError(req.Params.URI, err)
 	}
 
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("# %s\n\n", thread.Subject))
 	sb.WriteString(fmt.Sprintf("*Started by %s on %s*\n\n", thread.CreatedBy, thread.CreatedAt.Format("2006-01-02")))
+	if thread.Locked {
+		sb.WriteString("🔒 *This thread is locked. New replies are not accepted.*\n\n")
+	}
 	sb.WriteString("---\n\n")
 
+	ids := make([]uuid.UUID, 0, len(messages))
+	for _, msg := range messages {
+		ids = append(ids, msg.ID)
+	}
+	reactions, err := s.client.ListReactionsForMessages(ids)
+	if err != nil {
+		return nil, resourceError(req.Params.URI, err)
+	}
+
 	for _, msg := range messages {
-		sb.WriteString(fmt.Sprintf("**%s** · %s\n\n", msg.CreatedBy, msg.CreatedAt.Format("Jan 02 15:04")))
+		sb.WriteString(fmt.Sprintf("**%s** · %s · `%s`", msg.CreatedBy, msg.CreatedAt.Format("Jan 02 15:04"), msg.ID.String()[:8]))
+		if msg.Template != "" {
+			sb.WriteString(fmt.Sprintf(" · template `%s`", msg.Template))
+		}
+		sb.WriteString("\n\n")
 		sb.WriteString(msg.Content)
+		if counts := models.SummarizeReactions(reactions[msg.ID]); len(counts) > 0 {
+			parts := make([]string, 0, len(counts))
+			for _, rc := range counts {
+				parts = append(parts, fmt.Sprintf("%s %d", rc.Reaction, rc.Count))
+			}
+			sb.WriteString("\n\n*Reactions: " + strings.Join(parts, " · ") + "*")
+		}
 		sb.WriteString("\n\n---\n\n")
 	}
 
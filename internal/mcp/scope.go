@@ -0,0 +1,233 @@
+// ABOUTME: Read-only and scoped server modes
+// ABOUTME: Limits which tools are registered and which topics tools, resources and prompts can see
+
+package mcp
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/harper/bbs/internal/models"
+)
+
+// readTools are the tools kept in read-only mode. None of them change board
+// content; get_mentions, get_feed and subscribe only update the agent's own
+// read marks, feed cursor and watch list.
+var readTools = map[string]bool{
+	"list_topics":         true,
+	"list_threads":        true,
+	"list_messages":       true,
+	"list_reactions":      true,
+	"get_mentions":        true,
+	"subscribe":           true,
+	"get_feed":            true,
+	"get_recent_activity": true,
+	"list_templates":      true,
+	"list_template_posts": true,
+}
+
+// boardWideTools cannot be limited to a set of topics, so servers with
+// allowed topics leave them out.
+var boardWideTools = map[string]bool{
+	"create_topic": true,
+	"batch":        true,
+}
+
+// WithReadOnly leaves out every tool that changes board content, and the
+// prompts that ask for them. The kept get_mentions, get_feed and subscribe
+// tools still write the agent's own read marks, feed cursor and watch list.
+func WithReadOnly() Option {
+	return func(s *Server) {
+		s.readOnly = true
+	}
+}
+
+// WithTools registers only the named tools. An empty list allows all tools.
+func WithTools(names []string) Option {
+	return func(s *Server) {
+		s.toolNames = names
+	}
+}
+
+// WithAllowTopics limits tools, resources and prompts to the given topics,
+// named by name, slug or ID. An empty list allows every topic.
+func WithAllowTopics(topics []string) Option {
+	return func(s *Server) {
+		s.topicRefs = topics
+	}
+}
+
+// resolveScope looks up the allowed topics once, so renaming a topic later
+// does not widen or narrow what the server exposes.
+func (s *Server) resolveScope() error {
+	if len(s.topicRefs) == 0 {
+		return nil
+	}
+	s.topics = make(map[models.UUID]bool, len(s.topicRefs))
+	for _, ref := range s.topicRefs {
+		topic, err := s.client.ResolveTopic(ref)
+		if err != nil {
+			return fmt.Errorf("allowed topic: %w", err)
+		}
+		s.topics[topic.ID] = true
+	}
+	return nil
+}
+
+// addTool registers a tool unless the server's mode leaves it out.
+func (s *Server) addTool(t *mcp.Tool, h mcp.ToolHandler) {
+	s.allTools = append(s.allTools, t.Name)
+	if s.toolAllowed(t.Name) {
+		s.mcp.AddTool(t, h)
+	}
+}
+
+func (s *Server) toolAllowed(name string) bool {
+	if len(s.toolNames) > 0 && !slices.Contains(s.toolNames, name) {
+		return false
+	}
+	if s.readOnly && !readTools[name] {
+		return false
+	}
+	return s.topics == nil || !boardWideTools[name]
+}
+
+// checkToolNames reports allowlisted tools that do not exist.
+func (s *Server) checkToolNames() error {
+	for _, name := range s.toolNames {
+		if !slices.Contains(s.allTools, name) {
+			return fmt.Errorf("unknown tool %q", name)
+		}
+	}
+	return nil
+}
+
+// topicAllowed reports whether the server may show the topic.
+func (s *Server) topicAllowed(id models.UUID) bool {
+	return s.topics == nil || s.topics[id]
+}
+
+// threadAllowed returns a check for whether a thread's topic is allowed,
+// remembering each thread it has looked up.
+func (s *Server) threadAllowed() func(models.UUID) bool {
+	seen := make(map[models.UUID]bool)
+	return func(id models.UUID) bool {
+		if s.topics == nil {
+			return true
+		}
+		if ok, done := seen[id]; done {
+			return ok
+		}
+		thread, err := s.client.GetThread(id)
+		seen[id] = err == nil && s.topics[thread.TopicID]
+		return seen[id]
+	}
+}
+
+// resolveTopic is ResolveTopic for the server's topics. Other topics are
+// left out before matching, so they are reported as not found and never
+// named as candidates for an ambiguous prefix.
+func (s *Server) resolveTopic(ref string) (*models.Topic, error) {
+	if s.topics == nil {
+		return s.client.ResolveTopic(ref)
+	}
+	return s.client.ResolveTopicFunc(ref, func(t *models.Topic) bool { return s.topics[t.ID] })
+}
+
+// resolveThread is ResolveThread for threads in the server's topics.
+func (s *Server) resolveThread(ref string) (*models.Thread, error) {
+	if s.topics == nil {
+		return s.client.ResolveThread(ref)
+	}
+	return s.client.ResolveThreadFunc(ref, func(t *models.Thread) bool { return s.topics[t.TopicID] })
+}
+
+// resolveMessage is ResolveMessage for messages in the server's topics.
+func (s *Server) resolveMessage(ref string) (*models.Message, error) {
+	if s.topics == nil {
+		return s.client.ResolveMessage(ref)
+	}
+	allowed := s.threadAllowed()
+	return s.client.ResolveMessageFunc(ref, func(m *models.Message) bool { return allowed(m.ThreadID) })
+}
+
+// listTopics is ListTopics limited to the server's topics.
+func (s *Server) listTopics(includeArchived bool) ([]*models.Topic, error) {
+	topics, err := s.client.ListTopics(includeArchived)
+	if err != nil || s.topics == nil {
+		return topics, err
+	}
+	return slices.DeleteFunc(topics, func(t *models.Topic) bool { return !s.topics[t.ID] }), nil
+}
+
+// recentActivity is RecentActivity limited to the server's topics. The
+// limit applies after filtering, so a scoped server still fills it.
+func (s *Server) recentActivity(since time.Time, limit int) ([]*models.Activity, error) {
+	if s.topics == nil {
+		return s.client.RecentActivity(since, limit)
+	}
+	items, err := s.client.RecentActivity(since, 0)
+	if err != nil {
+		return nil, err
+	}
+	items = s.scopeActivity(items)
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// postsBy is PostsBy limited to the server's topics, with the limit applied
+// after filtering.
+func (s *Server) postsBy(author string, since time.Time, limit int) ([]*models.Message, error) {
+	if s.topics == nil {
+		return s.client.PostsBy(author, since, limit)
+	}
+	posts, err := s.client.PostsBy(author, since, 0)
+	if err != nil {
+		return nil, err
+	}
+	posts = s.scopeMessages(posts)
+	if limit > 0 && len(posts) > limit {
+		posts = posts[:limit]
+	}
+	return posts, nil
+}
+
+// scopeActivity drops activity outside the server's topics.
+func (s *Server) scopeActivity(items []*models.Activity) []*models.Activity {
+	if s.topics == nil {
+		return items
+	}
+	return slices.DeleteFunc(items, func(a *models.Activity) bool { return !s.topics[a.TopicID] })
+}
+
+// scopeMentions drops mentions in threads outside the server's topics.
+func (s *Server) scopeMentions(mentions []*models.Mention) []*models.Mention {
+	if s.topics == nil {
+		return mentions
+	}
+	allowed := s.threadAllowed()
+	return slices.DeleteFunc(mentions, func(m *models.Mention) bool { return !allowed(m.ThreadID) })
+}
+
+// scopeMessages drops messages in threads outside the server's topics.
+func (s *Server) scopeMessages(messages []*models.Message) []*models.Message {
+	if s.topics == nil {
+		return messages
+	}
+	allowed := s.threadAllowed()
+	return slices.DeleteFunc(messages, func(m *models.Message) bool { return !allowed(m.ThreadID) })
+}
+
+// scopeTemplates drops templates that belong to topics outside the server's
+// topics. Global templates are always kept.
+func (s *Server) scopeTemplates(templates []*models.Template) []*models.Template {
+	if s.topics == nil {
+		return templates
+	}
+	return slices.DeleteFunc(templates, func(t *models.Template) bool { return t.TopicID != nil && !s.topics[*t.TopicID] })
+}
@@ -6,38 +6,100 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/models"
 )
 
 // Server wraps MCP server with Charm client.
 type Server struct {
-	mcp    *mcp.Server
-	client *charm.Client
+	mcp          *mcp.Server
+	client       *charm.Client
+	watcher      *resourceWatcher
+	pollInterval time.Duration
+	board        string
+	defaultAgent string
+
+	readOnly  bool
+	toolNames []string             // tools to register; empty registers all
+	allTools  []string             // every tool the server defines
+	topicRefs []string             // allowed topics as given
+	topics    map[models.UUID]bool // allowed topic IDs; nil allows all
+
+	promptsMu       sync.Mutex
+	templatePrompts map[string]string // prompt name -> template JSON it was built from
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithPollInterval sets how often subscribed resources are checked for changes.
+func WithPollInterval(d time.Duration) Option {
+	return func(s *Server) {
+		s.pollInterval = d
+	}
+}
+
+// WithBoard names the board the server is bound to; it is reported to
+// clients in the server's title.
+func WithBoard(name string) Option {
+	return func(s *Server) {
+		s.board = name
+	}
+}
+
+// WithDefaultAgent sets the username for tool calls that give no
+// agent_name, such as a board's default identity.
+func WithDefaultAgent(name string) Option {
+	return func(s *Server) {
+		s.defaultAgent = name
+	}
 }
 
 // NewServer creates MCP server with all capabilities.
-func NewServer(client *charm.Client) (*Server, error) {
+func NewServer(client *charm.Client, opts ...Option) (*Server, error) {
 	if client == nil {
 		return nil, fmt.Errorf("charm client is required")
 	}
 
-	mcpServer := mcp.NewServer(
-		&mcp.Implementation{
-			Name:    "bbs",
-			Version: "1.0.0",
-		},
-		nil,
-	)
-
 	s := &Server{
-		mcp:    mcpServer,
-		client: client,
+		client:       client,
+		watcher:      newResourceWatcher(),
+		pollInterval: DefaultPollInterval,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := s.resolveScope(); err != nil {
+		return nil, err
+	}
+
+	impl := &mcp.Implementation{
+		Name:    "bbs",
+		Version: "1.0.0",
+	}
+	if s.board != "" {
+		impl.Title = "BBS (" + s.board + ")"
+	}
+	s.mcp = mcp.NewServer(
+		impl,
+		&mcp.ServerOptions{
+			SubscribeHandler:   s.handleResourceSubscribe,
+			UnsubscribeHandler: s.handleResourceUnsubscribe,
+			CompletionHandler:  s.handleComplete,
+			KeepAlive:          sessionKeepAlive,
+		},
+	)
 
 	s.registerTools()
+	if err := s.checkToolNames(); err != nil {
+		return nil, err
+	}
 	s.registerResources()
 	s.registerPrompts()
 
@@ -46,5 +108,22 @@ func NewServer(client *charm.Client) (*Server, error) {
 
 // Serve starts the MCP server in stdio mode.
 func (s *Server) Serve(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.start(ctx)
 	return s.mcp.Run(ctx, &mcp.StdioTransport{})
 }
+
+// start registers template prompts and begins polling for changes.
+func (s *Server) start(ctx context.Context) {
+	if err := s.refreshTemplatePrompts(); err != nil {
+		log.Printf("template prompts: %v", err)
+	}
+	if s.toolAllowed("post_from_template") {
+		go s.pollTemplatePrompts(ctx, templateRefreshInterval)
+	}
+	if s.pollInterval > 0 {
+		go s.pollResources(ctx, s.pollInterval)
+	}
+}
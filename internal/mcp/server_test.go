@@ -4,7 +4,26 @@
 package mcp
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/models"
 )
 
 func TestNewServerRequiresClient(t *testing.T) {
@@ -19,3 +38,756 @@ func TestNewServerSuccess(t *testing.T) {
 	// This test verifies the nil check works
 	t.Skip("Requires Charm client connectivity")
 }
+
+func TestResourceKind(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"bbs://topics", "topics"},
+		{"bbs://recent", "recent"},
+		{"bbs://topics/general/threads", "topic_threads"},
+		{"bbs://topics/my%20topic/threads", "topic_threads"},
+		{"bbs://threads/abc12345/messages", "thread_messages"},
+		{"bbs://topics//threads", ""},
+		{"bbs://threads/abc12345", ""},
+		{"bbs://unknown", ""},
+		{"https://topics", ""},
+	}
+
+	for _, tt := range tests {
+		if got := resourceKind(tt.uri); got != tt.want {
+			t.Errorf("resourceKind(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestResourceWatcherUpdate(t *testing.T) {
+	w := newResourceWatcher()
+	first := sha256.Sum256([]byte("one"))
+	second := sha256.Sum256([]byte("two"))
+
+	if w.update("bbs://topics", second) {
+		t.Error("update should ignore URIs nobody subscribed to")
+	}
+
+	w.resources["bbs://topics"] = &watchedResource{
+		sessions:    map[*mcp.ServerSession]bool{nil: true},
+		fingerprint: first,
+	}
+	if w.update("bbs://topics", first) {
+		t.Error("unchanged content should not count as an update")
+	}
+	if !w.update("bbs://topics", second) {
+		t.Error("changed content should count as an update")
+	}
+	if w.update("bbs://topics", second) {
+		t.Error("the new fingerprint should be remembered")
+	}
+}
+
+func TestCheckResourcesDropsClosedSessions(t *testing.T) {
+	s, err := NewServer(&charm.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := s.mcp.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gone := &mcp.ServerSession{}
+	s.watcher.resources["bbs://topics"] = &watchedResource{sessions: map[*mcp.ServerSession]bool{ss: true, gone: true}}
+	s.watcher.resources["bbs://recent"] = &watchedResource{sessions: map[*mcp.ServerSession]bool{gone: true}}
+	s.watcher.prune(map[*mcp.ServerSession]bool{ss: true})
+	if got := s.watcher.subscribedURIs(); len(got) != 1 || got[0] != "bbs://topics" {
+		t.Errorf("after prune subscribed URIs = %v, want only bbs://topics", got)
+	}
+	if s.watcher.resources["bbs://topics"].sessions[gone] {
+		t.Error("prune should drop sessions that are not live")
+	}
+
+	_ = session.Close()
+	_ = ss.Wait()
+	s.checkResources(ctx)
+	if got := s.watcher.subscribedURIs(); len(got) != 0 {
+		t.Errorf("a closed session's subscriptions should be dropped, still watching %v", got)
+	}
+}
+
+func TestParseTokens(t *testing.T) {
+	tokens, err := ParseTokens([]string{"claude=abc", "xyz"})
+	if err != nil {
+		t.Fatalf("ParseTokens error: %v", err)
+	}
+	if tokens["abc"] != "claude" || tokens["xyz"] != DefaultTokenName {
+		t.Errorf("ParseTokens = %v", tokens)
+	}
+
+	for _, bad := range [][]string{{"claude="}, {"=abc"}, {"a@b=abc"}, {"a=abc", "b=abc"}} {
+		if _, err := ParseTokens(bad); err == nil {
+			t.Errorf("ParseTokens(%q) should fail", bad)
+		}
+	}
+}
+
+func TestReadTokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(path, []byte("# agents\nclaude=abc\n\n  codex=xyz  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	specs, err := ReadTokenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(specs, ","); got != "claude=abc,codex=xyz" {
+		t.Errorf("ReadTokenFile = %s", got)
+	}
+	if _, err := ReadTokenFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("ReadTokenFile should fail for a missing file")
+	}
+}
+
+func TestListenRequiresTokensOffLoopback(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:8080": true,
+		"localhost:8080": true,
+		"[::1]:8080":     true,
+		":8080":          false,
+		"0.0.0.0:8080":   false,
+		"10.0.0.5:8080":  false,
+	} {
+		if got := isLoopback(addr); got != want {
+			t.Errorf("isLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+
+	s, err := NewServer(&charm.Client{}, WithPollInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.ListenAndServe(context.Background(), HTTPOptions{Addr: ":0"})
+	if err == nil || !strings.Contains(err.Error(), "without tokens") {
+		t.Errorf("serving all interfaces without tokens = %v, want refusal", err)
+	}
+}
+
+func TestAgentIdentity(t *testing.T) {
+	t.Setenv("BBS_USER", "harper")
+	s := &Server{client: &charm.Client{}}
+	identityOf := func(s *Server, req mcp.Request, agentName string) string {
+		t.Helper()
+		id, err := s.agentIdentity(req, agentName)
+		if err != nil {
+			t.Fatalf("agentIdentity(%q): %v", agentName, err)
+		}
+		return id
+	}
+
+	if got := identityOf(s, nil, ""); got != "harper@mcp" {
+		t.Errorf("default identity = %q, want harper@mcp", got)
+	}
+	if got := identityOf(s, &mcp.CallToolRequest{Extra: &mcp.RequestExtra{}}, "scout"); got != "scout@mcp" {
+		t.Errorf("agent_name identity = %q, want scout@mcp", got)
+	}
+
+	extra := &mcp.RequestExtra{TokenInfo: &auth.TokenInfo{Extra: map[string]any{tokenNameKey: "claude"}}}
+	if got := identityOf(s, &mcp.CallToolRequest{Extra: extra}, "scout"); got != "claude@mcp" {
+		t.Errorf("token identity = %q, want claude@mcp", got)
+	}
+	unnamed := &mcp.RequestExtra{TokenInfo: &auth.TokenInfo{Extra: map[string]any{tokenNameKey: DefaultTokenName}}}
+	if got := identityOf(s, &mcp.CallToolRequest{Extra: unnamed}, ""); got != "harper@mcp" {
+		t.Errorf("unnamed token without a session = %q, want harper@mcp", got)
+	}
+
+	s = &Server{client: &charm.Client{}, defaultAgent: "sandbox-bot"}
+	if got := identityOf(s, nil, ""); got != "harper@mcp" {
+		t.Errorf("BBS_USER should win over the board's identity, got %q", got)
+	}
+	t.Setenv("BBS_USER", "")
+	if got := identityOf(s, nil, ""); got != "sandbox-bot@mcp" {
+		t.Errorf("board identity = %q, want sandbox-bot@mcp", got)
+	}
+}
+
+func TestAgentNameCannotClaimModerator(t *testing.T) {
+	t.Setenv("BBS_USER", "")
+	client, err := charm.NewClient(charm.WithModerators([]string{"harper", "ops"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{client: client, defaultAgent: "harper"}
+
+	if _, err := s.agentIdentity(nil, "ops"); !errors.Is(err, charm.ErrForbidden) {
+		t.Errorf("agent_name naming another moderator = %v, want ErrForbidden", err)
+	}
+	if id, err := s.agentIdentity(nil, "Harper"); err != nil || id != "Harper@mcp" {
+		t.Errorf("agent_name naming the server's own identity = %q, %v", id, err)
+	}
+	extra := &mcp.RequestExtra{TokenInfo: &auth.TokenInfo{Extra: map[string]any{tokenNameKey: "ops"}}}
+	if id, err := s.agentIdentity(&mcp.CallToolRequest{Extra: extra}, ""); err != nil || !client.IsModerator(id) {
+		t.Errorf("a moderator's named token should act as the moderator, got %q, %v", id, err)
+	}
+}
+
+func TestHTTPSessionsGetOwnIdentity(t *testing.T) {
+	t.Setenv("BBS_USER", "harper")
+	s, err := NewServer(&charm.Client{}, WithPollInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.mcp.AddTool(&mcp.Tool{Name: "whoami", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			id, err := s.agentIdentity(req, "")
+			if err != nil {
+				return toolError(err), nil
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: id}}}, nil
+		})
+	ts := httptest.NewServer(s.Handler(map[string]string{"secret": DefaultTokenName}))
+	defer ts.Close()
+
+	whoami := func() string {
+		ctx := context.Background()
+		transport := &mcp.StreamableClientTransport{
+			Endpoint:   ts.URL + "/mcp",
+			HTTPClient: &http.Client{Transport: bearer{"secret"}},
+		}
+		session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil).Connect(ctx, transport, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer session.Close()
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "whoami"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Content[0].(*mcp.TextContent).Text
+	}
+
+	first, second := whoami(), whoami()
+	if first == second {
+		t.Errorf("two sessions with an unnamed token share identity %q", first)
+	}
+	for _, id := range []string{first, second} {
+		if !strings.HasPrefix(id, DefaultTokenName+"-") || !strings.HasSuffix(id, "@mcp") {
+			t.Errorf("session identity = %q, want %s-<session>@mcp", id, DefaultTokenName)
+		}
+	}
+}
+
+// bearer adds a bearer token to each request.
+type bearer struct{ token string }
+
+func (b bearer) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestHandlerRequiresToken(t *testing.T) {
+	s := &Server{mcp: mcp.NewServer(&mcp.Implementation{Name: "bbs", Version: "test"}, nil)}
+	h := s.Handler(map[string]string{"secret": "claude"})
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("{}"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("no token: status = %d, want 401", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("{}"))
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want 401", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("{}"))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code == http.StatusUnauthorized {
+		t.Error("valid token was rejected")
+	}
+}
+
+func TestTemplatePrompt(t *testing.T) {
+	topicID := uuid.New()
+	tmpl := models.NewTemplate("incident", "", []models.TemplateField{
+		{Name: "severity", Type: models.FieldEnum, Options: []string{"sev1", "sev2"}, Required: true},
+		{Name: "summary", Type: models.FieldText},
+	}, "", "harper@cli")
+
+	if got := templatePromptName(tmpl, ""); got != "template-incident" {
+		t.Errorf("global prompt name = %q", got)
+	}
+	tmpl.TopicID = &topicID
+	if got := templatePromptName(tmpl, "ops"); got != "template-ops-incident" {
+		t.Errorf("topic prompt name = %q", got)
+	}
+
+	p := templatePrompt("template-ops-incident", tmpl)
+	if len(p.Arguments) != 3 || p.Arguments[0].Name != "thread" || !p.Arguments[1].Required || p.Arguments[2].Required {
+		t.Errorf("prompt arguments = %+v", p.Arguments)
+	}
+	if !strings.Contains(p.Arguments[1].Description, "sev1, sev2") {
+		t.Errorf("enum options missing from %q", p.Arguments[1].Description)
+	}
+}
+
+func TestMatchThreads(t *testing.T) {
+	deploy := &models.Thread{ID: uuid.MustParse("a1b2c3d4-0000-0000-0000-000000000001"), Subject: "Deploy checklist"}
+	standup := &models.Thread{ID: uuid.MustParse("f0f0f0f0-0000-0000-0000-000000000002"), Subject: "Daily standup"}
+	threads := []*models.Thread{deploy, standup}
+
+	if got := matchThreads(threads, "a1b2"); len(got) != 1 || got[0] != "a1b2c3d4" {
+		t.Errorf("ID prefix match = %v", got)
+	}
+	if got := matchThreads(threads, "DEPLOY"); len(got) != 1 || got[0] != "a1b2c3d4" {
+		t.Errorf("subject match = %v", got)
+	}
+	if got := matchThreads(threads, ""); len(got) != 2 {
+		t.Errorf("empty value should match every thread, got %v", got)
+	}
+}
+
+func TestCompletionResult(t *testing.T) {
+	if got := completionResult(nil); got.Completion.Values == nil {
+		t.Error("values must be an empty list, not null")
+	}
+
+	many := make([]string, maxCompletionValues+5)
+	got := completionResult(many)
+	if len(got.Completion.Values) != maxCompletionValues || !got.Completion.HasMore || got.Completion.Total != len(many) {
+		t.Errorf("completion = %d values, hasMore %v, total %d", len(got.Completion.Values), got.Completion.HasMore, got.Completion.Total)
+	}
+}
+
+func TestCompleteTemplateField(t *testing.T) {
+	tmpl := models.NewTemplate("incident", "", []models.TemplateField{
+		{Name: "severity", Type: models.FieldEnum, Options: []string{"sev1", "sev2", "minor"}},
+		{Name: "paged", Type: models.FieldBool},
+	}, "", "harper@cli")
+	version, _ := json.Marshal(tmpl)
+	s := &Server{templatePrompts: map[string]string{"template-incident": string(version)}}
+
+	if got := s.completeTemplateField("template-incident", "severity", "SEV"); len(got) != 2 {
+		t.Errorf("enum completion = %v, want sev1 and sev2", got)
+	}
+	if got := s.completeTemplateField("template-incident", "paged", "t"); len(got) != 1 || got[0] != "true" {
+		t.Errorf("bool completion = %v", got)
+	}
+	if got := s.completeTemplateField("template-missing", "severity", ""); got != nil {
+		t.Errorf("unknown prompt should complete nothing, got %v", got)
+	}
+}
+
+func TestWriteTranscript(t *testing.T) {
+	thread := &models.Thread{ID: uuid.New(), Subject: "Deploy checklist", CreatedBy: "harper@cli", Locked: true}
+	messages := []*models.Message{
+		models.NewMessage(thread.ID, "First post", "harper@cli"),
+		models.NewMessage(thread.ID, "A reply", "claude@mcp"),
+	}
+
+	var sb strings.Builder
+	writeTranscript(&sb, thread, messages)
+	out := sb.String()
+	for _, want := range []string{"# Deploy checklist", "locked", "First post", "**claude@mcp**", "A reply"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("transcript missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "First post") > strings.Index(out, "A reply") {
+		t.Error("transcript should list posts oldest first")
+	}
+}
+
+func TestQuote(t *testing.T) {
+	if got := quote("one\n\ntwo\n"); got != "> one\n>\n> two" {
+		t.Errorf("quote = %q", got)
+	}
+}
+
+// validateOutput checks that out, as sent over the wire, matches its tool's schema.
+func validateOutput[T any](t *testing.T, out T) {
+	t.Helper()
+	resolved, err := outputSchema[T]().Resolve(nil)
+	if err != nil {
+		t.Fatalf("resolve %T schema: %v", out, err)
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wire map[string]any
+	if err := json.Unmarshal(data, &wire); err != nil {
+		t.Fatal(err)
+	}
+	if err := resolved.Validate(wire); err != nil {
+		t.Errorf("%T does not match its schema: %v\n%s", out, err, data)
+	}
+}
+
+func TestToolOutputSchemas(t *testing.T) {
+	topic := models.NewTopic("general", "", "harper@cli")
+	thread := models.NewThread(topic.ID, "Deploy", "harper@cli")
+	msg := models.NewMessage(thread.ID, "hello @claude", "harper@cli")
+	now := time.Now()
+	thread.RecordPost(msg)
+	thread.UpdatedAt = &now
+	tmpl := models.NewTemplate("standup", "", []models.TemplateField{{Name: "today", Type: models.FieldText}}, "", "harper@cli")
+	posted, err := tmpl.NewMessage(thread.ID, map[string]string{"today": "ship"}, "claude@mcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validateOutput(t, topicsOutput{})
+	validateOutput(t, topicsOutput{Topics: []*models.Topic{topic}})
+	validateOutput(t, topicOutput{Topic: topic})
+	validateOutput(t, threadsOutput{Topic: topic, Threads: []*models.Thread{thread, models.NewThread(topic.ID, "empty", "harper@cli")}})
+	validateOutput(t, threadOutput{Thread: thread})
+	validateOutput(t, threadOutput{Thread: thread, Message: msg})
+	validateOutput(t, messagesOutput{Thread: thread, Messages: []*models.Message{msg, posted}})
+	validateOutput(t, messageOutput{Message: posted})
+	validateOutput(t, reactionOutput{MessageID: msg.ID, Reaction: "ack", Reactions: []models.ReactionCount{{Reaction: "ack", Count: 1, By: []string{"claude@mcp"}}}})
+	validateOutput(t, reactionsOutput{MessageID: msg.ID})
+	validateOutput(t, mentionsOutput{Identity: "claude@mcp", Mentions: []*models.Mention{{Username: "claude", MessageID: msg.ID, ThreadID: thread.ID, CreatedAt: now}}})
+	validateOutput(t, subscriptionOutput{Identity: "claude@mcp", Kind: models.WatchTopic, TargetID: topic.ID, Name: topic.Name, Watching: true})
+	validateOutput(t, feedOutput{Identity: "claude@mcp", Items: []*models.Activity{{Kind: models.ActivityMessagePosted, At: now, ThreadID: thread.ID, MessageID: msg.ID}}})
+	validateOutput(t, activityOutput{})
+	validateOutput(t, templatesOutput{Templates: []*models.Template{tmpl}})
+	validateOutput(t, templatePostsOutput{Template: "standup", Messages: []*models.Message{posted}})
+	validateOutput(t, batchOutput{Results: []batchResult{{Op: "create_thread", Thread: thread, Message: msg}, {Op: "archive", Topic: topic}}})
+}
+
+func TestToolsDeclareOutputSchemas(t *testing.T) {
+	s, err := NewServer(&charm.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := s.mcp.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range tools.Tools {
+		if tool.OutputSchema == nil {
+			t.Errorf("tool %s has no output schema", tool.Name)
+		}
+	}
+}
+
+// listToolNames connects to s in memory and returns the names of its tools.
+func listToolNames(t *testing.T, s *Server) []string {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := s.mcp.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestScopedTools(t *testing.T) {
+	s, err := NewServer(&charm.Client{}, WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := listToolNames(t, s)
+	if len(names) != len(readTools) {
+		t.Errorf("read-only server has %d tools, want %d: %v", len(names), len(readTools), names)
+	}
+	for _, name := range names {
+		if !readTools[name] {
+			t.Errorf("read-only server registered %s", name)
+		}
+	}
+
+	s, err = NewServer(&charm.Client{}, WithTools([]string{"list_threads", "post_message", "create_topic"}), WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(listToolNames(t, s), ","); got != "list_threads" {
+		t.Errorf("read-only allowlisted tools = %s, want list_threads", got)
+	}
+
+	if _, err := NewServer(&charm.Client{}, WithTools([]string{"list_threads", "drop_tables"})); err == nil || !strings.Contains(err.Error(), "drop_tables") {
+		t.Errorf("unknown tool should be rejected, got %v", err)
+	}
+
+	s = &Server{topics: map[models.UUID]bool{uuid.New(): true}}
+	for _, name := range []string{"create_topic", "batch"} {
+		if s.toolAllowed(name) {
+			t.Errorf("topic-scoped server should leave out %s", name)
+		}
+	}
+	if !s.toolAllowed("post_message") {
+		t.Error("topic-scoped server should keep post_message")
+	}
+}
+
+func TestReadOnlyPrompts(t *testing.T) {
+	promptNames := func(s *Server) string {
+		ctx := context.Background()
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		if _, err := s.mcp.Connect(ctx, serverTransport, nil); err != nil {
+			t.Fatal(err)
+		}
+		session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil).Connect(ctx, clientTransport, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer session.Close()
+		prompts, err := session.ListPrompts(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, p := range prompts.Prompts {
+			names = append(names, p.Name)
+		}
+		slices.Sort(names)
+		return strings.Join(names, ",")
+	}
+
+	s, err := NewServer(&charm.Client{}, WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := promptNames(s); got != "catch-up,summarize-thread" {
+		t.Errorf("read-only prompts = %s, want catch-up,summarize-thread", got)
+	}
+
+	s, err = NewServer(&charm.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := promptNames(s); got != "catch-up,draft-reply,handoff,post-update,summarize-thread,triage-topic" {
+		t.Errorf("prompts = %s, want all built-in prompts", got)
+	}
+}
+
+func TestScopeFilters(t *testing.T) {
+	allowed, other := uuid.New(), uuid.New()
+	s := &Server{topics: map[models.UUID]bool{allowed: true}}
+
+	items := s.scopeActivity([]*models.Activity{
+		{TopicID: allowed, Subject: "kept"},
+		{TopicID: other, Subject: "hidden"},
+	})
+	if len(items) != 1 || items[0].Subject != "kept" {
+		t.Errorf("scopeActivity kept %v", items)
+	}
+
+	templates := s.scopeTemplates([]*models.Template{
+		{Name: "global"},
+		{Name: "ours", TopicID: &allowed},
+		{Name: "theirs", TopicID: &other},
+	})
+	var names []string
+	for _, tmpl := range templates {
+		names = append(names, tmpl.Name)
+	}
+	if got := strings.Join(names, ","); got != "global,ours" {
+		t.Errorf("scopeTemplates kept %s, want global,ours", got)
+	}
+
+	if !s.topicAllowed(allowed) || s.topicAllowed(other) {
+		t.Error("topicAllowed should only allow the scoped topic")
+	}
+	if unscoped := (&Server{}); !unscoped.topicAllowed(other) {
+		t.Error("a server without allowed topics should allow every topic")
+	}
+}
+
+func TestScopedResolveIgnoresHiddenRecords(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping embedded charm server test in short mode")
+	}
+	srv, err := charm.StartServer(charm.ServerConfig{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("StartServer: %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	for name, value := range srv.ClientEnv() {
+		t.Setenv(name, value)
+	}
+	t.Setenv("CHARM_DATA_DIR", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	client, err := charm.NewClient(charm.WithDataDir(t.TempDir()), charm.WithAutoSync(false))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	// Records share ID prefixes across the allowed and the hidden topic.
+	ours := models.NewTopic("standup", "", "harper@cli")
+	ours.ID = uuid.MustParse("aaaa0000-0000-0000-0000-000000000001")
+	hidden := models.NewTopic("payroll", "", "harper@cli")
+	hidden.ID = uuid.MustParse("aaaa0000-0000-0000-0000-000000000002")
+	for _, topic := range []*models.Topic{ours, hidden} {
+		if err := client.CreateTopic(topic); err != nil {
+			t.Fatalf("CreateTopic: %v", err)
+		}
+	}
+	threads := []*models.Thread{
+		models.NewThread(ours.ID, "Monday", "harper@cli"),
+		models.NewThread(ours.ID, "Tuesday", "harper@cli"),
+		models.NewThread(hidden.ID, "Raises", "harper@cli"),
+	}
+	for i, thread := range threads {
+		thread.ID = uuid.MustParse(fmt.Sprintf("bbbb0000-0000-0000-0000-00000000000%d", i+1))
+		if err := client.CreateThread(thread); err != nil {
+			t.Fatalf("CreateThread: %v", err)
+		}
+	}
+
+	s, err := NewServer(client, WithAllowTopics([]string{"standup"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if topic, err := s.resolveTopic("aaaa"); err != nil || topic.ID != ours.ID {
+		t.Errorf("resolveTopic(aaaa) = %v, %v; want the allowed topic", topic, err)
+	}
+	if _, err := s.resolveTopic("payroll"); !errors.Is(err, charm.ErrNotFound) {
+		t.Errorf("resolveTopic(payroll) = %v, want ErrNotFound", err)
+	}
+	if thread, err := s.resolveThread("bbbb0000-0000-0000-0000-000000000001"); err != nil || thread.ID != threads[0].ID {
+		t.Errorf("resolveThread(full ID) = %v, %v; want Monday", thread, err)
+	}
+	if _, err := s.resolveThread(threads[2].ID.String()); !errors.Is(err, charm.ErrNotFound) {
+		t.Errorf("resolveThread(hidden ID) = %v, want ErrNotFound", err)
+	}
+
+	_, err = s.resolveThread("bbbb")
+	var amb *charm.AmbiguousError
+	if !errors.As(err, &amb) {
+		t.Fatalf("resolveThread(bbbb) = %v, want an ambiguous prefix", err)
+	}
+	if len(amb.Candidates) != 2 {
+		t.Errorf("candidates = %+v, want the two allowed threads", amb.Candidates)
+	}
+	if text := toolError(err).Content[0].(*mcp.TextContent).Text; strings.Contains(text, "Raises") {
+		t.Errorf("tool error names a hidden thread: %s", text)
+	}
+}
+
+func TestActivityLineMarkers(t *testing.T) {
+	item := &models.Activity{
+		Kind:     models.ActivityMessagePosted,
+		At:       time.Date(2025, 12, 20, 12, 0, 0, 0, time.UTC),
+		Actor:    "harper@cli",
+		Topic:    "announcements",
+		ThreadID: uuid.New(),
+		Subject:  "Deploy",
+		Excerpt:  "shipping",
+	}
+	if line := activityLine(item); strings.Contains(line, "🔒") || strings.Contains(line, "read-only") {
+		t.Errorf("unmarked activity = %q", line)
+	}
+
+	item.Locked, item.ReadOnly = true, true
+	if line := activityLine(item); !strings.Contains(line, "**🔒 Deploy**") || !strings.Contains(line, "read-only topic") {
+		t.Errorf("post in a locked thread of a read-only topic = %q, want both markers", line)
+	}
+	item.Kind = models.ActivityThreadCreated
+	if line := activityLine(item); !strings.Contains(line, "**🔒 Deploy** in announcements (read-only)") {
+		t.Errorf("thread started in a read-only topic = %q, want both markers", line)
+	}
+}
+
+func TestToolError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&charm.NotFoundError{Kind: "topic", Key: "nope"}, CodeNotFound},
+		{fmt.Errorf("post: %w", charm.ErrThreadLocked), CodeForbidden},
+		{charm.ErrTopicExists, CodeConflict},
+		{fmt.Errorf("read: %w", charm.ErrSyncFailed), CodeSyncFailed},
+		{fmt.Errorf("%w: bad json", errInvalidArguments), CodeInvalidArgument},
+		{models.ErrInvalidFields, CodeInvalidArgument},
+		{errors.New("disk full"), CodeInternal},
+	}
+	for _, tt := range tests {
+		res := toolError(tt.err)
+		out, ok := res.StructuredContent.(toolErrorOutput)
+		if !res.IsError || !ok || out.Error.Code != tt.want {
+			t.Errorf("toolError(%q) = %+v, want code %s", tt.err, res.StructuredContent, tt.want)
+		}
+	}
+
+	amb := &charm.AmbiguousError{Kind: "thread", Prefix: "ab", Candidates: []charm.Candidate{
+		{ID: uuid.New(), Name: "Deploy"},
+		{ID: uuid.New(), Name: "Standup"},
+	}}
+	out := toolError(amb).StructuredContent.(toolErrorOutput)
+	if out.Error.Code != CodeAmbiguous || len(out.Error.Candidates) != 2 {
+		t.Errorf("ambiguous error = %+v, want code and both candidates", out.Error)
+	}
+}
+
+func TestResourceError(t *testing.T) {
+	err := resourceError("bbs://threads/nope/messages", &charm.NotFoundError{Kind: "thread", Key: "nope"})
+	if !strings.Contains(err.Error(), "not found") {
+		t.Errorf("missing thread should be a resource-not-found error, got %v", err)
+	}
+	err = resourceError("bbs://recent", fmt.Errorf("read: %w", charm.ErrSyncFailed))
+	if !strings.HasPrefix(err.Error(), CodeSyncFailed+": ") || !errors.Is(err, charm.ErrSyncFailed) {
+		t.Errorf("resource error = %v, want sync_failed prefix", err)
+	}
+}
+
+func TestBatchText(t *testing.T) {
+	topic := models.NewTopic("general", "", "harper@cli")
+	thread := models.NewThread(topic.ID, "Deploy", "harper@cli")
+	msg := models.NewMessage(thread.ID, "hello", "harper@cli")
+	text := batchText([]batchResult{
+		{Op: "create_thread", Thread: thread, Message: msg},
+		{Op: "sticky", Thread: thread},
+		{Op: "archive", Topic: topic},
+	})
+	for _, want := range []string{
+		"Ran 3 operations",
+		"$0 create_thread: message " + msg.ID.String() + " in Deploy",
+		"$1 sticky: thread Deploy (ID " + thread.ID.String() + ")",
+		"$2 archive: topic general",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("batch text missing %q:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// ABOUTME: MCP resource subscriptions and change notifications
+// ABOUTME: Polls subscribed resources and notifies sessions when they change
+
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultPollInterval is how often subscribed resources are checked for changes.
+const DefaultPollInterval = 5 * time.Second
+
+// sessionKeepAlive is how often sessions are pinged. A client that stops
+// answering is disconnected, which also drops its resource subscriptions.
+const sessionKeepAlive = 30 * time.Second
+
+// templateRefreshInterval is how often template prompts are rebuilt from
+// the board's templates.
+const templateRefreshInterval = time.Minute
+
+// watchedResource tracks the sessions subscribed to a URI and its last content hash.
+type watchedResource struct {
+	sessions    map[*mcp.ServerSession]bool
+	fingerprint [sha256.Size]byte
+}
+
+// resourceWatcher holds the set of subscribed resource URIs.
+type resourceWatcher struct {
+	mu        sync.Mutex
+	resources map[string]*watchedResource
+}
+
+func newResourceWatcher() *resourceWatcher {
+	return &resourceWatcher{resources: make(map[string]*watchedResource)}
+}
+
+// resourceKind classifies a bbs:// URI as one of the registered resources.
+// It returns "" for URIs the server does not serve.
+func resourceKind(uri string) string {
+	switch {
+	case uri == "bbs://topics":
+		return "topics"
+	case uri == "bbs://recent":
+		return "recent"
+	}
+
+	rest, ok := strings.CutPrefix(uri, "bbs://")
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 || parts[1] == "" {
+		return ""
+	}
+	switch {
+	case parts[0] == "topics" && parts[2] == "threads":
+		return "topic_threads"
+	case parts[0] == "threads" && parts[2] == "messages":
+		return "thread_messages"
+	}
+	return ""
+}
+
+// readResource renders a resource the same way resources/read would.
+func (s *Server) readResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	req := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}}
+	switch resourceKind(uri) {
+	case "topics":
+		return s.handleTopicsResource(ctx, req)
+	case "recent":
+		return s.handleRecentResource(ctx, req)
+	case "topic_threads":
+		return s.handleTopicThreadsResource(ctx, req)
+	case "thread_messages":
+		return s.handleThreadMessagesResource(ctx, req)
+	}
+	return nil, mcp.ResourceNotFoundError(uri)
+}
+
+// fingerprint hashes the rendered content of a resource.
+func (s *Server) fingerprint(ctx context.Context, uri string) ([sha256.Size]byte, error) {
+	res, err := s.readResource(ctx, uri)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	h := sha256.New()
+	for _, c := range res.Contents {
+		h.Write([]byte(c.Text))
+		h.Write(c.Blob)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+func (s *Server) handleResourceSubscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	uri := req.Params.URI
+	sum, err := s.fingerprint(ctx, uri)
+	if err != nil {
+		return fmt.Errorf("cannot subscribe to %s: %w", uri, err)
+	}
+
+	s.watcher.mu.Lock()
+	defer s.watcher.mu.Unlock()
+	w, ok := s.watcher.resources[uri]
+	if !ok {
+		w = &watchedResource{sessions: make(map[*mcp.ServerSession]bool), fingerprint: sum}
+		s.watcher.resources[uri] = w
+	}
+	w.sessions[req.Session] = true
+	return nil
+}
+
+func (s *Server) handleResourceUnsubscribe(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	s.watcher.mu.Lock()
+	defer s.watcher.mu.Unlock()
+	if w, ok := s.watcher.resources[req.Params.URI]; ok {
+		delete(w.sessions, req.Session)
+		if len(w.sessions) == 0 {
+			delete(s.watcher.resources, req.Params.URI)
+		}
+	}
+	return nil
+}
+
+// prune drops sessions that are no longer connected, and URIs left with no
+// subscribers. The SDK has no hook for a session closing, so the poller
+// calls this with the server's live sessions.
+func (w *resourceWatcher) prune(live map[*mcp.ServerSession]bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for uri, r := range w.resources {
+		for ss := range r.sessions {
+			if !live[ss] {
+				delete(r.sessions, ss)
+			}
+		}
+		if len(r.sessions) == 0 {
+			delete(w.resources, uri)
+		}
+	}
+}
+
+// subscribedURIs returns the URIs with at least one subscriber.
+func (w *resourceWatcher) subscribedURIs() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	uris := make([]string, 0, len(w.resources))
+	for uri := range w.resources {
+		uris = append(uris, uri)
+	}
+	return uris
+}
+
+// update stores a new fingerprint and reports whether it differs from the last one.
+// It returns false if the URI was unsubscribed in the meantime.
+func (w *resourceWatcher) update(uri string, sum [sha256.Size]byte) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	r, ok := w.resources[uri]
+	if !ok || r.fingerprint == sum {
+		return false
+	}
+	r.fingerprint = sum
+	return true
+}
+
+// pollResources checks subscribed resources every interval until ctx is done.
+func (s *Server) pollResources(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkResources(ctx)
+		}
+	}
+}
+
+// pollTemplatePrompts refreshes template prompts every interval until ctx
+// is done. It runs on its own timer so prompts stay current even when
+// resource polling is off.
+func (s *Server) pollTemplatePrompts(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.refreshTemplatePrompts(); err != nil {
+				log.Printf("template prompts: %v", err)
+			}
+		}
+	}
+}
+
+// checkResources syncs if stale and notifies subscribers of resources that changed.
+func (s *Server) checkResources(ctx context.Context) {
+	live := make(map[*mcp.ServerSession]bool)
+	for ss := range s.mcp.Sessions() {
+		live[ss] = true
+	}
+	s.watcher.prune(live)
+
+	uris := s.watcher.subscribedURIs()
+	if len(uris) == 0 {
+		return
+	}
+
+	if err := s.client.SyncIfStale(); err != nil {
+		log.Printf("resource poll: sync failed: %v", err)
+	}
+
+	for _, uri := range uris {
+		sum, err := s.fingerprint(ctx, uri)
+		if err != nil {
+			// A deleted thread or renamed topic is a change too.
+			sum = sha256.Sum256([]byte("error: " + err.Error()))
+		}
+		if !s.watcher.update(uri, sum) {
+			continue
+		}
+		if err := s.mcp.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri}); err != nil {
+			log.Printf("resource poll: notify %s: %v", uri, err)
+		}
+	}
+}
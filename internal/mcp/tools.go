@@ -11,67 +11,192 @@ import (
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
-	"github.com/harper/bbs/internal/identity"
+	"github.com/harper/bbs/internal/charm"
 	"github.com/harper/bbs/internal/models"
 )
 
 func (s *Server) registerTools() {
 	// Topic tools
-	s.mcp.AddTool(&mcp.Tool{
-		Name:        "list_topics",
-		Description: "List all topics on the board",
-		InputSchema: json.RawMessage(`{"type":"object","properties":{"include_archived":{"type":"boolean","description":"Include archived topics"}}}`),
+	s.addTool(&mcp.Tool{
+		Name:         "list_topics",
+		Description:  "List all topics on the board",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"include_archived":{"type":"boolean","description":"Include archived topics"}}}`),
+		OutputSchema: outputSchema[topicsOutput](),
 	}, s.handleListTopics)
 
-	s.mcp.AddTool(&mcp.Tool{
-		Name:        "create_topic",
-		Description: "Create a new topic",
-		InputSchema: json.RawMessage(`{"type":"object","properties":{"name":{"type":"string"},"description":{"type":"string"}},"required":["name"]}`),
+	s.addTool(&mcp.Tool{
+		Name:         "create_topic",
+		Description:  "Create a new topic. Names must be unique (case-insensitive); a URL-safe slug is derived from the name",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"name":{"type":"string"},"description":{"type":"string"},"agent_name":{"type":"string"}},"required":["name"]}`),
+		OutputSchema: outputSchema[topicOutput](),
 	}, s.handleCreateTopic)
 
-	s.mcp.AddTool(&mcp.Tool{
-		Name:        "archive_topic",
-		Description: "Archive or unarchive a topic",
-		InputSchema: json.RawMessage(`{"type":"object","properties":{"topic":{"type":"string"},"archived":{"type":"boolean"}},"required":["topic","archived"]}`),
+	s.addTool(&mcp.Tool{
+		Name:         "archive_topic",
+		Description:  "Archive or unarchive a topic",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"topic":{"type":"string"},"archived":{"type":"boolean"}},"required":["topic","archived"]}`),
+		OutputSchema: outputSchema[topicOutput](),
 	}, s.handleArchiveTopic)
 
+	s.addTool(&mcp.Tool{
+		Name:         "rename_topic",
+		Description:  "Rename a topic (names must stay unique)",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"topic":{"type":"string"},"name":{"type":"string"},"agent_name":{"type":"string"}},"required":["topic","name"]}`),
+		OutputSchema: outputSchema[topicOutput](),
+	}, s.handleRenameTopic)
+
+	s.addTool(&mcp.Tool{
+		Name:         "set_topic_read_only",
+		Description:  "Make a topic read-only or writable again (moderators only; acts as the session's identity)",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"topic":{"type":"string"},"read_only":{"type":"boolean"}},"required":["topic","read_only"]}`),
+		OutputSchema: outputSchema[topicOutput](),
+	}, s.handleSetTopicReadOnly)
+
+	s.addTool(&mcp.Tool{
+		Name:         "describe_topic",
+		Description:  "Change a topic's description",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"topic":{"type":"string"},"description":{"type":"string"},"agent_name":{"type":"string"}},"required":["topic","description"]}`),
+		OutputSchema: outputSchema[topicOutput](),
+	}, s.handleDescribeTopic)
+
 	// Thread tools
-	s.mcp.AddTool(&mcp.Tool{
-		Name:        "list_threads",
-		Description: "List threads in a topic",
-		InputSchema: json.RawMessage(`{"type":"object","properties":{"topic":{"type":"string"}},"required":["topic"]}`),
+	s.addTool(&mcp.Tool{
+		Name:         "list_threads",
+		Description:  "List threads in a topic, sticky first then most recently active, with message count, last poster and participants",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"topic":{"type":"string"}},"required":["topic"]}`),
+		OutputSchema: outputSchema[threadsOutput](),
 	}, s.handleListThreads)
 
-	s.mcp.AddTool(&mcp.Tool{
-		Name:        "create_thread",
-		Description: "Create a new thread with initial message",
-		InputSchema: json.RawMessage(`{"type":"object","properties":{"topic":{"type":"string"},"subject":{"type":"string"},"message":{"type":"string"},"agent_name":{"type":"string"}},"required":["topic","subject"]}`),
+	s.addTool(&mcp.Tool{
+		Name:         "create_thread",
+		Description:  "Create a new thread with initial message",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"topic":{"type":"string"},"subject":{"type":"string"},"message":{"type":"string"},"agent_name":{"type":"string"}},"required":["topic","subject"]}`),
+		OutputSchema: outputSchema[threadOutput](),
 	}, s.handleCreateThread)
 
-	s.mcp.AddTool(&mcp.Tool{
-		Name:        "sticky_thread",
-		Description: "Pin or unpin a thread",
-		InputSchema: json.RawMessage(`{"type":"object","properties":{"thread":{"type":"string"},"sticky":{"type":"boolean"}},"required":["thread","sticky"]}`),
+	s.addTool(&mcp.Tool{
+		Name:         "sticky_thread",
+		Description:  "Pin or unpin a thread",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"thread":{"type":"string"},"sticky":{"type":"boolean"}},"required":["thread","sticky"]}`),
+		OutputSchema: outputSchema[threadOutput](),
 	}, s.handleStickyThread)
 
+	s.addTool(&mcp.Tool{
+		Name:         "lock_thread",
+		Description:  "Lock or unlock a thread so no new replies can be posted (moderators only; acts as the session's identity)",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"thread":{"type":"string"},"locked":{"type":"boolean"}},"required":["thread","locked"]}`),
+		OutputSchema: outputSchema[threadOutput](),
+	}, s.handleLockThread)
+
+	s.addTool(&mcp.Tool{
+		Name:         "retitle_thread",
+		Description:  "Change a thread's subject",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"thread":{"type":"string"},"subject":{"type":"string"},"agent_name":{"type":"string"}},"required":["thread","subject"]}`),
+		OutputSchema: outputSchema[threadOutput](),
+	}, s.handleRetitleThread)
+
+	s.addTool(&mcp.Tool{
+		Name:         "move_thread",
+		Description:  "Move a thread to another topic",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"thread":{"type":"string"},"topic":{"type":"string"},"agent_name":{"type":"string"}},"required":["thread","topic"]}`),
+		OutputSchema: outputSchema[threadOutput](),
+	}, s.handleMoveThread)
+
 	// Message tools
-	s.mcp.AddTool(&mcp.Tool{
-		Name:        "list_messages",
-		Description: "List messages in a thread",
-		InputSchema: json.RawMessage(`{"type":"object","properties":{"thread":{"type":"string"}},"required":["thread"]}`),
+	s.addTool(&mcp.Tool{
+		Name:         "list_messages",
+		Description:  "List messages in a thread",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"thread":{"type":"string"}},"required":["thread"]}`),
+		OutputSchema: outputSchema[messagesOutput](),
 	}, s.handleListMessages)
 
-	s.mcp.AddTool(&mcp.Tool{
-		Name:        "post_message",
-		Description: "Post a message to a thread",
-		InputSchema: json.RawMessage(`{"type":"object","properties":{"thread":{"type":"string"},"content":{"type":"string"},"agent_name":{"type":"string"}},"required":["thread","content"]}`),
+	s.addTool(&mcp.Tool{
+		Name:         "post_message",
+		Description:  "Post a message to a thread",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"thread":{"type":"string"},"content":{"type":"string"},"agent_name":{"type":"string"}},"required":["thread","content"]}`),
+		OutputSchema: outputSchema[messageOutput](),
 	}, s.handlePostMessage)
 
-	s.mcp.AddTool(&mcp.Tool{
-		Name:        "edit_message",
-		Description: "Edit an existing message",
-		InputSchema: json.RawMessage(`{"type":"object","properties":{"message_id":{"type":"string"},"content":{"type":"string"}},"required":["message_id","content"]}`),
+	s.addTool(&mcp.Tool{
+		Name:         "edit_message",
+		Description:  "Edit an existing message",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"message_id":{"type":"string"},"content":{"type":"string"}},"required":["message_id","content"]}`),
+		OutputSchema: outputSchema[messageOutput](),
 	}, s.handleEditMessage)
+
+	// Reaction tools
+	s.addTool(&mcp.Tool{
+		Name:         "react_to_message",
+		Description:  "React to a message with an emoji or keyword (use \"ack\" to acknowledge) instead of posting a reply",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"message_id":{"type":"string"},"reaction":{"type":"string"},"remove":{"type":"boolean","description":"Remove the reaction instead of adding it"},"agent_name":{"type":"string"}},"required":["message_id","reaction"]}`),
+		OutputSchema: outputSchema[reactionOutput](),
+	}, s.handleReactToMessage)
+
+	s.addTool(&mcp.Tool{
+		Name:         "list_reactions",
+		Description:  "List reactions on a message and who made them, optionally only one reaction such as \"ack\"",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"message_id":{"type":"string"},"reaction":{"type":"string"}},"required":["message_id"]}`),
+		OutputSchema: outputSchema[reactionsOutput](),
+	}, s.handleListReactions)
+
+	// Inbox tools
+	s.addTool(&mcp.Tool{
+		Name:         "get_mentions",
+		Description:  "Get messages that @mention an agent or user, newest first",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"agent_name":{"type":"string","description":"Whose inbox to read (defaults to the session identity)"},"include_read":{"type":"boolean","description":"Include mentions already marked read"},"mark_read":{"type":"boolean","description":"Mark the returned mentions as read"}}}`),
+		OutputSchema: outputSchema[mentionsOutput](),
+	}, s.handleGetMentions)
+
+	s.addTool(&mcp.Tool{
+		Name:         "subscribe",
+		Description:  "Watch or unwatch a topic or thread so its activity appears in get_feed",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"target":{"type":"string","description":"Topic name, slug or ID, or thread ID prefix"},"kind":{"type":"string","enum":["topic","thread"],"description":"Force the target type (otherwise topic is tried first)"},"unsubscribe":{"type":"boolean","description":"Stop watching instead"},"agent_name":{"type":"string","description":"Whose watch list to change (defaults to the session identity)"}},"required":["target"]}`),
+		OutputSchema: outputSchema[subscriptionOutput](),
+	}, s.handleSubscribe)
+
+	s.addTool(&mcp.Tool{
+		Name:         "get_feed",
+		Description:  "Get new threads and posts on watched topics and threads since the last check, oldest first",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"agent_name":{"type":"string","description":"Whose feed to read (defaults to the session identity)"},"since":{"type":"string","description":"Show activity since this time (duration like 2h or 7d, date, or RFC 3339) instead of the last check"},"peek":{"type":"boolean","description":"Do not advance the last-check cursor"}}}`),
+		OutputSchema: outputSchema[feedOutput](),
+	}, s.handleGetFeed)
+
+	s.addTool(&mcp.Tool{
+		Name:         "get_recent_activity",
+		Description:  "Get thread creations, posts and edits across all topics, newest first",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"since":{"type":"string","description":"Only include activity after this time (duration like 2h or 7d, date, or RFC 3339)"},"limit":{"type":"integer","description":"Maximum number of entries (default 20, 0 for all)"}}}`),
+		OutputSchema: outputSchema[activityOutput](),
+	}, s.handleGetRecentActivity)
+
+	// Template tools
+	s.addTool(&mcp.Tool{
+		Name:         "list_templates",
+		Description:  "List message templates and their typed fields (global, plus a topic's own when topic is given)",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"topic":{"type":"string","description":"Include templates scoped to this topic"}}}`),
+		OutputSchema: outputSchema[templatesOutput](),
+	}, s.handleListTemplates)
+
+	s.addTool(&mcp.Tool{
+		Name:         "post_from_template",
+		Description:  "Post a message built from a template; fields are validated against the template's types",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"thread":{"type":"string"},"template":{"type":"string","description":"Template name"},"fields":{"type":"object","additionalProperties":{"type":"string"},"description":"Field values keyed by field name"},"agent_name":{"type":"string"}},"required":["thread","template","fields"]}`),
+		OutputSchema: outputSchema[messageOutput](),
+	}, s.handlePostFromTemplate)
+
+	s.addTool(&mcp.Tool{
+		Name:         "list_template_posts",
+		Description:  "Find messages posted from a template, optionally filtered by field values, newest first",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"template":{"type":"string"},"fields":{"type":"object","additionalProperties":{"type":"string"},"description":"Only posts whose fields have these values"},"topic":{"type":"string","description":"Only posts in this topic"}},"required":["template"]}`),
+		OutputSchema: outputSchema[templatePostsOutput](),
+	}, s.handleListTemplatePosts)
+
+	// Batch tool
+	s.addTool(&mcp.Tool{
+		Name:         "batch",
+		Description:  "Run several create_thread, post_message, sticky, archive and edit operations in order in one transaction; a later operation can name a record made by an earlier one as \"$N\" (N counts from 0)",
+		InputSchema:  json.RawMessage(`{"type":"object","properties":{"operations":{"type":"array","maxItems":50,"items":{"type":"object","properties":{"op":{"type":"string","enum":["create_thread","post_message","sticky","archive","edit"]},"topic":{"type":"string","description":"Topic for create_thread and archive"},"thread":{"type":"string","description":"Thread for post_message and sticky"},"message_id":{"type":"string","description":"Message for edit"},"subject":{"type":"string","description":"Subject for create_thread"},"content":{"type":"string","description":"Text for post_message and edit, or the opening post for create_thread"},"sticky":{"type":"boolean"},"archived":{"type":"boolean"}},"required":["op"]}},"agent_name":{"type":"string"}},"required":["operations"]}`),
+		OutputSchema: outputSchema[batchOutput](),
+	}, s.handleBatch)
 }
 
 func (s *Server) handleListTopics(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -79,30 +204,15 @@ func (s *Server) handleListTopics(ctx context.Context, req *mcp.CallToolRequest)
 		IncludeArchived bool `json:"include_archived"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("invalid arguments: %v", err)}},
-			IsError: true,
-		}, nil
+		return invalidArguments(err), nil
 	}
 
-	topics, err := s.client.ListTopics(args.IncludeArchived)
+	topics, err := s.listTopics(args.IncludeArchived)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
 
-	result, err := json.Marshal(topics)
-	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to marshal response: %v", err)}},
-			IsError: true,
-		}, nil
-	}
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(result)}},
-	}, nil
+	return toolResult(topicsText(topics), topicsOutput{Topics: topics}), nil
 }
 
 func (s *Server) handleCreateTopic(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -112,25 +222,20 @@ func (s *Server) handleCreateTopic(ctx context.Context, req *mcp.CallToolRequest
 		AgentName   string `json:"agent_name"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("invalid arguments: %v", err)}},
-			IsError: true,
-		}, nil
+		return invalidArguments(err), nil
 	}
 
-	id := identity.GetIdentity(args.AgentName, "mcp")
+	id, err := s.agentIdentity(req, args.AgentName)
+	if err != nil {
+		return toolError(err), nil
+	}
 	topic := models.NewTopic(args.Name, args.Description, id)
 
 	if err := s.client.CreateTopic(topic); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Created topic: %s (ID: %s)", args.Name, topic.ID.String()[:8])}},
-	}, nil
+	return toolResult(fmt.Sprintf("Created topic: %s (ID: %s)", topic.Name, topic.ID), topicOutput{Topic: topic}), nil
 }
 
 func (s *Server) handleArchiveTopic(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -139,34 +244,105 @@ func (s *Server) handleArchiveTopic(ctx context.Context, req *mcp.CallToolReques
 		Archived bool   `json:"archived"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("invalid arguments: %v", err)}},
-			IsError: true,
-		}, nil
+		return invalidArguments(err), nil
 	}
 
-	topic, err := s.client.ResolveTopic(args.Topic)
+	topic, err := s.resolveTopic(args.Topic)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
 
 	if err := s.client.ArchiveTopic(topic.ID, args.Archived); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
 
 	status := "archived"
 	if !args.Archived {
 		status = "unarchived"
 	}
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Topic %s", status)}},
-	}, nil
+	return s.topicResult(topic.ID, fmt.Sprintf("Topic %s: %s", status, topic.Name))
+}
+
+func (s *Server) handleRenameTopic(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Topic     string `json:"topic"`
+		Name      string `json:"name"`
+		AgentName string `json:"agent_name"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+
+	topic, err := s.resolveTopic(args.Topic)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	id, err := s.agentIdentity(req, args.AgentName)
+	if err != nil {
+		return toolError(err), nil
+	}
+	if err := s.client.RenameTopic(topic.ID, args.Name, id); err != nil {
+		return toolError(err), nil
+	}
+
+	return s.topicResult(topic.ID, fmt.Sprintf("Renamed topic: %s → %s", topic.Name, args.Name))
+}
+
+func (s *Server) handleSetTopicReadOnly(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Topic    string `json:"topic"`
+		ReadOnly bool   `json:"read_only"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+
+	topic, err := s.resolveTopic(args.Topic)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	// Moderation never takes agent_name: only a named token or the server identity can be a moderator
+	id, err := s.agentIdentity(req, "")
+	if err != nil {
+		return toolError(err), nil
+	}
+	if err := s.client.SetTopicReadOnly(topic.ID, args.ReadOnly, id); err != nil {
+		return toolError(err), nil
+	}
+
+	status := "read-only"
+	if !args.ReadOnly {
+		status = "writable"
+	}
+	return s.topicResult(topic.ID, fmt.Sprintf("Topic %s is now %s", topic.Name, status))
+}
+
+func (s *Server) handleDescribeTopic(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Topic       string `json:"topic"`
+		Description string `json:"description"`
+		AgentName   string `json:"agent_name"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+
+	topic, err := s.resolveTopic(args.Topic)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	id, err := s.agentIdentity(req, args.AgentName)
+	if err != nil {
+		return toolError(err), nil
+	}
+	if err := s.client.DescribeTopic(topic.ID, args.Description, id); err != nil {
+		return toolError(err), nil
+	}
+
+	return s.topicResult(topic.ID, fmt.Sprintf("Updated description of topic: %s", topic.Name))
 }
 
 func (s *Server) handleListThreads(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -174,38 +350,20 @@ func (s *Server) handleListThreads(ctx context.Context, req *mcp.CallToolRequest
 		Topic string `json:"topic"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("invalid arguments: %v", err)}},
-			IsError: true,
-		}, nil
+		return invalidArguments(err), nil
 	}
 
-	topic, err := s.client.ResolveTopic(args.Topic)
+	topic, err := s.resolveTopic(args.Topic)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
 
 	threads, err := s.client.ListThreads(topic.ID)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
 
-	result, err := json.Marshal(threads)
-	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to marshal response: %v", err)}},
-			IsError: true,
-		}, nil
-	}
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(result)}},
-	}, nil
+	return toolResult(threadsText(topic, threads), threadsOutput{Topic: topic, Threads: threads}), nil
 }
 
 func (s *Server) handleCreateThread(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -216,44 +374,29 @@ func (s *Server) handleCreateThread(ctx context.Context, req *mcp.CallToolReques
 		AgentName string `json:"agent_name"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("invalid arguments: %v", err)}},
-			IsError: true,
-		}, nil
+		return invalidArguments(err), nil
 	}
 
-	topic, err := s.client.ResolveTopic(args.Topic)
+	topic, err := s.resolveTopic(args.Topic)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
 
-	id := identity.GetIdentity(args.AgentName, "mcp")
+	id, err := s.agentIdentity(req, args.AgentName)
+	if err != nil {
+		return toolError(err), nil
+	}
 	thread := models.NewThread(topic.ID, args.Subject, id)
-
-	if err := s.client.CreateThread(thread); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+	out := threadOutput{Thread: thread}
+	if args.Message != "" {
+		out.Message = models.NewMessage(thread.ID, args.Message, id)
 	}
 
-	// Post initial message if provided
-	if args.Message != "" {
-		msg := models.NewMessage(thread.ID, args.Message, id)
-		if err := s.client.CreateMessage(msg); err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("thread created but failed to post message: %v", err)}},
-				IsError: true,
-			}, nil
-		}
+	if err := s.client.CreateThreadWithPost(thread, out.Message); err != nil {
+		return toolError(err), nil
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Created thread: %s (ID: %s)", args.Subject, thread.ID.String()[:8])}},
-	}, nil
+	return toolResult(fmt.Sprintf("Created thread: %s (ID: %s)", thread.Subject, thread.ID), out), nil
 }
 
 func (s *Server) handleStickyThread(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -262,73 +405,131 @@ func (s *Server) handleStickyThread(ctx context.Context, req *mcp.CallToolReques
 		Sticky bool   `json:"sticky"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("invalid arguments: %v", err)}},
-			IsError: true,
-		}, nil
+		return invalidArguments(err), nil
 	}
 
-	thread, err := s.client.ResolveThread(args.Thread)
+	thread, err := s.resolveThread(args.Thread)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
 
 	if err := s.client.SetThreadSticky(thread.ID, args.Sticky); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
 
 	status := "pinned"
 	if !args.Sticky {
 		status = "unpinned"
 	}
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Thread %s", status)}},
-	}, nil
+	return s.threadResult(thread.ID, fmt.Sprintf("Thread %s: %s", status, thread.Subject))
 }
 
-func (s *Server) handleListMessages(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (s *Server) handleLockThread(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var args struct {
 		Thread string `json:"thread"`
+		Locked bool   `json:"locked"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("invalid arguments: %v", err)}},
-			IsError: true,
-		}, nil
+		return invalidArguments(err), nil
 	}
 
-	thread, err := s.client.ResolveThread(args.Thread)
+	thread, err := s.resolveThread(args.Thread)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
 
-	messages, err := s.client.ListMessages(thread.ID)
+	// Moderation never takes agent_name: only a named token or the server identity can be a moderator
+	id, err := s.agentIdentity(req, "")
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
+	}
+	if err := s.client.SetThreadLocked(thread.ID, args.Locked, id); err != nil {
+		return toolError(err), nil
+	}
+
+	status := "locked"
+	if !args.Locked {
+		status = "unlocked"
+	}
+	return s.threadResult(thread.ID, fmt.Sprintf("Thread %s: %s", status, thread.Subject))
+}
+
+func (s *Server) handleRetitleThread(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Thread    string `json:"thread"`
+		Subject   string `json:"subject"`
+		AgentName string `json:"agent_name"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
 	}
 
-	result, err := json.Marshal(messages)
+	thread, err := s.resolveThread(args.Thread)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("failed to marshal response: %v", err)}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(result)}},
-	}, nil
+
+	id, err := s.agentIdentity(req, args.AgentName)
+	if err != nil {
+		return toolError(err), nil
+	}
+	if err := s.client.RetitleThread(thread.ID, args.Subject, id); err != nil {
+		return toolError(err), nil
+	}
+
+	return s.threadResult(thread.ID, fmt.Sprintf("Retitled thread: %s → %s", thread.Subject, args.Subject))
+}
+
+func (s *Server) handleMoveThread(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Thread    string `json:"thread"`
+		Topic     string `json:"topic"`
+		AgentName string `json:"agent_name"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+
+	thread, err := s.resolveThread(args.Thread)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	topic, err := s.resolveTopic(args.Topic)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	id, err := s.agentIdentity(req, args.AgentName)
+	if err != nil {
+		return toolError(err), nil
+	}
+	if err := s.client.MoveThread(thread.ID, topic.ID, id); err != nil {
+		return toolError(err), nil
+	}
+
+	return s.threadResult(thread.ID, fmt.Sprintf("Moved thread %q to topic: %s", thread.Subject, topic.Name))
+}
+
+func (s *Server) handleListMessages(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Thread string `json:"thread"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+
+	thread, err := s.resolveThread(args.Thread)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	messages, err := s.client.ListMessages(thread.ID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	return toolResult(messagesText(messages), messagesOutput{Thread: thread, Messages: messages}), nil
 }
 
 func (s *Server) handlePostMessage(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -338,33 +539,25 @@ func (s *Server) handlePostMessage(ctx context.Context, req *mcp.CallToolRequest
 		AgentName string `json:"agent_name"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("invalid arguments: %v", err)}},
-			IsError: true,
-		}, nil
+		return invalidArguments(err), nil
 	}
 
-	thread, err := s.client.ResolveThread(args.Thread)
+	thread, err := s.resolveThread(args.Thread)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
 
-	id := identity.GetIdentity(args.AgentName, "mcp")
+	id, err := s.agentIdentity(req, args.AgentName)
+	if err != nil {
+		return toolError(err), nil
+	}
 	msg := models.NewMessage(thread.ID, args.Content, id)
 
 	if err := s.client.CreateMessage(msg); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Posted message (ID: %s)", msg.ID.String()[:8])}},
-	}, nil
+	return toolResult(fmt.Sprintf("Posted message to %s (ID: %s)", thread.Subject, msg.ID), messageOutput{Message: msg}), nil
 }
 
 func (s *Server) handleEditMessage(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -373,18 +566,12 @@ func (s *Server) handleEditMessage(ctx context.Context, req *mcp.CallToolRequest
 		Content   string `json:"content"`
 	}
 	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("invalid arguments: %v", err)}},
-			IsError: true,
-		}, nil
+		return invalidArguments(err), nil
 	}
 
-	msg, err := s.client.ResolveMessage(args.MessageID)
+	msg, err := s.resolveMessage(args.MessageID)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
 	}
 
 	msg.Content = args.Content
@@ -392,13 +579,408 @@ func (s *Server) handleEditMessage(ctx context.Context, req *mcp.CallToolRequest
 	msg.EditedAt = &now
 
 	if err := s.client.UpdateMessage(msg); err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
-			IsError: true,
-		}, nil
+		return toolError(err), nil
+	}
+
+	return toolResult(fmt.Sprintf("Message updated (ID: %s)", msg.ID), messageOutput{Message: msg}), nil
+}
+
+func (s *Server) handleReactToMessage(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		MessageID string `json:"message_id"`
+		Reaction  string `json:"reaction"`
+		Remove    bool   `json:"remove"`
+		AgentName string `json:"agent_name"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+
+	msg, err := s.resolveMessage(args.MessageID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	reaction, err := models.NormalizeReaction(args.Reaction)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	id, err := s.agentIdentity(req, args.AgentName)
+	if err != nil {
+		return toolError(err), nil
+	}
+	if args.Remove {
+		err = s.client.RemoveReaction(msg.ID, reaction, id)
+	} else {
+		err = s.client.AddReaction(models.NewReaction(msg.ID, reaction, id))
+	}
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	reactions, err := s.client.ListReactions(msg.ID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	verb := "Reacted"
+	if args.Remove {
+		verb = "Removed reaction"
+	}
+	return toolResult(fmt.Sprintf("%s %s on message %s", verb, reaction, msg.ID), reactionOutput{
+		MessageID: msg.ID,
+		Reaction:  reaction,
+		Removed:   args.Remove,
+		Reactions: models.SummarizeReactions(reactions),
+	}), nil
+}
+
+func (s *Server) handleListReactions(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		MessageID string `json:"message_id"`
+		Reaction  string `json:"reaction"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: "Message updated"}},
-	}, nil
+	msg, err := s.resolveMessage(args.MessageID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	reactions, err := s.client.ListReactions(msg.ID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	counts := models.SummarizeReactions(reactions)
+	if args.Reaction != "" {
+		want, err := models.NormalizeReaction(args.Reaction)
+		if err != nil {
+			return toolError(err), nil
+		}
+		filtered := []models.ReactionCount{}
+		for _, rc := range counts {
+			if rc.Reaction == want {
+				filtered = append(filtered, rc)
+			}
+		}
+		counts = filtered
+	}
+
+	return toolResult(reactionsText(counts), reactionsOutput{MessageID: msg.ID, Reactions: counts}), nil
+}
+
+func (s *Server) handleGetMentions(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		AgentName   string `json:"agent_name"`
+		IncludeRead bool   `json:"include_read"`
+		MarkRead    bool   `json:"mark_read"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+
+	id, err := s.agentIdentity(req, args.AgentName)
+	if err != nil {
+		return toolError(err), nil
+	}
+	mentions, err := s.client.ListMentions(id, args.IncludeRead)
+	if err != nil {
+		return toolError(err), nil
+	}
+	mentions = s.scopeMentions(mentions)
+
+	if args.MarkRead && len(mentions) > 0 {
+		ids := make([]models.UUID, 0, len(mentions))
+		for _, m := range mentions {
+			ids = append(ids, m.MessageID)
+		}
+		if err := s.client.MarkMentionsRead(id, ids...); err != nil {
+			return toolError(err), nil
+		}
+	}
+
+	return toolResult(mentionsText(mentions), mentionsOutput{Identity: id, Mentions: mentions, MarkedRead: args.MarkRead && len(mentions) > 0}), nil
+}
+
+func (s *Server) handleSubscribe(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Target      string `json:"target"`
+		Kind        string `json:"kind"`
+		Unsubscribe bool   `json:"unsubscribe"`
+		AgentName   string `json:"agent_name"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+
+	kind, targetID, name, err := charm.ResolveWatchTargetWith(args.Target, args.Kind, s.resolveTopic, s.resolveThread)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	id, err := s.agentIdentity(req, args.AgentName)
+	if err != nil {
+		return toolError(err), nil
+	}
+	if args.Unsubscribe {
+		err = s.client.Unsubscribe(id, targetID)
+	} else {
+		err = s.client.Subscribe(id, kind, targetID)
+	}
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	action := "Watching"
+	if args.Unsubscribe {
+		action = "Stopped watching"
+	}
+	return toolResult(fmt.Sprintf("%s %s: %s (ID: %s)", action, kind, name, targetID), subscriptionOutput{
+		Identity: id,
+		Kind:     kind,
+		TargetID: targetID,
+		Name:     name,
+		Watching: !args.Unsubscribe,
+	}), nil
+}
+
+func (s *Server) handleGetFeed(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		AgentName string `json:"agent_name"`
+		Since     string `json:"since"`
+		Peek      bool   `json:"peek"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+
+	var since *time.Time
+	if args.Since != "" {
+		t, err := charm.ParseSince(args.Since, time.Now())
+		if err != nil {
+			return toolError(err), nil
+		}
+		since = &t
+	}
+
+	id, err := s.agentIdentity(req, args.AgentName)
+	if err != nil {
+		return toolError(err), nil
+	}
+	checkedAt := time.Now()
+	feed, err := s.client.GetFeed(id, since)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	if !args.Peek && since == nil {
+		if err := s.client.MarkFeedChecked(id, checkedAt); err != nil {
+			return toolError(err), nil
+		}
+	}
+
+	feed.Items = s.scopeActivity(feed.Items)
+	return toolResult(activityText(feed.Items), feedOutput{
+		Identity:      id,
+		Since:         feed.Since,
+		Subscriptions: feed.Subscriptions,
+		Items:         feed.Items,
+	}), nil
+}
+
+func (s *Server) handleGetRecentActivity(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := struct {
+		Since string `json:"since"`
+		Limit int    `json:"limit"`
+	}{Limit: 20}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+
+	var since time.Time
+	if args.Since != "" {
+		t, err := charm.ParseSince(args.Since, time.Now())
+		if err != nil {
+			return toolError(err), nil
+		}
+		since = t
+	}
+
+	items, err := s.recentActivity(since, args.Limit)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	return toolResult(activityText(items), activityOutput{Items: items}), nil
+}
+
+// topicResult re-reads a topic after a change and returns it.
+func (s *Server) topicResult(id models.UUID, text string) (*mcp.CallToolResult, error) {
+	topic, err := s.client.GetTopic(id)
+	if err != nil {
+		return toolError(err), nil
+	}
+	return toolResult(text, topicOutput{Topic: topic}), nil
+}
+
+// threadResult re-reads a thread after a change and returns it.
+func (s *Server) threadResult(id models.UUID, text string) (*mcp.CallToolResult, error) {
+	thread, err := s.client.GetThread(id)
+	if err != nil {
+		return toolError(err), nil
+	}
+	return toolResult(text, threadOutput{Thread: thread}), nil
+}
+
+// topicScope resolves an optional topic argument to a topic ID.
+func (s *Server) topicScope(topic string) (*models.UUID, error) {
+	if topic == "" {
+		return nil, nil
+	}
+	t, err := s.resolveTopic(topic)
+	if err != nil {
+		return nil, err
+	}
+	return &t.ID, nil
+}
+
+func (s *Server) handleListTemplates(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Topic string `json:"topic"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+
+	scope, err := s.topicScope(args.Topic)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	templates, err := s.client.ListTemplates(scope)
+	if err != nil {
+		return toolError(err), nil
+	}
+	templates = s.scopeTemplates(templates)
+
+	return toolResult(templatesText(templates), templatesOutput{Templates: templates}), nil
+}
+
+func (s *Server) handlePostFromTemplate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Thread    string            `json:"thread"`
+		Template  string            `json:"template"`
+		Fields    map[string]string `json:"fields"`
+		AgentName string            `json:"agent_name"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+
+	thread, err := s.resolveThread(args.Thread)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	tmpl, err := s.client.GetTemplate(args.Template, &thread.TopicID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	id, err := s.agentIdentity(req, args.AgentName)
+	if err != nil {
+		return toolError(err), nil
+	}
+	msg, err := tmpl.NewMessage(thread.ID, args.Fields, id)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	if err := s.client.CreateMessage(msg); err != nil {
+		return toolError(err), nil
+	}
+
+	return toolResult(fmt.Sprintf("Posted %s to %s (ID: %s)", tmpl.Name, thread.Subject, msg.ID), messageOutput{Message: msg}), nil
+}
+
+func (s *Server) handleListTemplatePosts(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Template string            `json:"template"`
+		Fields   map[string]string `json:"fields"`
+		Topic    string            `json:"topic"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+
+	scope, err := s.topicScope(args.Topic)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	messages, err := s.client.ListTemplatePosts(args.Template, args.Fields, scope)
+	if err != nil {
+		return toolError(err), nil
+	}
+	messages = s.scopeMessages(messages)
+
+	return toolResult(messagesText(messages), templatePostsOutput{Template: args.Template, Messages: messages}), nil
+}
+
+// maxBatchOps caps the operations in one batch call.
+const maxBatchOps = 50
+
+func (s *Server) handleBatch(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Operations []struct {
+			Op        string `json:"op"`
+			Topic     string `json:"topic"`
+			Thread    string `json:"thread"`
+			MessageID string `json:"message_id"`
+			Subject   string `json:"subject"`
+			Content   string `json:"content"`
+			Sticky    bool   `json:"sticky"`
+			Archived  bool   `json:"archived"`
+		} `json:"operations"`
+		AgentName string `json:"agent_name"`
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+		return invalidArguments(err), nil
+	}
+	if len(args.Operations) == 0 || len(args.Operations) > maxBatchOps {
+		return invalidArguments(fmt.Errorf("operations must list 1 to %d operations", maxBatchOps)), nil
+	}
+
+	ops := make([]charm.BatchOp, len(args.Operations))
+	for i, op := range args.Operations {
+		ops[i] = charm.BatchOp{
+			Op:       op.Op,
+			Topic:    op.Topic,
+			Thread:   op.Thread,
+			Message:  op.MessageID,
+			Subject:  op.Subject,
+			Content:  op.Content,
+			Sticky:   op.Sticky,
+			Archived: op.Archived,
+		}
+	}
+
+	id, err := s.agentIdentity(req, args.AgentName)
+	if err != nil {
+		return toolError(err), nil
+	}
+	results, err := s.client.Batch(ops, id)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	out := batchOutput{Results: make([]batchResult, len(results))}
+	for i, r := range results {
+		out.Results[i] = batchResult{Op: r.Op, Topic: r.Topic, Thread: r.Thread, Message: r.Message}
+	}
+	return toolResult(batchText(out.Results), out), nil
 }
@@ -0,0 +1,139 @@
+// ABOUTME: Three-way merges of topics, threads and messages edited on two devices
+// ABOUTME: Additive fields combine; a field changed differently on both sides is a conflict
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrMergeConflict is returned when both sides changed the same field to
+// different values.
+var ErrMergeConflict = errors.New("conflicting changes")
+
+// merger collects the fields a three-way merge could not settle.
+type merger struct {
+	fields []string
+}
+
+// pick returns the side that changed field from base, or local when both
+// changed it to different values, which is recorded as a conflict.
+func pick[T comparable](m *merger, field string, base, local, remote T) T {
+	switch {
+	case local == remote, local == base:
+		return remote
+	case remote == base:
+		return local
+	}
+	m.fields = append(m.fields, field)
+	return local
+}
+
+func (m *merger) err() error {
+	if len(m.fields) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrMergeConflict, strings.Join(m.fields, ", "))
+}
+
+// later returns the later of two optional times.
+func later(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.After(*a)) {
+		return b
+	}
+	return a
+}
+
+// mergeHistory combines two change logs, oldest first, without duplicates.
+func mergeHistory(local, remote []Change) []Change {
+	merged := slices.Clone(remote)
+	for _, c := range local {
+		if !slices.ContainsFunc(merged, func(o Change) bool {
+			return o.Field == c.Field && o.ChangedBy == c.ChangedBy && o.ChangedAt.Equal(c.ChangedAt)
+		}) {
+			merged = append(merged, c)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].ChangedAt.Before(merged[j].ChangedAt)
+	})
+	return merged
+}
+
+// mergeVersion returns the version of a merge of local and remote.
+func mergeVersion(local, remote uint64) uint64 {
+	return max(local, remote) + 1
+}
+
+// MergeTopic merges topic edits made locally and remotely since base.
+// The change history is combined; the other fields may differ on one side only.
+func MergeTopic(base, local, remote *Topic) (*Topic, error) {
+	var m merger
+	merged := *remote
+	merged.Name = pick(&m, "Name", base.Name, local.Name, remote.Name)
+	merged.Slug = pick(&m, "Slug", base.Slug, local.Slug, remote.Slug)
+	merged.Description = pick(&m, "Description", base.Description, local.Description, remote.Description)
+	merged.Archived = pick(&m, "Archived", base.Archived, local.Archived, remote.Archived)
+	merged.ReadOnly = pick(&m, "ReadOnly", base.ReadOnly, local.ReadOnly, remote.ReadOnly)
+	merged.History = mergeHistory(local.History, remote.History)
+	if later(local.UpdatedAt, remote.UpdatedAt) == local.UpdatedAt {
+		merged.UpdatedAt, merged.UpdatedBy = local.UpdatedAt, local.UpdatedBy
+	}
+	merged.Version = mergeVersion(local.Version, remote.Version)
+	return &merged, m.err()
+}
+
+// MergeThread merges thread edits made locally and remotely since base.
+// Posts on both sides add up: message counts combine, participants are
+// unioned and the latest post wins LastPoster.
+func MergeThread(base, local, remote *Thread) (*Thread, error) {
+	var m merger
+	merged := *remote
+	merged.TopicID = pick(&m, "TopicID", base.TopicID, local.TopicID, remote.TopicID)
+	merged.Subject = pick(&m, "Subject", base.Subject, local.Subject, remote.Subject)
+	merged.Sticky = pick(&m, "Sticky", base.Sticky, local.Sticky, remote.Sticky)
+	merged.Locked = pick(&m, "Locked", base.Locked, local.Locked, remote.Locked)
+	merged.History = mergeHistory(local.History, remote.History)
+	if later(local.UpdatedAt, remote.UpdatedAt) == local.UpdatedAt {
+		merged.UpdatedAt, merged.UpdatedBy = local.UpdatedAt, local.UpdatedBy
+	}
+
+	merged.MessageCount = max(remote.MessageCount+local.MessageCount-base.MessageCount, 0)
+	if local.LastActivityAt.After(remote.LastActivityAt) {
+		merged.LastActivityAt, merged.LastPoster = local.LastActivityAt, local.LastPoster
+	}
+	merged.Participants = slices.Clone(remote.Participants)
+	for _, p := range local.Participants {
+		if !slices.Contains(merged.Participants, p) {
+			merged.Participants = append(merged.Participants, p)
+		}
+	}
+	merged.Version = mergeVersion(local.Version, remote.Version)
+	return &merged, m.err()
+}
+
+// MergeMessage merges message edits made locally and remotely since base.
+// Content, template and fields may differ on one side only.
+func MergeMessage(base, local, remote *Message) (*Message, error) {
+	var m merger
+	merged := *remote
+	merged.ThreadID = pick(&m, "ThreadID", base.ThreadID, local.ThreadID, remote.ThreadID)
+	merged.Content = pick(&m, "Content", base.Content, local.Content, remote.Content)
+	merged.Template = pick(&m, "Template", base.Template, local.Template, remote.Template)
+	switch {
+	case maps.Equal(local.Fields, remote.Fields), maps.Equal(local.Fields, base.Fields):
+	case maps.Equal(remote.Fields, base.Fields):
+		merged.Fields = local.Fields
+	default:
+		m.fields = append(m.fields, "Fields")
+	}
+	merged.EditedAt = later(local.EditedAt, remote.EditedAt)
+	merged.Version = mergeVersion(local.Version, remote.Version)
+	return &merged, m.err()
+}
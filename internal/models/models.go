@@ -4,7 +4,14 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 )
@@ -20,23 +27,41 @@ func ParseUUID(s string) (uuid.UUID, error) {
 type Topic struct {
 	ID          uuid.UUID
 	Name        string
+	Slug        string
 	Description string
 	CreatedAt   time.Time
 	CreatedBy   string
 	Archived    bool
+	ReadOnly    bool
+	UpdatedAt   *time.Time
+	UpdatedBy   string
+	History     []Change
+	Version     uint64 // bumped on every write
 }
 
 // Thread represents a discussion within a topic.
+// LastActivityAt, MessageCount, LastPoster and Participants are
+// denormalized from the thread's messages and kept current on every post.
 type Thread struct {
-	ID        uuid.UUID
-	TopicID   uuid.UUID
-	Subject   string
-	CreatedAt time.Time
-	CreatedBy string
-	Sticky    bool
+	ID             uuid.UUID
+	TopicID        uuid.UUID
+	Subject        string
+	CreatedAt      time.Time
+	CreatedBy      string
+	Sticky         bool
+	Locked         bool
+	UpdatedAt      *time.Time
+	UpdatedBy      string
+	History        []Change
+	LastActivityAt time.Time
+	MessageCount   int
+	LastPoster     string
+	Participants   []string
+	Version        uint64 // bumped on every write
 }
 
 // Message represents a post within a thread.
+// Messages posted from a template keep the template name and field values.
 type Message struct {
 	ID        uuid.UUID
 	ThreadID  uuid.UUID
@@ -44,6 +69,81 @@ type Message struct {
 	CreatedAt time.Time
 	CreatedBy string
 	EditedAt  *time.Time
+	Template  string
+	Fields    map[string]string
+	Version   uint64 // bumped on every write
+}
+
+// Change records a single field update made to a topic or thread.
+type Change struct {
+	Field     string
+	From      string
+	To        string
+	ChangedBy string
+	ChangedAt time.Time
+}
+
+// Reaction records one identity reacting to a message with an emoji or keyword.
+type Reaction struct {
+	MessageID uuid.UUID
+	Reaction  string
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// ReactionCount aggregates all identities that used the same reaction on a message.
+type ReactionCount struct {
+	Reaction string
+	Count    int
+	By       []string
+}
+
+// Mention is an inbox entry created when a message mentions @username.
+type Mention struct {
+	Username    string
+	MessageID   uuid.UUID
+	ThreadID    uuid.UUID
+	MentionedBy string
+	Excerpt     string
+	CreatedAt   time.Time
+	ReadAt      *time.Time
+}
+
+// Subscription kinds.
+const (
+	WatchTopic  = "topic"
+	WatchThread = "thread"
+)
+
+// Subscription marks a topic or thread an identity wants in its feed.
+type Subscription struct {
+	Username  string
+	Kind      string
+	TargetID  uuid.UUID
+	CreatedAt time.Time
+}
+
+// Activity kinds.
+const (
+	ActivityThreadCreated = "thread_created"
+	ActivityMessagePosted = "message_posted"
+	ActivityMessageEdited = "message_edited"
+)
+
+// Activity is one entry in a feed: a thread being started or a message being posted or edited.
+// MessageID is the nil UUID for thread activity.
+type Activity struct {
+	Kind      string
+	At        time.Time
+	Actor     string
+	TopicID   uuid.UUID
+	Topic     string
+	ThreadID  uuid.UUID
+	Subject   string
+	MessageID uuid.UUID
+	Excerpt   string
+	Locked    bool // the thread is locked
+	ReadOnly  bool // the topic is read-only
 }
 
 // Attachment represents a file attached to a message.
@@ -58,7 +158,7 @@ type Attachment struct {
 
 // NewTopic creates a new topic with generated UUID and timestamp.
 func NewTopic(name, description, createdBy string) *Topic {
-	return &Topic{
+	t := &Topic{
 		ID:          uuid.New(),
 		Name:        name,
 		Description: description,
@@ -66,6 +166,47 @@ func NewTopic(name, description, createdBy string) *Topic {
 		CreatedBy:   createdBy,
 		Archived:    false,
 	}
+	t.EnsureSlug()
+	return t
+}
+
+// EnsureSlug fills in the slug for topics created before slugs existed.
+// Names without any ASCII letters or digits fall back to the short ID.
+func (t *Topic) EnsureSlug() {
+	if t.Slug != "" {
+		return
+	}
+	t.Slug = Slugify(t.Name)
+	if t.Slug == "" {
+		t.Slug = t.ID.String()[:8]
+	}
+}
+
+// Slugify converts a topic name into a lowercase, URL-safe slug.
+// Runs of characters other than ASCII letters and digits collapse into a single hyphen.
+func Slugify(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingHyphen = true
+	}
+	return b.String()
+}
+
+// RecordChange appends a change to the topic's history and marks who updated it.
+func (t *Topic) RecordChange(field, from, to, by string) {
+	now := time.Now()
+	t.History = append(t.History, Change{Field: field, From: from, To: to, ChangedBy: by, ChangedAt: now})
+	t.UpdatedAt = &now
+	t.UpdatedBy = by
 }
 
 // NewThread creates a new thread with generated UUID and timestamp.
@@ -80,6 +221,61 @@ func NewThread(topicID uuid.UUID, subject, createdBy string) *Thread {
 	}
 }
 
+// RecordChange appends a change to the thread's history and marks who updated it.
+func (t *Thread) RecordChange(field, from, to, by string) {
+	now := time.Now()
+	t.History = append(t.History, Change{Field: field, From: from, To: to, ChangedBy: by, ChangedAt: now})
+	t.UpdatedAt = &now
+	t.UpdatedBy = by
+}
+
+// RecordPost updates the thread's activity fields for a new message.
+func (t *Thread) RecordPost(m *Message) {
+	t.MessageCount++
+	if !m.CreatedAt.Before(t.LastActivityAt) {
+		t.LastActivityAt = m.CreatedAt
+		t.LastPoster = m.CreatedBy
+	}
+	if !slices.Contains(t.Participants, m.CreatedBy) {
+		t.Participants = append(t.Participants, m.CreatedBy)
+	}
+}
+
+// RebuildStats recomputes the thread's activity fields from all of its messages.
+func (t *Thread) RebuildStats(messages []*Message) {
+	sorted := slices.Clone(messages)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	t.LastActivityAt = time.Time{}
+	t.MessageCount = 0
+	t.LastPoster = ""
+	t.Participants = nil
+	for _, m := range sorted {
+		t.RecordPost(m)
+	}
+}
+
+// LastActive returns when the thread last saw a post, or its creation time
+// if it has none.
+func (t *Thread) LastActive() time.Time {
+	if t.LastActivityAt.After(t.CreatedAt) {
+		return t.LastActivityAt
+	}
+	return t.CreatedAt
+}
+
+// SortThreadsByActivity orders threads sticky first, then most recently active first.
+func SortThreadsByActivity(threads []*Thread) {
+	sort.SliceStable(threads, func(i, j int) bool {
+		if threads[i].Sticky != threads[j].Sticky {
+			return threads[i].Sticky
+		}
+		return threads[i].LastActive().After(threads[j].LastActive())
+	})
+}
+
 // NewMessage creates a new message with generated UUID and timestamp.
 func NewMessage(threadID uuid.UUID, content, createdBy string) *Message {
 	return &Message{
@@ -91,6 +287,137 @@ func NewMessage(threadID uuid.UUID, content, createdBy string) *Message {
 	}
 }
 
+// NewReaction creates a new reaction with the current timestamp.
+func NewReaction(messageID uuid.UUID, reaction, createdBy string) *Reaction {
+	return &Reaction{
+		MessageID: messageID,
+		Reaction:  reaction,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+}
+
+// MaxReactionLength bounds the size of a reaction keyword in bytes.
+const MaxReactionLength = 32
+
+// ErrInvalidReaction is returned for empty, oversized, or multi-word reactions.
+var ErrInvalidReaction = errors.New("invalid reaction")
+
+// reactionAliases maps common shortcodes to the emoji they stand for.
+var reactionAliases = map[string]string{
+	"+1":         "👍",
+	"thumbsup":   "👍",
+	"-1":         "👎",
+	"thumbsdown": "👎",
+	"eyes":       "👀",
+	"check":      "✅",
+	"heart":      "❤️",
+	"tada":       "🎉",
+}
+
+// NormalizeReaction cleans up a reaction so that ":+1:", "+1" and "👍" are stored alike.
+// Keywords such as "ack" are lowercased and kept as-is.
+func NormalizeReaction(s string) (string, error) {
+	r := strings.TrimSpace(s)
+	if len(r) > 2 && strings.HasPrefix(r, ":") && strings.HasSuffix(r, ":") {
+		r = r[1 : len(r)-1]
+	}
+	r = strings.ToLower(r)
+	if alias, ok := reactionAliases[r]; ok {
+		r = alias
+	}
+
+	switch {
+	case r == "":
+		return "", fmt.Errorf("%w: empty", ErrInvalidReaction)
+	case len(r) > MaxReactionLength:
+		return "", fmt.Errorf("%w: longer than %d bytes", ErrInvalidReaction, MaxReactionLength)
+	case strings.ContainsAny(r, " \t\n:"):
+		return "", fmt.Errorf("%w: %q must be a single emoji or word", ErrInvalidReaction, s)
+	}
+	return r, nil
+}
+
+// SummarizeReactions groups reactions by kind, most used first.
+// Ties keep the order in which each reaction was first used.
+func SummarizeReactions(reactions []*Reaction) []ReactionCount {
+	sorted := make([]*Reaction, len(reactions))
+	copy(sorted, reactions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	index := make(map[string]int)
+	var counts []ReactionCount
+	for _, r := range sorted {
+		i, ok := index[r.Reaction]
+		if !ok {
+			i = len(counts)
+			index[r.Reaction] = i
+			counts = append(counts, ReactionCount{Reaction: r.Reaction})
+		}
+		counts[i].Count++
+		counts[i].By = append(counts[i].By, r.CreatedBy)
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].Count > counts[j].Count
+	})
+	return counts
+}
+
+// mentionPattern matches @username when it is not part of an email address or word.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// ParseMentions returns the lowercase usernames mentioned in content, in order and without duplicates.
+func ParseMentions(content string) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, m := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		username := strings.ToLower(strings.TrimRight(m[1], ".-_"))
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
+
+// MaxExcerptLength bounds the message text copied into mentions and feeds.
+const MaxExcerptLength = 140
+
+// Excerpt collapses whitespace and truncates content to MaxExcerptLength runes.
+func Excerpt(content string) string {
+	excerpt := strings.Join(strings.Fields(content), " ")
+	if r := []rune(excerpt); len(r) > MaxExcerptLength {
+		excerpt = string(r[:MaxExcerptLength]) + "…"
+	}
+	return excerpt
+}
+
+// NewMention creates an unread inbox entry for username pointing at msg.
+func NewMention(username string, msg *Message) *Mention {
+	return &Mention{
+		Username:    strings.ToLower(username),
+		MessageID:   msg.ID,
+		ThreadID:    msg.ThreadID,
+		MentionedBy: msg.CreatedBy,
+		Excerpt:     Excerpt(msg.Content),
+		CreatedAt:   time.Now(),
+	}
+}
+
+// NewSubscription creates a subscription for username to a topic or thread.
+func NewSubscription(username, kind string, targetID uuid.UUID) *Subscription {
+	return &Subscription{
+		Username:  strings.ToLower(username),
+		Kind:      kind,
+		TargetID:  targetID,
+		CreatedAt: time.Now(),
+	}
+}
+
 // NewAttachment creates a new attachment with generated UUID and timestamp.
 func NewAttachment(messageID uuid.UUID, filename, mimeType string, data []byte) *Attachment {
 	return &Attachment{
@@ -4,7 +4,11 @@
 package models
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -55,3 +59,409 @@ func TestNewMessage(t *testing.T) {
 		t.Errorf("expected createdBy 'claude@mcp', got '%s'", msg.CreatedBy)
 	}
 }
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"general", "general"},
+		{"General", "general"},
+		{"Release Notes", "release-notes"},
+		{"  ops / incidents!! ", "ops-incidents"},
+		{"v2.0 launch", "v2-0-launch"},
+		{"🚀", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.name); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTopicSlug(t *testing.T) {
+	topic := NewTopic("Release Notes", "", "harper@cli")
+	if topic.Slug != "release-notes" {
+		t.Errorf("expected slug 'release-notes', got '%s'", topic.Slug)
+	}
+
+	emoji := NewTopic("🚀", "", "harper@cli")
+	if emoji.Slug != emoji.ID.String()[:8] {
+		t.Errorf("expected slug to fall back to short ID, got '%s'", emoji.Slug)
+	}
+}
+
+func TestRecordChange(t *testing.T) {
+	thread := NewThread(uuid.New(), "Typo subjcet", "harper@cli")
+	thread.RecordChange("Subject", "Typo subjcet", "Typo subject", "claude@mcp")
+
+	if thread.UpdatedAt == nil {
+		t.Fatal("expected UpdatedAt to be set")
+	}
+	if thread.UpdatedBy != "claude@mcp" {
+		t.Errorf("expected UpdatedBy 'claude@mcp', got '%s'", thread.UpdatedBy)
+	}
+	if len(thread.History) != 1 {
+		t.Fatalf("expected 1 history entry, got %d", len(thread.History))
+	}
+	change := thread.History[0]
+	if change.Field != "Subject" || change.From != "Typo subjcet" || change.To != "Typo subject" {
+		t.Errorf("unexpected change recorded: %+v", change)
+	}
+}
+
+func TestNormalizeReaction(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"ack", "ack", false},
+		{" ACK ", "ack", false},
+		{"+1", "👍", false},
+		{":+1:", "👍", false},
+		{":eyes:", "👀", false},
+		{"🚀", "🚀", false},
+		{"", "", true},
+		{"two words", "", true},
+		{"a:b", "", true},
+		{"averyveryveryveryverylongreactionword", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := NormalizeReaction(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidReaction) {
+					t.Errorf("expected ErrInvalidReaction, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeReaction(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeReactions(t *testing.T) {
+	msgID := uuid.New()
+	base := time.Now()
+	reaction := func(r, by string, offset int) *Reaction {
+		rr := NewReaction(msgID, r, by)
+		rr.CreatedAt = base.Add(time.Duration(offset) * time.Second)
+		return rr
+	}
+
+	counts := SummarizeReactions([]*Reaction{
+		reaction("👀", "harper@cli", 0),
+		reaction("ack", "claude@mcp", 1),
+		reaction("ack", "harper@cli", 2),
+		reaction("👍", "bot@mcp", 3),
+	})
+
+	if len(counts) != 3 {
+		t.Fatalf("expected 3 reaction kinds, got %d", len(counts))
+	}
+	if counts[0].Reaction != "ack" || counts[0].Count != 2 {
+		t.Errorf("expected ack x2 first, got %+v", counts[0])
+	}
+	if counts[0].By[0] != "claude@mcp" {
+		t.Errorf("expected earliest reactor first, got %v", counts[0].By)
+	}
+	if counts[1].Reaction != "👀" || counts[2].Reaction != "👍" {
+		t.Errorf("expected ties in first-used order, got %+v", counts)
+	}
+}
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"@harper can you look?", []string{"harper"}},
+		{"cc @Harper and @ops-bot.", []string{"harper", "ops-bot"}},
+		{"(@claude) @harper @HARPER", []string{"claude", "harper"}},
+		{"mail harper@example.com", nil},
+		{"no mentions here", nil},
+		{"@", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			if got := ParseMentions(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMentions(%q) = %v, want %v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMentionExcerpt(t *testing.T) {
+	msg := NewMessage(uuid.New(), "hey @harper\n\n"+strings.Repeat("x", 200), "claude@mcp")
+	mention := NewMention("Harper", msg)
+
+	if mention.Username != "harper" {
+		t.Errorf("expected lowercase username, got '%s'", mention.Username)
+	}
+	if mention.ReadAt != nil {
+		t.Error("expected new mention to be unread")
+	}
+	if strings.Contains(mention.Excerpt, "\n") {
+		t.Error("expected excerpt to collapse whitespace")
+	}
+	if len([]rune(mention.Excerpt)) != MaxExcerptLength+1 {
+		t.Errorf("expected excerpt truncated to %d runes plus ellipsis, got %d", MaxExcerptLength, len([]rune(mention.Excerpt)))
+	}
+}
+
+func TestThreadRecordPost(t *testing.T) {
+	thread := NewThread(uuid.New(), "Standup", "harper@cli")
+	base := thread.CreatedAt
+
+	first := &Message{ID: uuid.New(), CreatedBy: "harper@cli", CreatedAt: base.Add(time.Minute)}
+	second := &Message{ID: uuid.New(), CreatedBy: "claude@mcp", CreatedAt: base.Add(2 * time.Minute)}
+	third := &Message{ID: uuid.New(), CreatedBy: "harper@cli", CreatedAt: base.Add(3 * time.Minute)}
+
+	for _, m := range []*Message{first, second, third} {
+		thread.RecordPost(m)
+	}
+
+	if thread.MessageCount != 3 {
+		t.Errorf("MessageCount = %d, want 3", thread.MessageCount)
+	}
+	if thread.LastPoster != "harper@cli" || !thread.LastActivityAt.Equal(third.CreatedAt) {
+		t.Errorf("last = %s at %v, want harper@cli at %v", thread.LastPoster, thread.LastActivityAt, third.CreatedAt)
+	}
+	if !reflect.DeepEqual(thread.Participants, []string{"harper@cli", "claude@mcp"}) {
+		t.Errorf("Participants = %v", thread.Participants)
+	}
+
+	// Rebuilding from the remaining messages in any order gives the same stats.
+	thread.RebuildStats([]*Message{second, first})
+	if thread.MessageCount != 2 || thread.LastPoster != "claude@mcp" {
+		t.Errorf("after rebuild: count=%d last=%s", thread.MessageCount, thread.LastPoster)
+	}
+	if !reflect.DeepEqual(thread.Participants, []string{"harper@cli", "claude@mcp"}) {
+		t.Errorf("after rebuild: Participants = %v", thread.Participants)
+	}
+}
+
+func TestSortThreadsByActivity(t *testing.T) {
+	base := time.Date(2025, 12, 20, 12, 0, 0, 0, time.UTC)
+	quiet := &Thread{Subject: "quiet", CreatedAt: base.Add(time.Hour)}
+	busy := &Thread{Subject: "busy", CreatedAt: base, LastActivityAt: base.Add(2 * time.Hour)}
+	pinned := &Thread{Subject: "pinned", CreatedAt: base, Sticky: true}
+
+	threads := []*Thread{quiet, pinned, busy}
+	SortThreadsByActivity(threads)
+
+	var got []string
+	for _, th := range threads {
+		got = append(got, th.Subject)
+	}
+	want := []string{"pinned", "busy", "quiet"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func standupTemplate(t *testing.T) *Template {
+	t.Helper()
+	var fields []TemplateField
+	for _, spec := range []string{"today:text!", "status:enum=green|yellow|red!", "hours:number", "blocked:bool", "eta:date"} {
+		f, err := ParseFieldSpec(spec)
+		if err != nil {
+			t.Fatalf("ParseFieldSpec(%q): %v", spec, err)
+		}
+		fields = append(fields, f)
+	}
+	tmpl := NewTemplate("Standup", "Daily standup", fields, "", "harper@cli")
+	if err := tmpl.Check(); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	return tmpl
+}
+
+func TestParseFieldSpec(t *testing.T) {
+	f, err := ParseFieldSpec("status:enum=green|yellow|red!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := TemplateField{Name: "status", Type: FieldEnum, Required: true, Options: []string{"green", "yellow", "red"}}
+	if !reflect.DeepEqual(f, want) {
+		t.Errorf("ParseFieldSpec = %+v, want %+v", f, want)
+	}
+
+	f, _ = ParseFieldSpec("Notes")
+	if f.Name != "notes" || f.Type != FieldString || f.Required {
+		t.Errorf("default spec = %+v", f)
+	}
+}
+
+func TestTemplateCheck(t *testing.T) {
+	bad := []*Template{
+		{Name: "Has Space", Fields: []TemplateField{{Name: "a", Type: FieldString}}},
+		{Name: "empty"},
+		{Name: "dup", Fields: []TemplateField{{Name: "a", Type: FieldString}, {Name: "a", Type: FieldText}}},
+		{Name: "enum", Fields: []TemplateField{{Name: "a", Type: FieldEnum}}},
+		{Name: "type", Fields: []TemplateField{{Name: "a", Type: "color"}}},
+		{Name: "body", Fields: []TemplateField{{Name: "a", Type: FieldString}}, Body: "{{b}}"},
+	}
+	for _, tmpl := range bad {
+		if err := tmpl.Check(); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("Check(%s) = %v, want ErrInvalidTemplate", tmpl.Name, err)
+		}
+	}
+}
+
+func TestTemplateValidate(t *testing.T) {
+	tmpl := standupTemplate(t)
+
+	got, err := tmpl.Validate(map[string]string{"today": " ship it ", "status": "GREEN", "hours": "7.50", "blocked": "no", "eta": "2025-12-24"})
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	want := map[string]string{"today": "ship it", "status": "green", "hours": "7.5", "blocked": "false", "eta": "2025-12-24"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate = %v, want %v", got, want)
+	}
+
+	_, err = tmpl.Validate(map[string]string{"status": "purple", "hours": "lots", "mood": "ok"})
+	if !errors.Is(err, ErrInvalidFields) {
+		t.Fatalf("expected ErrInvalidFields, got %v", err)
+	}
+	for _, part := range []string{"today is required", "status:", "hours:", `unknown field "mood"`} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("error %q should mention %q", err, part)
+		}
+	}
+}
+
+func TestTemplateNewMessage(t *testing.T) {
+	tmpl := standupTemplate(t)
+	threadID := uuid.New()
+
+	msg, err := tmpl.NewMessage(threadID, map[string]string{"today": "templates", "status": "yellow"}, "claude@mcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Template != "standup" || msg.Fields["status"] != "yellow" || msg.ThreadID != threadID {
+		t.Errorf("message metadata = %q %v", msg.Template, msg.Fields)
+	}
+	if msg.Content != "**Today**\n\ntemplates\n\n**Status:** yellow" {
+		t.Errorf("default render = %q", msg.Content)
+	}
+	if !msg.MatchesFields(map[string]string{"status": "Yellow"}) || msg.MatchesFields(map[string]string{"status": "red"}) {
+		t.Error("MatchesFields should compare field values case-insensitively")
+	}
+
+	tmpl.Body = "Status {{ status }}: {{today}}"
+	msg, _ = tmpl.NewMessage(threadID, map[string]string{"today": "templates", "status": "red"}, "claude@mcp")
+	if msg.Content != "Status red: templates" {
+		t.Errorf("body render = %q", msg.Content)
+	}
+}
+
+func TestMergeThread(t *testing.T) {
+	base := NewThread(uuid.New(), "Deploy", "harper@cli")
+	base.Participants = []string{"harper@cli"}
+	base.MessageCount = 1
+	base.Version = 3
+
+	local := *base
+	local.Participants = []string{"harper@cli", "claude@mcp"}
+	local.MessageCount = 2
+	local.LastActivityAt = time.Now()
+	local.LastPoster = "claude@mcp"
+	local.Sticky = true
+	local.Version = 4
+
+	remote := *base
+	remote.Participants = []string{"harper@cli", "dylan@cli"}
+	remote.MessageCount = 3
+	remote.LastActivityAt = time.Now().Add(-time.Minute)
+	remote.LastPoster = "dylan@cli"
+	remote.Subject = "Deploy Friday"
+	remote.Version = 5
+
+	merged, err := MergeThread(base, &local, &remote)
+	if err != nil {
+		t.Fatalf("MergeThread: %v", err)
+	}
+	if merged.MessageCount != 4 {
+		t.Errorf("MessageCount = %d, want posts from both sides (4)", merged.MessageCount)
+	}
+	if !reflect.DeepEqual(merged.Participants, []string{"harper@cli", "dylan@cli", "claude@mcp"}) {
+		t.Errorf("Participants = %v", merged.Participants)
+	}
+	if merged.LastPoster != "claude@mcp" || !merged.Sticky || merged.Subject != "Deploy Friday" {
+		t.Errorf("merged = %+v, want the latest post and both one-sided edits", merged)
+	}
+	if merged.Version != 6 {
+		t.Errorf("Version = %d, want one past both sides (6)", merged.Version)
+	}
+
+	local.Subject = "Deploy Monday"
+	if _, err := MergeThread(base, &local, &remote); !errors.Is(err, ErrMergeConflict) || !strings.Contains(err.Error(), "Subject") {
+		t.Errorf("both sides retitling should conflict on Subject, got %v", err)
+	}
+}
+
+func TestMergeTopic(t *testing.T) {
+	base := NewTopic("general", "", "harper@cli")
+	earlier := time.Now().Add(-time.Hour)
+	now := time.Now()
+
+	local := *base
+	local.RecordChange("Description", "", "chat", "harper@cli")
+	local.Description = "chat"
+	local.UpdatedAt = &now
+
+	remote := *base
+	remote.Archived = true
+	remote.History = []Change{{Field: "Archived", From: "false", To: "true", ChangedBy: "dylan@cli", ChangedAt: earlier}}
+	remote.UpdatedAt = &earlier
+	remote.UpdatedBy = "dylan@cli"
+
+	merged, err := MergeTopic(base, &local, &remote)
+	if err != nil {
+		t.Fatalf("MergeTopic: %v", err)
+	}
+	if merged.Description != "chat" || !merged.Archived {
+		t.Errorf("merged = %+v, want both edits", merged)
+	}
+	if len(merged.History) != 2 || merged.History[0].Field != "Archived" {
+		t.Errorf("History = %+v, want both changes oldest first", merged.History)
+	}
+	if merged.UpdatedBy != "harper@cli" {
+		t.Errorf("UpdatedBy = %q, want the later update", merged.UpdatedBy)
+	}
+}
+
+func TestMergeMessage(t *testing.T) {
+	base := NewMessage(uuid.New(), "hello", "harper@cli")
+
+	local := *base
+	local.Content = "hello world"
+	remote := *base
+	remote.Fields = map[string]string{"status": "done"}
+
+	merged, err := MergeMessage(base, &local, &remote)
+	if err != nil {
+		t.Fatalf("MergeMessage: %v", err)
+	}
+	if merged.Content != "hello world" || merged.Fields["status"] != "done" {
+		t.Errorf("merged = %+v, want both edits", merged)
+	}
+
+	remote.Content = "hi"
+	if _, err := MergeMessage(base, &local, &remote); !errors.Is(err, ErrMergeConflict) {
+		t.Errorf("editing the content on both sides should conflict, got %v", err)
+	}
+}
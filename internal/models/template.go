@@ -0,0 +1,303 @@
+// ABOUTME: Message templates with typed fields
+// ABOUTME: Validates field values and renders them into a message body
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Template field types.
+const (
+	FieldString = "string" // single line of text
+	FieldText   = "text"   // multi-line Markdown
+	FieldNumber = "number"
+	FieldBool   = "bool"
+	FieldDate   = "date" // 2006-01-02
+	FieldEnum   = "enum" // one of Options
+)
+
+// ErrInvalidTemplate is returned for malformed template definitions.
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// ErrInvalidFields is returned when values do not satisfy a template's fields.
+var ErrInvalidFields = errors.New("invalid template fields")
+
+// TemplateField is one typed input of a template.
+type TemplateField struct {
+	Name        string   `yaml:"name"`
+	Type        string   `yaml:"type"`
+	Description string   `yaml:"description,omitempty"`
+	Required    bool     `yaml:"required,omitempty"`
+	Options     []string `yaml:"options,omitempty"`
+	Default     string   `yaml:"default,omitempty"`
+}
+
+// Template is a named, reusable message shape. A nil TopicID makes it
+// available in every topic; otherwise it only applies to that topic.
+// Body may reference fields as {{name}}; an empty body lists every field.
+type Template struct {
+	ID          uuid.UUID
+	Name        string
+	TopicID     *uuid.UUID
+	Description string
+	Fields      []TemplateField
+	Body        string
+	CreatedAt   time.Time
+	CreatedBy   string
+	UpdatedAt   *time.Time
+}
+
+var (
+	templateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	placeholderPattern  = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_-]+)\s*\}\}`)
+)
+
+// NewTemplate creates a template with generated UUID and timestamp.
+func NewTemplate(name, description string, fields []TemplateField, body, createdBy string) *Template {
+	return &Template{
+		ID:          uuid.New(),
+		Name:        strings.ToLower(strings.TrimSpace(name)),
+		Description: description,
+		Fields:      fields,
+		Body:        body,
+		CreatedAt:   time.Now(),
+		CreatedBy:   createdBy,
+	}
+}
+
+// Field returns the named field, or nil.
+func (t *Template) Field(name string) *TemplateField {
+	for i := range t.Fields {
+		if t.Fields[i].Name == name {
+			return &t.Fields[i]
+		}
+	}
+	return nil
+}
+
+// Check reports problems with the template definition itself.
+func (t *Template) Check() error {
+	if !templateNamePattern.MatchString(t.Name) {
+		return fmt.Errorf("%w: name %q must be lowercase letters, digits, - or _", ErrInvalidTemplate, t.Name)
+	}
+	if len(t.Fields) == 0 {
+		return fmt.Errorf("%w: %s has no fields", ErrInvalidTemplate, t.Name)
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range t.Fields {
+		if !templateNamePattern.MatchString(f.Name) {
+			return fmt.Errorf("%w: field name %q must be lowercase letters, digits, - or _", ErrInvalidTemplate, f.Name)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("%w: duplicate field %q", ErrInvalidTemplate, f.Name)
+		}
+		seen[f.Name] = true
+
+		switch f.Type {
+		case FieldString, FieldText, FieldNumber, FieldBool, FieldDate:
+		case FieldEnum:
+			if len(f.Options) == 0 {
+				return fmt.Errorf("%w: enum field %q needs options", ErrInvalidTemplate, f.Name)
+			}
+		default:
+			return fmt.Errorf("%w: field %q has unknown type %q", ErrInvalidTemplate, f.Name, f.Type)
+		}
+
+		if f.Default != "" {
+			if _, err := f.normalize(f.Default); err != nil {
+				return fmt.Errorf("%w: default for %q: %v", ErrInvalidTemplate, f.Name, err)
+			}
+		}
+	}
+
+	for _, m := range placeholderPattern.FindAllStringSubmatch(t.Body, -1) {
+		if !seen[m[1]] {
+			return fmt.Errorf("%w: body references unknown field %q", ErrInvalidTemplate, m[1])
+		}
+	}
+	return nil
+}
+
+// Validate checks values against the template's fields, applies defaults and
+// returns the normalized values. All problems are reported together.
+func (t *Template) Validate(values map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(t.Fields))
+	var problems []string
+
+	for name := range values {
+		if t.Field(name) == nil {
+			problems = append(problems, fmt.Sprintf("unknown field %q", name))
+		}
+	}
+
+	for _, f := range t.Fields {
+		v, ok := values[f.Name]
+		if !ok || strings.TrimSpace(v) == "" {
+			v = f.Default
+		}
+		if v == "" {
+			if f.Required {
+				problems = append(problems, fmt.Sprintf("%s is required", f.Name))
+			}
+			continue
+		}
+
+		norm, err := f.normalize(v)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", f.Name, err))
+			continue
+		}
+		out[f.Name] = norm
+	}
+
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return nil, fmt.Errorf("%w for %s: %s", ErrInvalidFields, t.Name, strings.Join(problems, "; "))
+	}
+	return out, nil
+}
+
+// normalize checks one value against the field type and returns its canonical form.
+func (f *TemplateField) normalize(v string) (string, error) {
+	switch f.Type {
+	case FieldString:
+		v = strings.TrimSpace(v)
+		if strings.Contains(v, "\n") {
+			return "", errors.New("must be a single line")
+		}
+		return v, nil
+	case FieldText:
+		return strings.TrimSpace(v), nil
+	case FieldNumber:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a number", v)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case FieldBool:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes", "y", "1":
+			return "true", nil
+		case "false", "no", "n", "0":
+			return "false", nil
+		}
+		return "", fmt.Errorf("%q is not true or false", v)
+	case FieldDate:
+		d, err := time.Parse("2006-01-02", strings.TrimSpace(v))
+		if err != nil {
+			return "", fmt.Errorf("%q is not a date (YYYY-MM-DD)", v)
+		}
+		return d.Format("2006-01-02"), nil
+	case FieldEnum:
+		for _, opt := range f.Options {
+			if strings.EqualFold(opt, strings.TrimSpace(v)) {
+				return opt, nil
+			}
+		}
+		return "", fmt.Errorf("%q is not one of %s", v, strings.Join(f.Options, ", "))
+	}
+	return "", fmt.Errorf("unknown type %q", f.Type)
+}
+
+// Render builds the message body from validated values.
+func (t *Template) Render(values map[string]string) string {
+	if strings.TrimSpace(t.Body) != "" {
+		return strings.TrimSpace(placeholderPattern.ReplaceAllStringFunc(t.Body, func(m string) string {
+			name := placeholderPattern.FindStringSubmatch(m)[1]
+			return values[name]
+		}))
+	}
+
+	var sb strings.Builder
+	for _, f := range t.Fields {
+		v, ok := values[f.Name]
+		if !ok {
+			continue
+		}
+		label := fieldLabel(f.Name)
+		if f.Type == FieldText {
+			sb.WriteString(fmt.Sprintf("**%s**\n\n%s\n\n", label, v))
+		} else {
+			sb.WriteString(fmt.Sprintf("**%s:** %s\n\n", label, v))
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// NewMessage validates values and builds a message rendered from the
+// template, keeping the template name and normalized fields as metadata.
+func (t *Template) NewMessage(threadID uuid.UUID, values map[string]string, createdBy string) (*Message, error) {
+	fields, err := t.Validate(values)
+	if err != nil {
+		return nil, err
+	}
+	msg := NewMessage(threadID, t.Render(fields), createdBy)
+	msg.Template = t.Name
+	msg.Fields = fields
+	return msg, nil
+}
+
+// fieldLabel turns "next_steps" into "Next steps".
+func fieldLabel(name string) string {
+	label := strings.NewReplacer("_", " ", "-", " ").Replace(name)
+	if label == "" {
+		return label
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// ParseFieldSpec parses a compact field definition:
+//
+//	name[:type][!]          e.g. "summary:text!", "eta:date"
+//	name:enum=a|b|c[!]      e.g. "status:enum=green|yellow|red!"
+//
+// A trailing ! marks the field required. The type defaults to string.
+func ParseFieldSpec(spec string) (TemplateField, error) {
+	var f TemplateField
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutSuffix(spec, "!"); ok {
+		f.Required = true
+		spec = rest
+	}
+
+	name, typ, _ := strings.Cut(spec, ":")
+	f.Name = strings.ToLower(strings.TrimSpace(name))
+	f.Type = FieldString
+	if typ != "" {
+		typ, opts, hasOpts := strings.Cut(typ, "=")
+		f.Type = strings.ToLower(strings.TrimSpace(typ))
+		if hasOpts {
+			for _, o := range strings.Split(opts, "|") {
+				if o = strings.TrimSpace(o); o != "" {
+					f.Options = append(f.Options, o)
+				}
+			}
+		}
+	}
+
+	if f.Name == "" {
+		return f, fmt.Errorf("%w: empty field name in %q", ErrInvalidTemplate, spec)
+	}
+	return f, nil
+}
+
+// MatchesFields reports whether a message posted from a template carries
+// every given field value (compared case-insensitively).
+func (m *Message) MatchesFields(want map[string]string) bool {
+	for k, v := range want {
+		if !strings.EqualFold(m.Fields[k], v) {
+			return false
+		}
+	}
+	return true
+}
@@ -4,6 +4,9 @@
 package tui
 
 import (
+	"fmt"
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/harper/bbs/internal/charm"
@@ -30,24 +33,112 @@ type Model struct {
 	messages    MessagesModel
 	composing   bool
 	composeText string
+	unread      int
+	sync        string
+	syncRefresh time.Duration
 	err         error
 }
 
+// Option configures the TUI.
+type Option func(*Model)
+
+// WithSyncRefresh sets how often the status bar rereads sync health.
+func WithSyncRefresh(d time.Duration) Option {
+	return func(m *Model) {
+		if d > 0 {
+			m.syncRefresh = d
+		}
+	}
+}
+
 // NewModel creates a new TUI model
-func NewModel(client *charm.Client, identity string) Model {
-	return Model{
-		client:     client,
-		identity:   identity,
-		activePane: TopicsPane,
-		topics:     NewTopicsModel(client),
-		threads:    NewThreadsModel(client),
-		messages:   NewMessagesModel(client),
+func NewModel(client *charm.Client, identity string, opts ...Option) Model {
+	m := Model{
+		client:      client,
+		identity:    identity,
+		activePane:  TopicsPane,
+		topics:      NewTopicsModel(client),
+		threads:     NewThreadsModel(client),
+		messages:    NewMessagesModel(client),
+		syncRefresh: defaultSyncRefresh,
+	}
+	for _, opt := range opts {
+		opt(&m)
 	}
+	return m
+}
+
+// MentionsCountMsg carries the number of unread mentions for the badge
+type MentionsCountMsg struct {
+	Count int
 }
 
+// SyncHealthMsg carries a one-line sync summary for the status bar
+type SyncHealthMsg struct {
+	Summary string
+}
+
+// defaultSyncRefresh is how often the status bar rereads sync health.
+const defaultSyncRefresh = 15 * time.Second
+
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	return m.topics.LoadTopics()
+	return tea.Batch(m.topics.LoadTopics(), m.loadMentionCount(), m.loadSyncHealth())
+}
+
+func (m Model) loadSyncHealth() tea.Cmd {
+	return func() tea.Msg {
+		// Health is best-effort: a missing or unreadable file leaves the bar empty
+		st, _ := m.client.SyncState()
+		h, _ := m.client.DaemonHealth()
+		return SyncHealthMsg{Summary: syncSummary(h, st, time.Now())}
+	}
+}
+
+// syncSummary describes sync health from the daemon when one is running,
+// otherwise from this device's outbox.
+func syncSummary(h *charm.DaemonHealth, st *charm.SyncState, now time.Time) string {
+	if h.Running(now) {
+		if h.LastError != "" {
+			return "sync ✗ retrying"
+		}
+		return "sync ✓ " + ago(h.Lag(now))
+	}
+	if st == nil {
+		return ""
+	}
+	switch {
+	case len(st.Pending) > 0 && st.LastError != "":
+		return fmt.Sprintf("sync ✗ %d queued", len(st.Pending))
+	case len(st.Pending) > 0:
+		return fmt.Sprintf("sync ⟳ %d queued", len(st.Pending))
+	case !st.LastSuccess.IsZero():
+		return "sync ✓ " + ago(now.Sub(st.LastSuccess))
+	}
+	return ""
+}
+
+// ago formats a duration coarsely for the status bar.
+func ago(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+func (m Model) loadMentionCount() tea.Cmd {
+	return func() tea.Msg {
+		count, err := m.client.CountUnreadMentions(m.identity)
+		if err != nil {
+			return err
+		}
+		return MentionsCountMsg{Count: count}
+	}
 }
 
 // Update handles messages
@@ -73,9 +164,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case MessagesLoadedMsg:
-		m.messages.SetMessages(msg.Messages)
+		m.messages.SetMessages(msg.Messages, msg.Reactions)
+		return m, nil
+
+	case MentionsCountMsg:
+		m.unread = msg.Count
 		return m, nil
 
+	case SyncHealthMsg:
+		m.sync = msg.Summary
+		return m, tea.Tick(m.syncRefresh, func(time.Time) tea.Msg {
+			return m.loadSyncHealth()()
+		})
+
 	case error:
 		m.err = msg
 		return m, nil
@@ -140,7 +241,7 @@ func (m Model) updateNavigation(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "r":
-		return m, m.topics.LoadTopics()
+		return m, tea.Batch(m.topics.LoadTopics(), m.loadMentionCount())
 	}
 
 	return m, nil
@@ -213,6 +314,20 @@ func (m Model) View() string {
 		Foreground(lipgloss.Color("241")).
 		Render("[tab] switch pane  [j/k] navigate  [enter] select  [n] new  [r] refresh  [q] quit")
 
+	if m.unread > 0 {
+		badge := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("0")).
+			Background(lipgloss.Color("214")).
+			Render(fmt.Sprintf(" @%d ", m.unread))
+		status = badge + " " + status
+	}
+
+	if m.sync != "" {
+		status += lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241")).
+			Render("  " + m.sync)
+	}
+
 	if m.composing {
 		status = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("86")).
@@ -223,8 +338,8 @@ func (m Model) View() string {
 }
 
 // Run starts the TUI
-func Run(client *charm.Client, identity string) error {
-	p := tea.NewProgram(NewModel(client, identity), tea.WithAltScreen())
+func Run(client *charm.Client, identity string, opts ...Option) error {
+	p := tea.NewProgram(NewModel(client, identity, opts...), tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
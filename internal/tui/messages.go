@@ -15,15 +15,17 @@ import (
 )
 
 type MessagesLoadedMsg struct {
-	Messages []*models.Message
+	Messages  []*models.Message
+	Reactions map[uuid.UUID][]models.ReactionCount
 }
 
 type MessagesModel struct {
-	client   *charm.Client
-	messages []*models.Message
-	cursor   int
-	scroll   int
-	threadID uuid.UUID
+	client    *charm.Client
+	messages  []*models.Message
+	reactions map[uuid.UUID][]models.ReactionCount
+	cursor    int
+	scroll    int
+	threadID  uuid.UUID
 }
 
 func NewMessagesModel(client *charm.Client) MessagesModel {
@@ -37,12 +39,27 @@ func (m *MessagesModel) LoadMessages(threadID uuid.UUID) tea.Cmd {
 		if err != nil {
 			return err
 		}
-		return MessagesLoadedMsg{Messages: messages}
+
+		ids := make([]uuid.UUID, 0, len(messages))
+		for _, msg := range messages {
+			ids = append(ids, msg.ID)
+		}
+		byMessage, err := m.client.ListReactionsForMessages(ids)
+		if err != nil {
+			return err
+		}
+		reactions := make(map[uuid.UUID][]models.ReactionCount, len(byMessage))
+		for id, rs := range byMessage {
+			reactions[id] = models.SummarizeReactions(rs)
+		}
+
+		return MessagesLoadedMsg{Messages: messages, Reactions: reactions}
 	}
 }
 
-func (m *MessagesModel) SetMessages(messages []*models.Message) {
+func (m *MessagesModel) SetMessages(messages []*models.Message, reactions map[uuid.UUID][]models.ReactionCount) {
 	m.messages = messages
+	m.reactions = reactions
 	m.cursor = 0
 	m.scroll = 0
 }
@@ -100,6 +117,15 @@ func (m MessagesModel) View() string {
 		for _, line := range lines {
 			s += line + "\n"
 		}
+
+		// Reaction counts
+		if counts := m.reactions[msg.ID]; len(counts) > 0 {
+			parts := make([]string, 0, len(counts))
+			for _, rc := range counts {
+				parts = append(parts, fmt.Sprintf("%s %d", rc.Reaction, rc.Count))
+			}
+			s += faintStyle.Render(strings.Join(parts, "  ")) + "\n"
+		}
 		s += "\n"
 	}
 
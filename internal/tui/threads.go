@@ -84,10 +84,29 @@ func (m ThreadsModel) View() string {
 		if thread.Sticky {
 			prefix = "📌 "
 		}
+		if thread.Locked {
+			prefix += "🔒 "
+		}
 
 		s += fmt.Sprintf("%s%s%s\n", cursor, prefix, style.Render(thread.Subject))
-		s += lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf("   %s\n", thread.CreatedBy))
+		s += lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf("   %s\n", threadSummary(thread)))
 	}
 
 	return s
 }
+
+// threadSummary describes how busy a thread is, e.g. "3 posts · 2 people · last alice@cli Jan 02 15:04".
+func threadSummary(t *models.Thread) string {
+	if t.MessageCount == 0 {
+		return fmt.Sprintf("%s · no posts", t.CreatedBy)
+	}
+	posts, people := "posts", "people"
+	if t.MessageCount == 1 {
+		posts = "post"
+	}
+	if len(t.Participants) == 1 {
+		people = "person"
+	}
+	return fmt.Sprintf("%d %s · %d %s · last %s %s",
+		t.MessageCount, posts, len(t.Participants), people, t.LastPoster, t.LastActive().Format("Jan 02 15:04"))
+}
@@ -88,6 +88,9 @@ func (m TopicsModel) View() string {
 			archived = " (archived)"
 			style = style.Faint(true)
 		}
+		if topic.ReadOnly {
+			archived += " 🔒"
+		}
 
 		s += fmt.Sprintf("%s%s%s\n", cursor, style.Render(topic.Name), archived)
 	}
@@ -4,7 +4,14 @@
 package tui
 
 import (
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/harper/bbs/internal/charm"
+	"github.com/harper/bbs/internal/models"
 )
 
 func TestNewModel(t *testing.T) {
@@ -25,3 +32,56 @@ func TestModelInit(t *testing.T) {
 	// Skip this test as it requires Charm connectivity
 	t.Skip("Requires Charm client connectivity")
 }
+
+func TestMentionBadge(t *testing.T) {
+	model := NewModel(nil, "test@tui")
+	model.width = 120
+	model.height = 30
+
+	if strings.Contains(model.View(), "@3") {
+		t.Error("Expected no badge without unread mentions")
+	}
+
+	updated, _ := model.Update(MentionsCountMsg{Count: 3})
+	if !strings.Contains(updated.View(), "@3") {
+		t.Error("Expected badge with unread mention count")
+	}
+}
+
+func TestThreadSummary(t *testing.T) {
+	thread := models.NewThread(uuid.New(), "Plans", "harper@tui")
+	if got := threadSummary(thread); got != "harper@tui · no posts" {
+		t.Errorf("empty thread summary = %q", got)
+	}
+
+	thread.RecordPost(models.NewMessage(thread.ID, "hi", "claude@mcp"))
+	if got := threadSummary(thread); !strings.HasPrefix(got, "1 post · 1 person · last claude@mcp") {
+		t.Errorf("summary = %q", got)
+	}
+}
+
+func TestSyncSummary(t *testing.T) {
+	now := time.Now()
+	if got := syncSummary(nil, nil, now); got != "" {
+		t.Errorf("no health = %q, want empty", got)
+	}
+
+	st := &charm.SyncState{LastSuccess: now.Add(-5 * time.Minute)}
+	if got := syncSummary(nil, st, now); got != "sync ✓ 5m ago" {
+		t.Errorf("synced = %q", got)
+	}
+	st.Pending = []charm.OutboxEntry{{Key: "topic:a"}}
+	st.LastError = "sync failed: connection refused"
+	if got := syncSummary(nil, st, now); got != "sync ✗ 1 queued" {
+		t.Errorf("failing = %q", got)
+	}
+
+	daemon := &charm.DaemonHealth{LastSuccess: now.Add(-10 * time.Second), UpdatedAt: now}
+	if got := syncSummary(daemon, st, now); got != "sync ✓ just now" {
+		t.Errorf("a running daemon should win over the outbox, got %q", got)
+	}
+	daemon.UpdatedAt = now.Add(-time.Hour)
+	if got := syncSummary(daemon, st, now); got != "sync ✗ 1 queued" {
+		t.Errorf("a stopped daemon should be ignored, got %q", got)
+	}
+}